@@ -3,6 +3,8 @@ package ansi
 import (
 	"fmt"
 	"image/color"
+	"strconv"
+	"strings"
 )
 
 // Colorizer is a [color.Color] interface that can be formatted as a string.
@@ -167,3 +169,136 @@ const RequestCursorColor = "\x1b]12;?\x07"
 //
 // See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Operating-System-Commands
 const ResetCursorColor = "\x1b]112\x07"
+
+// SetPaletteColor returns a sequence that sets the terminal palette color at
+// the given index.
+//
+//	OSC 4 ; index ; color ST
+//	OSC 4 ; index ; color BEL
+//
+// Where color is the encoded color number.
+//
+// See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Operating-System-Commands
+func SetPaletteColor(index int, c color.Color) string {
+	var s string
+	switch c := c.(type) {
+	case Colorizer:
+		s = c.String()
+	case fmt.Stringer:
+		s = c.String()
+	default:
+		s = HexColorizer{c}.String()
+	}
+	return "\x1b]4;" + strconv.Itoa(index) + ";" + s + "\x07"
+}
+
+// RequestPaletteColor returns a sequence that requests the terminal palette
+// color at the given index.
+//
+//	OSC 4 ; index ; ? ST
+//	OSC 4 ; index ; ? BEL
+//
+// See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Operating-System-Commands
+func RequestPaletteColor(index int) string {
+	return "\x1b]4;" + strconv.Itoa(index) + ";?\x07"
+}
+
+// ResetPaletteColor returns a sequence that resets the terminal palette
+// colors at the given indices. It resets the whole palette when no index is
+// given.
+//
+//	OSC 104 ; index ; ... ST
+//	OSC 104 ; index ; ... BEL
+//
+// See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Operating-System-Commands
+func ResetPaletteColor(indices ...int) string {
+	if len(indices) == 0 {
+		return "\x1b]104\x07"
+	}
+	s := make([]string, len(indices))
+	for i, index := range indices {
+		s[i] = strconv.Itoa(index)
+	}
+	return "\x1b]104;" + strings.Join(s, ";") + "\x07"
+}
+
+// DecodePaletteColor decodes an OSC 4 sequence setting or reporting the
+// terminal palette color at an index. Colors are parsed using [XParseColor]
+// and a request ("?") returns a nil color. Only the first index and color
+// pair is decoded when the sequence contains more than one. It reports false
+// if seq isn't a valid OSC 4 sequence.
+//
+//	OSC 4 ; index ; color ST
+//	OSC 4 ; index ; color BEL
+//
+// See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Operating-System-Commands
+func DecodePaletteColor(seq string) (index int, c color.Color, ok bool) {
+	payload, ok := oscPayload(seq, "4")
+	if !ok {
+		return 0, nil, false
+	}
+
+	parts := strings.Split(payload, ";")
+	if len(parts) < 2 {
+		return 0, nil, false
+	}
+
+	index, err := strconv.Atoi(parts[0])
+	if err != nil || index < 0 || index > 255 {
+		return 0, nil, false
+	}
+
+	c, ok = decodeColorSpec(parts[1])
+	if !ok {
+		return 0, nil, false
+	}
+
+	return index, c, true
+}
+
+// DecodeForegroundColor decodes an OSC 10 sequence setting or reporting the
+// default terminal foreground color. Colors are parsed using [XParseColor]
+// and a request ("?") returns a nil color. It reports false if seq isn't a
+// valid OSC 10 sequence.
+//
+//	OSC 10 ; color ST
+//	OSC 10 ; color BEL
+//
+// See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Operating-System-Commands
+func DecodeForegroundColor(seq string) (color.Color, bool) {
+	payload, ok := oscPayload(seq, "10")
+	if !ok {
+		return nil, false
+	}
+	return decodeColorSpec(payload)
+}
+
+// DecodeBackgroundColor decodes an OSC 11 sequence setting or reporting the
+// default terminal background color. Colors are parsed using [XParseColor]
+// and a request ("?") returns a nil color. It reports false if seq isn't a
+// valid OSC 11 sequence.
+//
+//	OSC 11 ; color ST
+//	OSC 11 ; color BEL
+//
+// See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Operating-System-Commands
+func DecodeBackgroundColor(seq string) (color.Color, bool) {
+	payload, ok := oscPayload(seq, "11")
+	if !ok {
+		return nil, false
+	}
+	return decodeColorSpec(payload)
+}
+
+// decodeColorSpec parses a color spec used in OSC color sequences. A request
+// ("?") returns a nil color.
+func decodeColorSpec(spec string) (color.Color, bool) {
+	if spec == "?" {
+		return nil, true
+	}
+	c := XParseColor(spec)
+	if c == nil {
+		return nil, false
+	}
+	return c, true
+}
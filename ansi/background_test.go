@@ -1,6 +1,7 @@
 package ansi_test
 
 import (
+	"image/color"
 	"testing"
 
 	"github.com/charmbracelet/x/ansi"
@@ -47,3 +48,90 @@ func TestColorizer(t *testing.T) {
 		t.Errorf("Unexpected sequence for XRGBAColorizer: got %q", seq)
 	}
 }
+
+func TestPaletteColorRoundTrip(t *testing.T) {
+	cases := []struct {
+		index int
+		color color.Color
+		want  string
+	}{
+		{1, ansi.TrueColor(0xff0000), "\x1b]4;1;#ff0000\x07"},
+		{42, color.RGBA{0x12, 0x34, 0x56, 0xff}, "\x1b]4;42;#123456\x07"},
+		{255, ansi.XRGBColorizer{Color: ansi.TrueColor(0xabcdef)}, "\x1b]4;255;rgb:abab/cdcd/efef\x07"},
+	}
+	for _, tc := range cases {
+		seq := ansi.SetPaletteColor(tc.index, tc.color)
+		if seq != tc.want {
+			t.Errorf("SetPaletteColor(%d, %v) = %q, want %q", tc.index, tc.color, seq, tc.want)
+		}
+
+		index, c, ok := ansi.DecodePaletteColor(seq)
+		if !ok || index != tc.index || !sameRGB(c, tc.color) {
+			t.Errorf("DecodePaletteColor(%q) = %d, %v, %v; want %d, %v", seq, index, c, ok, tc.index, tc.color)
+		}
+	}
+}
+
+func TestDecodePaletteColor(t *testing.T) {
+	index, c, ok := ansi.DecodePaletteColor(ansi.RequestPaletteColor(7))
+	if !ok || index != 7 || c != nil {
+		t.Errorf("unexpected query decoding: %d, %v, %v", index, c, ok)
+	}
+
+	index, c, ok = ansi.DecodePaletteColor("\x1b]4;3;rgb:cdcd/0000/8080\x1b\\")
+	if !ok || index != 3 || !sameRGB(c, color.RGBA{0xcd, 0x00, 0x80, 0xff}) {
+		t.Errorf("unexpected response decoding: %d, %v, %v", index, c, ok)
+	}
+
+	for _, seq := range []string{
+		"\x1b]4;1\x07",
+		"\x1b]4;x;#ff0000\x07",
+		"\x1b]4;256;#ff0000\x07",
+		"\x1b]4;1;notacolor\x07",
+		"\x1b]10;#ff0000\x07",
+		"\x1b]4;1;#ff0000",
+	} {
+		if _, _, ok := ansi.DecodePaletteColor(seq); ok {
+			t.Errorf("expected DecodePaletteColor(%q) to fail", seq)
+		}
+	}
+}
+
+func TestDecodeForegroundBackgroundColor(t *testing.T) {
+	c, ok := ansi.DecodeForegroundColor("\x1b]10;rgb:ffff/8080/0000\x07")
+	if !ok || !sameRGB(c, color.RGBA{0xff, 0x80, 0x00, 0xff}) {
+		t.Errorf("unexpected foreground decoding: %v, %v", c, ok)
+	}
+
+	c, ok = ansi.DecodeBackgroundColor("\x1b]11;#1e1e2e\x1b\\")
+	if !ok || !sameRGB(c, color.RGBA{0x1e, 0x1e, 0x2e, 0xff}) {
+		t.Errorf("unexpected background decoding: %v, %v", c, ok)
+	}
+
+	c, ok = ansi.DecodeBackgroundColor(ansi.RequestBackgroundColor)
+	if !ok || c != nil {
+		t.Errorf("unexpected background query decoding: %v, %v", c, ok)
+	}
+
+	c, ok = ansi.DecodeForegroundColor(ansi.SetForegroundColor(ansi.TrueColor(0x336699)))
+	if !ok || !sameRGB(c, ansi.TrueColor(0x336699)) {
+		t.Errorf("unexpected foreground round trip: %v, %v", c, ok)
+	}
+
+	if _, ok := ansi.DecodeForegroundColor(ansi.RequestBackgroundColor); ok {
+		t.Error("expected background sequence to fail foreground decoding")
+	}
+	if _, ok := ansi.DecodeBackgroundColor("\x1b]11;bogus\x07"); ok {
+		t.Error("expected invalid color to fail decoding")
+	}
+}
+
+// sameRGB reports whether two colors have the same 8-bit RGB components.
+func sameRGB(a, b color.Color) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	return ar>>8 == br>>8 && ag>>8 == bg>>8 && ab>>8 == bb>>8
+}
@@ -1,6 +1,9 @@
 package ansi
 
-import "encoding/base64"
+import (
+	"encoding/base64"
+	"strings"
+)
 
 // Clipboard names.
 const (
@@ -73,3 +76,41 @@ const RequestSystemClipboard = "\x1b]52;c;?\x07"
 //
 // This is equivalent to RequestClipboard(PrimaryClipboard).
 const RequestPrimaryClipboard = "\x1b]52;p;?\x07"
+
+// DecodeClipboard decodes an OSC 52 clipboard sequence terminated by either
+// BEL or ST. It returns the clipboard name and the decoded data. The first
+// clipboard name is returned when more than one is given, and 0 when none is.
+//
+// A clipboard request ("?") returns nil data, while an empty clipboard,
+// used to reset it, returns empty non-nil data. It reports false if seq isn't
+// a valid OSC 52 sequence or if the data isn't valid base64.
+//
+//	OSC 52 ; Pc ; Pd ST
+//	OSC 52 ; Pc ; Pd BEL
+//
+// See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Operating-System-Commands
+func DecodeClipboard(seq string) (selection byte, data []byte, ok bool) {
+	payload, ok := oscPayload(seq, "52")
+	if !ok {
+		return 0, nil, false
+	}
+
+	pc, pd, found := strings.Cut(payload, ";")
+	if !found {
+		return 0, nil, false
+	}
+	if len(pc) > 0 {
+		selection = pc[0]
+	}
+
+	if pd == "?" {
+		return selection, nil, true
+	}
+
+	data, err := base64.StdEncoding.DecodeString(pd)
+	if err != nil {
+		return 0, nil, false
+	}
+
+	return selection, data, true
+}
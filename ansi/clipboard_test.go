@@ -39,3 +39,51 @@ func TestClipboardRequest(t *testing.T) {
 		t.Errorf("Unexpected clipboard request: %q", cb)
 	}
 }
+
+func TestDecodeClipboard(t *testing.T) {
+	cases := []struct {
+		name      string
+		seq       string
+		selection byte
+		data      []byte
+		ok        bool
+	}{
+		{"bel", "\x1b]52;c;SGVsbG8gVGVzdA==\x07", 'c', []byte("Hello Test"), true},
+		{"st", "\x1b]52;p;QW5zaSBUZXN0\x1b\\", 'p', []byte("Ansi Test"), true},
+		{"c1", "\x9d52;c;dGVzdA==\x9c", 'c', []byte("test"), true},
+		{"query", "\x1b]52;c;?\x07", 'c', nil, true},
+		{"reset", "\x1b]52;c;\x07", 'c', []byte{}, true},
+		{"multiple selections", "\x1b]52;pc;dGVzdA==\x07", 'p', []byte("test"), true},
+		{"invalid base64", "\x1b]52;c;!!\x07", 0, nil, false},
+		{"other osc", "\x1b]8;;\x07", 0, nil, false},
+		{"missing data", "\x1b]52;c\x07", 0, nil, false},
+		{"unterminated", "\x1b]52;c;dGVzdA==", 0, nil, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			selection, data, ok := ansi.DecodeClipboard(tc.seq)
+			if ok != tc.ok || selection != tc.selection {
+				t.Fatalf("DecodeClipboard(%q) = %q, %v; want %q, %v", tc.seq, selection, ok, tc.selection, tc.ok)
+			}
+			if (data == nil) != (tc.data == nil) || string(data) != string(tc.data) {
+				t.Errorf("DecodeClipboard(%q) data = %q, want %q", tc.seq, data, tc.data)
+			}
+		})
+	}
+}
+
+func TestClipboardRoundTrip(t *testing.T) {
+	payloads := []string{
+		"Hello, World!",
+		"multi\nline\ttext",
+		"\x00\x01\x02\xff\xfe\x1b\x07",
+		"日本語 🎉",
+	}
+	for _, p := range payloads {
+		seq := ansi.SetClipboard(ansi.SystemClipboard, p)
+		selection, data, ok := ansi.DecodeClipboard(seq)
+		if !ok || selection != ansi.SystemClipboard || string(data) != p {
+			t.Errorf("round trip %q: got %q, %q, %v", p, selection, data, ok)
+		}
+	}
+}
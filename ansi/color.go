@@ -194,3 +194,71 @@ func toRGBA(r, g, b uint32) (uint32, uint32, uint32, uint32) {
 	b |= b << 8
 	return r, g, b, 0xffff
 }
+
+// Quantize returns the index of the xterm 256-color palette color closest to
+// the given 24-bit color. It only considers the 6x6x6 color cube (16-231) and
+// the grayscale ramp (232-255) since the first 16 colors are often customized
+// by terminals.
+//
+//	i := Quantize(255, 128, 0) // 208
+func Quantize(r, g, b uint8) int {
+	// Map each component to the closest of the cube values 0, 95, 135, 175,
+	// 215, and 255.
+	toCube := func(v uint8) int {
+		if v < 48 {
+			return 0
+		}
+		if v < 115 {
+			return 1
+		}
+		return (int(v) - 35) / 40
+	}
+	cubeValue := func(i int) int {
+		if i == 0 {
+			return 0
+		}
+		return i*40 + 55
+	}
+
+	qr, qg, qb := toCube(r), toCube(g), toCube(b)
+	cr, cg, cb := cubeValue(qr), cubeValue(qg), cubeValue(qb)
+	ci := 16 + 36*qr + 6*qg + qb
+	if cr == int(r) && cg == int(g) && cb == int(b) {
+		return ci
+	}
+
+	// Find the closest gray, the ramp goes from 8 to 238 in steps of 10.
+	avg := (int(r) + int(g) + int(b)) / 3
+	gi := 23
+	if avg <= 238 {
+		gi = max(avg-3, 0) / 10
+	}
+	gv := 8 + gi*10
+
+	if distSq(cr, cg, cb, int(r), int(g), int(b)) <= distSq(gv, gv, gv, int(r), int(g), int(b)) {
+		return ci
+	}
+	return 232 + gi
+}
+
+// Quantize16 returns the index of the basic 16 ANSI color closest to the
+// given 24-bit color, using the default palette values of the basic colors.
+//
+//	i := Quantize16(255, 0, 0) // 9, bright red
+func Quantize16(r, g, b uint8) int {
+	best, bestDist := 0, -1
+	for i := uint32(0); i < 16; i++ {
+		hr, hg, hb := hexToRGB(lowANSI[i])
+		d := distSq(int(hr), int(hg), int(hb), int(r), int(g), int(b))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = int(i), d
+		}
+	}
+	return best
+}
+
+// distSq returns the squared euclidean distance between two RGB colors.
+func distSq(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
@@ -82,3 +82,63 @@ func TestHexToRGB(t *testing.T) {
 		}
 	}
 }
+
+func TestQuantize(t *testing.T) {
+	cases := []struct {
+		r, g, b uint8
+		want    int
+	}{
+		{0, 0, 0, 16},
+		{255, 255, 255, 231},
+		{255, 0, 0, 196},
+		{0, 255, 0, 46},
+		{0, 0, 255, 21},
+		{8, 8, 8, 232},
+		{128, 128, 128, 244},
+		{100, 100, 100, 241},
+		{238, 238, 238, 255},
+		{95, 135, 175, 67},
+		{255, 128, 0, 208},
+		{30, 144, 255, 33},
+	}
+
+	for _, c := range cases {
+		if got := Quantize(c.r, c.g, c.b); got != c.want {
+			t.Errorf("Quantize(%d, %d, %d): got %d, want %d", c.r, c.g, c.b, got, c.want)
+		}
+	}
+}
+
+func TestQuantizeCubeRoundTrip(t *testing.T) {
+	for i := uint32(16); i < 256; i++ {
+		r, g, b := ansiToRGB(i)
+		if got := Quantize(uint8(r), uint8(g), uint8(b)); got != int(i) {
+			t.Errorf("Quantize(ansiToRGB(%d)): got %d", i, got)
+		}
+	}
+}
+
+func TestQuantize16(t *testing.T) {
+	cases := []struct {
+		r, g, b uint8
+		want    int
+	}{
+		{0, 0, 0, 0},
+		{255, 0, 0, 9},
+		{0, 255, 0, 10},
+		{0, 0, 255, 12},
+		{255, 255, 255, 15},
+		{128, 0, 0, 1},
+		{192, 192, 192, 7},
+		{128, 128, 128, 8},
+		{40, 40, 40, 0},
+		{250, 240, 20, 11},
+		{0, 120, 130, 6},
+	}
+
+	for _, c := range cases {
+		if got := Quantize16(c.r, c.g, c.b); got != c.want {
+			t.Errorf("Quantize16(%d, %d, %d): got %d, want %d", c.r, c.g, c.b, got, c.want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package ansi
 
-import "fmt"
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
 
 // ITerm2 returns a sequence that uses the iTerm2 proprietary protocol. Use the
 // iterm2 package for a more convenient API.
@@ -16,3 +20,44 @@ import "fmt"
 func ITerm2(data any) string {
 	return "\x1b]1337;" + fmt.Sprint(data) + "\x07"
 }
+
+// DecodeITerm2Image decodes an iTerm2 inline image sequence terminated by
+// either BEL or ST. It returns the arguments of the file, such as name, size,
+// width, height, and inline, keyed by name with their values unchanged, and
+// the decoded file content. It reports false if seq isn't a valid OSC 1337
+// File sequence, if an argument isn't a key=value pair, or if the content
+// isn't valid base64.
+//
+//	OSC 1337 ; File = [arguments] : [base64 content] ST
+//	OSC 1337 ; File = [arguments] : [base64 content] BEL
+//
+// See https://iterm2.com/documentation-images.html
+func DecodeITerm2Image(seq string) (meta map[string]string, data []byte, ok bool) {
+	payload, ok := oscPayload(seq, "1337")
+	if !ok || !strings.HasPrefix(payload, "File=") {
+		return nil, nil, false
+	}
+
+	args, content, found := strings.Cut(payload[len("File="):], ":")
+	if !found {
+		return nil, nil, false
+	}
+
+	meta = make(map[string]string)
+	if args != "" {
+		for _, arg := range strings.Split(args, ";") {
+			k, v, found := strings.Cut(arg, "=")
+			if !found || k == "" {
+				return nil, nil, false
+			}
+			meta[k] = v
+		}
+	}
+
+	data, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	return meta, data, true
+}
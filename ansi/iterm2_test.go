@@ -2,6 +2,7 @@ package ansi
 
 import (
 	"encoding/base64"
+	"reflect"
 	"testing"
 
 	"github.com/charmbracelet/x/ansi/iterm2"
@@ -88,3 +89,68 @@ func TestITerm2(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeITerm2Image(t *testing.T) {
+	// A 1x1 transparent GIF.
+	gif := []byte("GIF89a\x01\x00\x01\x00\x80\x00\x00\x00\x00\x00\xff\xff\xff!\xf9\x04\x01\x00\x00\x00\x00,\x00\x00\x00\x00\x01\x00\x01\x00\x00\x02\x02D\x01\x00;")
+	content := []byte(base64.StdEncoding.EncodeToString(gif))
+
+	tests := []struct {
+		name string
+		seq  string
+		meta map[string]string
+		data []byte
+		ok   bool
+	}{
+		{
+			name: "inline image",
+			seq: ITerm2(iterm2.File{
+				Name:    "pixel.gif",
+				Size:    int64(len(gif)),
+				Width:   iterm2.Cells(1),
+				Height:  iterm2.Auto,
+				Inline:  true,
+				Content: content,
+			}),
+			meta: map[string]string{
+				"name":   "pixel.gif",
+				"size":   "43",
+				"width":  "1",
+				"height": "auto",
+				"inline": "1",
+			},
+			data: gif,
+			ok:   true,
+		},
+		{
+			name: "st terminator",
+			seq:  "\x1b]1337;File=inline=1:" + string(content) + "\x1b\\",
+			meta: map[string]string{"inline": "1"},
+			data: gif,
+			ok:   true,
+		},
+		{
+			name: "no arguments",
+			seq:  "\x1b]1337;File=:dGVzdA==\x07",
+			meta: map[string]string{},
+			data: []byte("test"),
+			ok:   true,
+		},
+		{name: "no content", seq: "\x1b]1337;File=name=a.png\x07"},
+		{name: "invalid base64", seq: "\x1b]1337;File=inline=1:not base64\x07"},
+		{name: "invalid argument", seq: "\x1b]1337;File=inline:dGVzdA==\x07"},
+		{name: "multipart", seq: "\x1b]1337;MultipartFile=name=a.png\x07"},
+		{name: "other command", seq: "\x1b]52;c;dGVzdA==\x07"},
+		{name: "unterminated", seq: "\x1b]1337;File=inline=1:dGVzdA=="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, data, ok := DecodeITerm2Image(tt.seq)
+			if ok != tt.ok || !reflect.DeepEqual(meta, tt.meta) || string(data) != string(tt.data) {
+				t.Errorf("DecodeITerm2Image(%q) = %v, %q, %v; want %v, %q, %v",
+					tt.seq, meta, data, ok, tt.meta, tt.data, tt.ok)
+			}
+		})
+	}
+}
@@ -88,3 +88,218 @@ func PopKittyKeyboard(n int) string {
 
 	return "\x1b[<" + num + "u"
 }
+
+// Kitty keyboard protocol modifier bits. Note that the protocol encodes the
+// modifiers as 1 + the bitmask, [DecodeKittyKey] reports the bitmask itself.
+// See: https://sw.kovidgoyal.net/kitty/keyboard-protocol/#modifiers
+const (
+	KittyModShift = 1 << iota
+	KittyModAlt
+	KittyModCtrl
+	KittyModSuper
+	KittyModHyper
+	KittyModMeta
+	KittyModCapsLock
+	KittyModNumLock
+)
+
+// Kitty keyboard protocol event types.
+// See: https://sw.kovidgoyal.net/kitty/keyboard-protocol/#event-types
+const (
+	KittyKeyPress = iota + 1
+	KittyKeyRepeat
+	KittyKeyRelease
+)
+
+// Kitty keyboard protocol functional key codes that have a legacy CSI
+// encoding. Legacy sequences decoded by [DecodeKittyKey] are reported using
+// these codes.
+// See: https://sw.kovidgoyal.net/kitty/keyboard-protocol/#functional-key-definitions
+const (
+	KittyKeyInsert   rune = 57348
+	KittyKeyDelete   rune = 57349
+	KittyKeyLeft     rune = 57350
+	KittyKeyRight    rune = 57351
+	KittyKeyUp       rune = 57352
+	KittyKeyDown     rune = 57353
+	KittyKeyPageUp   rune = 57354
+	KittyKeyPageDown rune = 57355
+	KittyKeyHome     rune = 57356
+	KittyKeyEnd      rune = 57357
+	KittyKeyF1       rune = 57364
+	KittyKeyF2       rune = 57365
+	KittyKeyF3       rune = 57366
+	KittyKeyF4       rune = 57367
+	KittyKeyF5       rune = 57368
+	KittyKeyF6       rune = 57369
+	KittyKeyF7       rune = 57370
+	KittyKeyF8       rune = 57371
+	KittyKeyF9       rune = 57372
+	KittyKeyF10      rune = 57373
+	KittyKeyF11      rune = 57374
+	KittyKeyF12      rune = 57375
+	KittyKeyKpBegin  rune = 57427
+)
+
+// kittyLegacyTildeKeys maps legacy CSI number ~ sequences to their Kitty key
+// codes.
+var kittyLegacyTildeKeys = map[int]rune{
+	2:  KittyKeyInsert,
+	3:  KittyKeyDelete,
+	5:  KittyKeyPageUp,
+	6:  KittyKeyPageDown,
+	7:  KittyKeyHome,
+	8:  KittyKeyEnd,
+	11: KittyKeyF1,
+	12: KittyKeyF2,
+	13: KittyKeyF3,
+	14: KittyKeyF4,
+	15: KittyKeyF5,
+	17: KittyKeyF6,
+	18: KittyKeyF7,
+	19: KittyKeyF8,
+	20: KittyKeyF9,
+	21: KittyKeyF10,
+	23: KittyKeyF11,
+	24: KittyKeyF12,
+}
+
+// kittyLegacyLetterKeys maps legacy CSI 1 ; modifiers letter sequences to
+// their Kitty key codes.
+var kittyLegacyLetterKeys = map[byte]rune{
+	'A': KittyKeyUp,
+	'B': KittyKeyDown,
+	'C': KittyKeyRight,
+	'D': KittyKeyLeft,
+	'E': KittyKeyKpBegin,
+	'F': KittyKeyEnd,
+	'H': KittyKeyHome,
+	'P': KittyKeyF1,
+	'Q': KittyKeyF2,
+	'S': KittyKeyF4,
+}
+
+// KittyKey represents a key event reported using the Kitty keyboard protocol.
+type KittyKey struct {
+	// Code is the Unicode codepoint of the key, or a functional key code
+	// such as [KittyKeyF1].
+	Code rune
+
+	// ShiftedCode is the shifted key codepoint, if reported. This requires
+	// the [KittyReportAlternateKeys] flag.
+	ShiftedCode rune
+
+	// BaseCode is the key codepoint in the standard PC-101 layout, if
+	// reported. This requires the [KittyReportAlternateKeys] flag.
+	BaseCode rune
+
+	// Mod is the bitmask of the active modifiers such as [KittyModShift].
+	Mod int
+
+	// Event is the event type, one of [KittyKeyPress], [KittyKeyRepeat], or
+	// [KittyKeyRelease].
+	Event int
+
+	// Text is the text associated with the key event, if reported. This
+	// requires the [KittyReportAssociatedKeys] flag.
+	Text string
+}
+
+// DecodeKittyKey decodes a key event sequence reported using the Kitty
+// keyboard protocol. It also recognizes the legacy functional key sequences
+// the protocol keeps using, such as the arrow and function keys, reporting
+// them with their Kitty key codes. It reports false if the sequence isn't a
+// valid key event.
+//
+//	CSI unicode-key-code:alternate-key-codes ; modifiers:event-type ; text-as-codepoints u
+//	CSI number ; modifiers:event-type ~
+//	CSI 1 ; modifiers:event-type {ABCDEFHPQS}
+//
+// See: https://sw.kovidgoyal.net/kitty/keyboard-protocol/
+func DecodeKittyKey(seq string) (k KittyKey, ok bool) {
+	if !HasCsiPrefix(seq) {
+		return k, false
+	}
+
+	p := GetParser()
+	defer PutParser(p)
+
+	s, _, n, _ := DecodeSequence(seq, NormalState, p)
+	if n != len(seq) || len(s) == 0 {
+		return k, false
+	}
+
+	cmd := Cmd(p.Command())
+	if cmd.Prefix() != 0 || cmd.Intermediate() != 0 {
+		return k, false
+	}
+
+	// Split the parameters into groups of sub-parameters.
+	var groups [][]int
+	var group []int
+	params := p.Params()
+	for i := range params {
+		group = append(group, params[i].Param(0))
+		if !params[i].HasMore() {
+			groups = append(groups, group)
+			group = nil
+		}
+	}
+	if group != nil {
+		groups = append(groups, group)
+	}
+
+	// The first parameter is the key code, the second contains the modifiers
+	// and event type.
+	var code int
+	if len(groups) > 0 && len(groups[0]) > 0 {
+		code = groups[0][0]
+	}
+
+	switch final := cmd.Final(); final {
+	case 'u':
+		if code == 0 {
+			return k, false
+		}
+		k.Code = rune(code)
+		if len(groups[0]) > 1 {
+			k.ShiftedCode = rune(groups[0][1])
+		}
+		if len(groups[0]) > 2 {
+			k.BaseCode = rune(groups[0][2])
+		}
+		if len(groups) > 2 {
+			var text []rune
+			for _, r := range groups[2] {
+				if r > 0 {
+					text = append(text, rune(r))
+				}
+			}
+			k.Text = string(text)
+		}
+	case '~':
+		r, ok := kittyLegacyTildeKeys[code]
+		if !ok {
+			return k, false
+		}
+		k.Code = r
+	default:
+		r, ok := kittyLegacyLetterKeys[final]
+		if !ok || code > 1 {
+			return k, false
+		}
+		k.Code = r
+	}
+
+	k.Event = KittyKeyPress
+	if len(groups) > 1 {
+		if mod := groups[1][0]; mod > 1 {
+			k.Mod = mod - 1
+		}
+		if len(groups[1]) > 1 && groups[1][1] > 0 {
+			k.Event = groups[1][1]
+		}
+	}
+
+	return k, true
+}
@@ -0,0 +1,110 @@
+package ansi
+
+import "testing"
+
+func TestDecodeKittyKey(t *testing.T) {
+	cases := []struct {
+		name string
+		seq  string
+		want KittyKey
+		ok   bool
+	}{
+		{
+			name: "plain letter",
+			seq:  "\x1b[97u",
+			want: KittyKey{Code: 'a', Event: KittyKeyPress},
+			ok:   true,
+		},
+		{
+			// Modifiers are encoded as 1 + bitmask, shift is 1.
+			name: "shifted letter",
+			seq:  "\x1b[97;2u",
+			want: KittyKey{Code: 'a', Mod: KittyModShift, Event: KittyKeyPress},
+			ok:   true,
+		},
+		{
+			name: "shifted letter with alternate keys and text",
+			seq:  "\x1b[97:65;2;65u",
+			want: KittyKey{Code: 'a', ShiftedCode: 'A', Mod: KittyModShift, Event: KittyKeyPress, Text: "A"},
+			ok:   true,
+		},
+		{
+			name: "base layout key without shifted key",
+			seq:  "\x1b[1089::99;5u",
+			want: KittyKey{Code: 'с', BaseCode: 'c', Mod: KittyModCtrl, Event: KittyKeyPress},
+			ok:   true,
+		},
+		{
+			name: "ctrl+alt letter release",
+			seq:  "\x1b[120;7:3u",
+			want: KittyKey{Code: 'x', Mod: KittyModCtrl | KittyModAlt, Event: KittyKeyRelease},
+			ok:   true,
+		},
+		{
+			name: "repeat without modifiers",
+			seq:  "\x1b[106;1:2u",
+			want: KittyKey{Code: 'j', Event: KittyKeyRepeat},
+			ok:   true,
+		},
+		{
+			name: "multiple text codepoints",
+			seq:  "\x1b[97;1;104:105u",
+			want: KittyKey{Code: 'a', Event: KittyKeyPress, Text: "hi"},
+			ok:   true,
+		},
+		{
+			name: "escape key",
+			seq:  "\x1b[27u",
+			want: KittyKey{Code: 27, Event: KittyKeyPress},
+			ok:   true,
+		},
+		{
+			name: "functional key code",
+			seq:  "\x1b[57376;9u",
+			want: KittyKey{Code: 57376, Mod: KittyModSuper, Event: KittyKeyPress},
+			ok:   true,
+		},
+		{
+			name: "legacy f5 with ctrl+shift",
+			seq:  "\x1b[15;6~",
+			want: KittyKey{Code: KittyKeyF5, Mod: KittyModCtrl | KittyModShift, Event: KittyKeyPress},
+			ok:   true,
+		},
+		{
+			name: "legacy delete release",
+			seq:  "\x1b[3;1:3~",
+			want: KittyKey{Code: KittyKeyDelete, Event: KittyKeyRelease},
+			ok:   true,
+		},
+		{
+			name: "legacy up arrow",
+			seq:  "\x1b[A",
+			want: KittyKey{Code: KittyKeyUp, Event: KittyKeyPress},
+			ok:   true,
+		},
+		{
+			name: "legacy f1 with alt",
+			seq:  "\x1b[1;3P",
+			want: KittyKey{Code: KittyKeyF1, Mod: KittyModAlt, Event: KittyKeyPress},
+			ok:   true,
+		},
+		{name: "flags report", seq: "\x1b[?1u"},
+		{name: "missing key code", seq: "\x1b[;2u"},
+		{name: "unknown legacy key", seq: "\x1b[99~"},
+		{name: "unknown final", seq: "\x1b[1;2Z"},
+		{name: "truncated", seq: "\x1b[97;2"},
+		{name: "not a csi sequence", seq: "a"},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := DecodeKittyKey(tc.seq)
+			if ok != tc.ok {
+				t.Fatalf("test %d: got ok %v; want %v", i+1, ok, tc.ok)
+			}
+			if got != tc.want {
+				t.Errorf("test %d: got %+v; want %+v", i+1, got, tc.want)
+			}
+		})
+	}
+}
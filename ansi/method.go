@@ -15,7 +15,7 @@ const (
 // codes are ignored and wide characters (such as East Asians and emojis) are
 // accounted for.
 func (m Method) StringWidth(s string) int {
-	return stringWidth(m, s)
+	return stringWidth(m, s, false)
 }
 
 // Truncate truncates a string to a given length, adding a tail to the end if
@@ -34,6 +34,15 @@ func (m Method) TruncateLeft(s string, length int, prefix string) string {
 	return truncateLeft(m, s, length, prefix)
 }
 
+// TruncateMiddle truncates a string to a given length by removing characters
+// from the middle of the string and joining the remaining left and right
+// parts with sep. This function is aware of ANSI escape codes and will not
+// break them, and accounts for wide-characters (such as East-Asian characters
+// and emojis).
+func (m Method) TruncateMiddle(s string, length int, sep string) string {
+	return truncateMiddle(m, s, length, sep)
+}
+
 // Cut the string, without adding any prefix or tail strings. This function is
 // aware of ANSI escape codes and will not break them, and accounts for
 // wide-characters (such as East-Asian characters and emojis). Note that the
@@ -42,6 +51,15 @@ func (m Method) Cut(s string, left, right int) string {
 	return cut(m, s, left, right)
 }
 
+// SplitAt splits the string at the given column into a left part holding
+// the first col cells and a right part holding the rest. A wide character
+// crossing the column goes whole to the right part. This function is aware of
+// ANSI escape codes and will not break them, and accounts for wide-characters
+// (such as East-Asian characters and emojis).
+func (m Method) SplitAt(s string, col int) (left, right string) {
+	return splitAt(m, s, col)
+}
+
 // Hardwrap wraps a string or a block of text to a given line length, breaking
 // word boundaries. This will preserve ANSI escape codes and will account for
 // wide-characters in the string.
@@ -49,7 +67,7 @@ func (m Method) Cut(s string, left, right int) string {
 // preserved.
 // This treats the text as a sequence of graphemes.
 func (m Method) Hardwrap(s string, length int, preserveSpace bool) string {
-	return hardwrap(m, s, length, preserveSpace)
+	return hardwrap(m, s, length, preserveSpace, nil)
 }
 
 // Wordwrap wraps a string or a block of text to a given line length, not
@@ -197,6 +197,54 @@ func DECRPM(mode Mode, value ModeSetting) string {
 	return ReportMode(mode, value)
 }
 
+// DecodeModeReport decodes a mode report [DECRPM] sent by the terminal in
+// response to [RequestMode]. The returned mode is a [DECMode] for the DEC
+// format and an [ANSIMode] otherwise. It reports false if seq isn't a valid
+// report.
+//
+//	CSI Pa ; Ps $ y
+//	CSI ? Pa ; Ps $ y
+//
+// See: https://vt100.net/docs/vt510-rm/DECRPM.html
+func DecodeModeReport(seq string) (mode Mode, value ModeSetting, ok bool) {
+	if !HasCsiPrefix(seq) {
+		return nil, 0, false
+	}
+
+	p := GetParser()
+	defer PutParser(p)
+
+	s, _, n, _ := DecodeSequence(seq, NormalState, p)
+	if n != len(seq) || len(s) == 0 {
+		return nil, 0, false
+	}
+
+	cmd := Cmd(p.Command())
+	if cmd.Final() != 'y' || cmd.Intermediate() != '$' {
+		return nil, 0, false
+	}
+
+	params := p.Params()
+	if len(params) != 2 {
+		return nil, 0, false
+	}
+	m, v := params[0].Param(-1), params[1].Param(-1)
+	if m < 0 || v < 0 || v > int(ModePermanentlyReset) {
+		return nil, 0, false
+	}
+
+	switch cmd.Prefix() {
+	case 0:
+		mode = ANSIMode(m)
+	case '?':
+		mode = DECMode(m)
+	default:
+		return nil, 0, false
+	}
+
+	return mode, ModeSetting(v), true
+}
+
 // ANSIMode represents an ANSI terminal mode.
 type ANSIMode int //nolint:revive
 
@@ -316,6 +364,20 @@ const (
 	DisableCursorKeys = "\x1b[?1l"
 )
 
+// Screen Mode (DECSCNM) is a mode that determines whether the screen is
+// displayed in reverse video, with the foreground and background colors
+// swapped.
+//
+// See: https://vt100.net/docs/vt510-rm/DECSCNM.html
+const (
+	ScreenMode = DECMode(5)
+	DECSCNM    = ScreenMode
+
+	SetScreenMode     = "\x1b[?5h"
+	ResetScreenMode   = "\x1b[?5l"
+	RequestScreenMode = "\x1b[?5$p"
+)
+
 // Origin Mode (DECOM) is a mode that determines whether the cursor moves to the
 // home position or the margin position.
 //
@@ -293,3 +293,41 @@ func TestModeImplementations(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeModeReport(t *testing.T) {
+	cases := []struct {
+		name  string
+		seq   string
+		mode  Mode
+		value ModeSetting
+		ok    bool
+	}{
+		{"dec set", "\x1b[?2026;1$y", DECMode(2026), ModeSet, true},
+		{"dec not recognized", "\x1b[?2027;0$y", DECMode(2027), ModeNotRecognized, true},
+		{"ansi reset", "\x1b[4;2$y", ANSIMode(4), ModeReset, true},
+		{"permanently set", "\x1b[?1;3$y", DECMode(1), ModePermanentlySet, true},
+		{"permanently reset", "\x1b[20;4$y", ANSIMode(20), ModePermanentlyReset, true},
+		{"dec round trip", ReportMode(BracketedPasteMode, ModeSet), BracketedPasteMode, ModeSet, true},
+		{"ansi round trip", ReportMode(InsertReplaceMode, ModeReset), InsertReplaceMode, ModeReset, true},
+		{"c1 csi", "\x9b?25;2$y", DECMode(25), ModeReset, true},
+		{"unknown value", "\x1b[?25;5$y", nil, 0, false},
+		{"missing value", "\x1b[?25$y", nil, 0, false},
+		{"missing mode", "\x1b[?;1$y", nil, 0, false},
+		{"too many params", "\x1b[?25;1;2$y", nil, 0, false},
+		{"request", "\x1b[?25$p", nil, 0, false},
+		{"no intermediate", "\x1b[?25;1y", nil, 0, false},
+		{"wrong prefix", "\x1b[>25;1$y", nil, 0, false},
+		{"unterminated", "\x1b[?25;1$", nil, 0, false},
+		{"trailing data", "\x1b[?25;1$yx", nil, 0, false},
+		{"not a sequence", "?25;1$y", nil, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mode, value, ok := DecodeModeReport(tc.seq)
+			if mode != tc.mode || value != tc.value || ok != tc.ok {
+				t.Errorf("DecodeModeReport(%q) = %v, %d, %v; want %v, %d, %v",
+					tc.seq, mode, value, ok, tc.mode, tc.value, tc.ok)
+			}
+		})
+	}
+}
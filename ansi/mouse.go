@@ -2,6 +2,8 @@ package ansi
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // MouseButton represents the button that was pressed during a mouse message.
@@ -95,48 +97,159 @@ func (b MouseButton) String() string {
 // If button is [MouseNone], and motion is false, this returns a release event.
 // If button is undefined, this function returns 0xff.
 func EncodeMouseButton(b MouseButton, motion, shift, alt, ctrl bool) (m byte) {
-	// mouse bit shifts
-	const (
-		bitShift  = 0b0000_0100
-		bitAlt    = 0b0000_1000
-		bitCtrl   = 0b0001_0000
-		bitMotion = 0b0010_0000
-		bitWheel  = 0b0100_0000
-		bitAdd    = 0b1000_0000 // additional buttons 8-11
-
-		bitsMask = 0b0000_0011
-	)
-
 	if b == MouseNone {
-		m = bitsMask
+		m = mouseBitsMask
 	} else if b >= MouseLeft && b <= MouseRight {
 		m = byte(b - MouseLeft)
 	} else if b >= MouseWheelUp && b <= MouseWheelRight {
 		m = byte(b - MouseWheelUp)
-		m |= bitWheel
+		m |= mouseBitWheel
 	} else if b >= MouseBackward && b <= MouseButton11 {
 		m = byte(b - MouseBackward)
-		m |= bitAdd
+		m |= mouseBitAdd
 	} else {
 		m = 0xff // invalid button
 	}
 
 	if shift {
-		m |= bitShift
+		m |= mouseBitShift
 	}
 	if alt {
-		m |= bitAlt
+		m |= mouseBitAlt
 	}
 	if ctrl {
-		m |= bitCtrl
+		m |= mouseBitCtrl
 	}
 	if motion {
-		m |= bitMotion
+		m |= mouseBitMotion
 	}
 
 	return
 }
 
+// mouse bit shifts
+const (
+	mouseBitShift  = 0b0000_0100
+	mouseBitAlt    = 0b0000_1000
+	mouseBitCtrl   = 0b0001_0000
+	mouseBitMotion = 0b0010_0000
+	mouseBitWheel  = 0b0100_0000
+	mouseBitAdd    = 0b1000_0000 // additional buttons 8-11
+
+	mouseBitsMask = 0b0000_0011
+)
+
+// DecodeMouseButton decodes a mouse button byte as encoded by
+// [EncodeMouseButton]. A release event in X10 mode is reported as [MouseNone].
+// The motion bit is ignored for wheel events.
+func DecodeMouseButton(m byte) (b MouseButton, motion, shift, alt, ctrl bool) {
+	switch {
+	case m&mouseBitAdd != 0:
+		b = MouseBackward + MouseButton(m&mouseBitsMask)
+	case m&mouseBitWheel != 0:
+		b = MouseWheelUp + MouseButton(m&mouseBitsMask)
+	case m&mouseBitsMask == mouseBitsMask:
+		b = MouseNone
+	default:
+		b = MouseLeft + MouseButton(m&mouseBitsMask)
+	}
+
+	shift = m&mouseBitShift != 0
+	alt = m&mouseBitAlt != 0
+	ctrl = m&mouseBitCtrl != 0
+	motion = m&mouseBitMotion != 0 && (b < MouseWheelUp || b > MouseWheelRight)
+
+	return
+}
+
+// MouseEvent represents a decoded mouse event. The X and Y coordinates are
+// zero-based, i.e. the upper left cell is (0, 0).
+type MouseEvent struct {
+	// Button is the button that was pressed or released. It is [MouseNone]
+	// for motion events with no button held and for X10 release events.
+	Button MouseButton
+
+	// X and Y are the zero-based column and row of the event.
+	X, Y int
+
+	// Modifier keys held during the event.
+	Shift, Alt, Ctrl bool
+
+	// Motion reports whether the mouse moved, with or without a button held
+	// i.e. a drag event.
+	Motion bool
+
+	// Release reports whether the button was released. Wheel events never
+	// report a release.
+	Release bool
+}
+
+// DecodeMouse decodes a mouse event sequence. It recognizes the SGR extended
+// mouse encoding and falls back to the legacy X10 encoding. It reports false
+// if the sequence isn't a valid mouse event.
+//
+//	CSI < Cb ; Cx ; Cy M
+//	CSI < Cb ; Cx ; Cy m (release)
+//	CSI M Cb Cx Cy
+//
+// See: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#Mouse%20Tracking
+func DecodeMouse(seq string) (MouseEvent, bool) {
+	switch {
+	case strings.HasPrefix(seq, "\x1b[<"):
+		return decodeMouseSgr(seq[3:])
+	case strings.HasPrefix(seq, "\x1b[M"):
+		return decodeMouseX10(seq[3:])
+	}
+	return MouseEvent{}, false
+}
+
+func decodeMouseSgr(s string) (e MouseEvent, ok bool) {
+	if len(s) == 0 {
+		return e, false
+	}
+
+	final := s[len(s)-1]
+	if final != 'M' && final != 'm' {
+		return e, false
+	}
+
+	parts := strings.Split(s[:len(s)-1], ";")
+	if len(parts) != 3 {
+		return e, false
+	}
+
+	var v [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return e, false
+		}
+		v[i] = n
+	}
+	if v[0] > 0xff || v[1] < 1 || v[2] < 1 {
+		return e, false
+	}
+
+	e.Button, e.Motion, e.Shift, e.Alt, e.Ctrl = DecodeMouseButton(byte(v[0]))
+	e.X, e.Y = v[1]-1, v[2]-1
+	isWheel := e.Button >= MouseWheelUp && e.Button <= MouseWheelRight
+	e.Release = final == 'm' && !e.Motion && !isWheel
+
+	return e, true
+}
+
+func decodeMouseX10(s string) (e MouseEvent, ok bool) {
+	if len(s) != 3 || s[0] < x10Offset || s[1] <= x10Offset || s[2] <= x10Offset {
+		return e, false
+	}
+
+	e.Button, e.Motion, e.Shift, e.Alt, e.Ctrl = DecodeMouseButton(s[0] - x10Offset)
+	e.X, e.Y = int(s[1])-x10Offset-1, int(s[2])-x10Offset-1
+	e.Release = e.Button == MouseNone && !e.Motion
+
+	return e, true
+}
+
 // x10Offset is the offset for X10 mouse events.
 // See https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#Mouse%20Tracking
 const x10Offset = 32
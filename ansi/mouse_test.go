@@ -241,3 +241,125 @@ func TestMouseSgr(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeMouse(t *testing.T) {
+	cases := []struct {
+		name string
+		seq  string
+		want MouseEvent
+		ok   bool
+	}{
+		{
+			name: "sgr left click",
+			seq:  "\x1b[<0;10;5M",
+			want: MouseEvent{Button: MouseLeft, X: 9, Y: 4},
+			ok:   true,
+		},
+		{
+			name: "sgr left release",
+			seq:  "\x1b[<0;10;5m",
+			want: MouseEvent{Button: MouseLeft, X: 9, Y: 4, Release: true},
+			ok:   true,
+		},
+		{
+			name: "sgr right release with shift and ctrl",
+			seq:  "\x1b[<22;1;1m",
+			want: MouseEvent{Button: MouseRight, Shift: true, Ctrl: true, Release: true},
+			ok:   true,
+		},
+		{
+			name: "sgr wheel up",
+			seq:  "\x1b[<64;3;4M",
+			want: MouseEvent{Button: MouseWheelUp, X: 2, Y: 3},
+			ok:   true,
+		},
+		{
+			name: "sgr wheel down with alt",
+			seq:  "\x1b[<73;3;4M",
+			want: MouseEvent{Button: MouseWheelDown, X: 2, Y: 3, Alt: true},
+			ok:   true,
+		},
+		{
+			name: "sgr left drag",
+			seq:  "\x1b[<32;20;10M",
+			want: MouseEvent{Button: MouseLeft, X: 19, Y: 9, Motion: true},
+			ok:   true,
+		},
+		{
+			name: "sgr motion without buttons",
+			seq:  "\x1b[<35;20;10M",
+			want: MouseEvent{Button: MouseNone, X: 19, Y: 9, Motion: true},
+			ok:   true,
+		},
+		{
+			name: "sgr backward button",
+			seq:  "\x1b[<128;1;1M",
+			want: MouseEvent{Button: MouseBackward},
+			ok:   true,
+		},
+		{
+			name: "sgr large coordinates",
+			seq:  "\x1b[<0;300;400M",
+			want: MouseEvent{Button: MouseLeft, X: 299, Y: 399},
+			ok:   true,
+		},
+		{
+			name: "x10 left click",
+			seq:  MouseX10(EncodeMouseButton(MouseLeft, false, false, false, false), 9, 4),
+			want: MouseEvent{Button: MouseLeft, X: 9, Y: 4},
+			ok:   true,
+		},
+		{
+			name: "x10 release with ctrl",
+			seq:  MouseX10(EncodeMouseButton(MouseNone, false, false, false, true), 0, 0),
+			want: MouseEvent{Button: MouseNone, Ctrl: true, Release: true},
+			ok:   true,
+		},
+		{
+			name: "x10 middle drag",
+			seq:  MouseX10(EncodeMouseButton(MouseMiddle, true, false, false, false), 5, 6),
+			want: MouseEvent{Button: MouseMiddle, X: 5, Y: 6, Motion: true},
+			ok:   true,
+		},
+		{
+			name: "x10 wheel up",
+			seq:  MouseX10(EncodeMouseButton(MouseWheelUp, false, false, false, false), 1, 2),
+			want: MouseEvent{Button: MouseWheelUp, X: 1, Y: 2},
+			ok:   true,
+		},
+		{name: "sgr missing params", seq: "\x1b[<0;10M"},
+		{name: "sgr invalid final", seq: "\x1b[<0;10;5X"},
+		{name: "sgr zero coordinate", seq: "\x1b[<0;0;5M"},
+		{name: "sgr invalid number", seq: "\x1b[<a;1;1M"},
+		{name: "x10 too short", seq: "\x1b[M !"},
+		{name: "not a mouse sequence", seq: "\x1b[A"},
+		{name: "empty", seq: ""},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := DecodeMouse(tc.seq)
+			if ok != tc.ok {
+				t.Fatalf("test %d: got ok %v; want %v", i+1, ok, tc.ok)
+			}
+			if got != tc.want {
+				t.Errorf("test %d: got %+v; want %+v", i+1, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeMouseButton(t *testing.T) {
+	for _, btn := range []MouseButton{
+		MouseLeft, MouseMiddle, MouseRight,
+		MouseBackward, MouseForward, MouseButton10, MouseButton11,
+	} {
+		for _, motion := range []bool{false, true} {
+			m := EncodeMouseButton(btn, motion, true, false, true)
+			b, gotMotion, shift, alt, ctrl := DecodeMouseButton(m)
+			if b != btn || gotMotion != motion || !shift || alt || !ctrl {
+				t.Errorf("button %s, motion %v: got %s, %v, %v, %v, %v", btn, motion, b, gotMotion, shift, alt, ctrl)
+			}
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package ansi
 
+import "strings"
+
 // Notify sends a desktop notification using iTerm's OSC 9.
 //
 //	OSC 9 ; Mc ST
@@ -11,3 +13,34 @@ package ansi
 func Notify(s string) string {
 	return "\x1b]9;" + s + "\x07"
 }
+
+// DecodeNotification decodes a desktop notification sequence terminated by
+// either BEL or ST. It handles iTerm's OSC 9, which only has a body and
+// returns an empty title, and rxvt's OSC 777 notify, where the title ends at
+// the first semicolon and the body may contain more. It reports false if seq
+// isn't one of these sequences.
+//
+//	OSC 9 ; Mc ST
+//	OSC 777 ; notify ; Pt ; Mc ST
+//
+// Note that ConEmu uses OSC 9 followed by a number and a semicolon for other
+// purposes, such as progress reports, which are decoded as a body too.
+//
+// See: https://iterm2.com/documentation-escape-codes.html
+func DecodeNotification(seq string) (title, body string, ok bool) {
+	if payload, ok := oscPayload(seq, "9"); ok {
+		return "", payload, true
+	}
+
+	payload, ok := oscPayload(seq, "777")
+	if !ok || !strings.HasPrefix(payload, "notify;") {
+		return "", "", false
+	}
+
+	title, body, ok = strings.Cut(payload[len("notify;"):], ";")
+	if !ok {
+		return "", "", false
+	}
+
+	return title, body, true
+}
@@ -0,0 +1,40 @@
+package ansi_test
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestDecodeNotification(t *testing.T) {
+	cases := []struct {
+		name        string
+		seq         string
+		title, body string
+		ok          bool
+	}{
+		{"osc 9", "\x1b]9;Build finished\x07", "", "Build finished", true},
+		{"osc 9 st", "\x1b]9;Build finished\x1b\\", "", "Build finished", true},
+		{"osc 9 round trip", ansi.Notify("hello"), "", "hello", true},
+		{"osc 9 semicolons", "\x1b]9;a;b;c\x07", "", "a;b;c", true},
+		{"osc 9 empty", "\x1b]9;\x07", "", "", true},
+		{"osc 777", "\x1b]777;notify;Build;Finished in 3s\x07", "Build", "Finished in 3s", true},
+		{"osc 777 st", "\x9d777;notify;Build;Done\x9c", "Build", "Done", true},
+		{"osc 777 semicolons", "\x1b]777;notify;Tests;3 passed; 1 failed\x07", "Tests", "3 passed; 1 failed", true},
+		{"osc 777 empty body", "\x1b]777;notify;Title;\x07", "Title", "", true},
+		{"osc 777 no body", "\x1b]777;notify;Title\x07", "", "", false},
+		{"osc 777 other command", "\x1b]777;preexec\x07", "", "", false},
+		{"other osc", "\x1b]99;hello\x07", "", "", false},
+		{"unterminated", "\x1b]9;hello", "", "", false},
+		{"not a sequence", "9;hello", "", "", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			title, body, ok := ansi.DecodeNotification(tc.seq)
+			if title != tc.title || body != tc.body || ok != tc.ok {
+				t.Errorf("DecodeNotification(%q) = %q, %q, %v; want %q, %q, %v",
+					tc.seq, title, body, ok, tc.title, tc.body, tc.ok)
+			}
+		})
+	}
+}
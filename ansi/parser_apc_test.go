@@ -1,7 +1,10 @@
 package ansi
 
 import (
+	"bytes"
 	"testing"
+
+	"github.com/charmbracelet/x/ansi/kitty"
 )
 
 func TestSosPmApcSequence(t *testing.T) {
@@ -26,3 +29,31 @@ func TestSosPmApcSequence(t *testing.T) {
 		})
 	}
 }
+
+func TestApcSplitAcrossParse(t *testing.T) {
+	dispatcher := &testDispatcher{}
+	parser := testParser(dispatcher)
+	parser.Parse([]byte("\x1b_Ga=T,f=100,i=7;iVBO"))
+	if len(dispatcher.dispatched) != 0 {
+		t.Fatalf("expected no dispatch before the terminator, got %v", dispatcher.dispatched)
+	}
+	parser.Parse([]byte("Rw0K\x1b\\"))
+
+	expected := []any{[]byte("Ga=T,f=100,i=7;iVBORw0K"), Cmd('\\')}
+	assertEqual(t, expected, dispatcher.dispatched)
+
+	// Decode the Kitty graphics header.
+	data := dispatcher.dispatched[0].([]byte)
+	if data[0] != 'G' {
+		t.Fatalf("expected a Kitty graphics command, got %q", data)
+	}
+	header, payload, _ := bytes.Cut(data[1:], []byte{';'})
+	var opts kitty.Options
+	if err := opts.UnmarshalText(header); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Action != kitty.TransmitAndPut || opts.Format != kitty.PNG || opts.ID != 7 {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	assertEqual(t, "iVBORw0K", string(payload))
+}
@@ -68,6 +68,20 @@ func TestDcsSequence(t *testing.T) {
 				Cmd('\\'),
 			},
 		},
+		{
+			name:  "decrqss",
+			input: "a\x1bP$qm\x1b\\b",
+			expected: []any{
+				rune('a'),
+				dcsSequence{
+					Cmd:    'q' | '$'<<parser.IntermedShift,
+					Params: Params{},
+					Data:   []byte("m"),
+				},
+				Cmd('\\'),
+				rune('b'),
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -80,3 +94,13 @@ func TestDcsSequence(t *testing.T) {
 		})
 	}
 }
+
+func TestDcsIgnoredByWidth(t *testing.T) {
+	input := "a\x1bP$qm\x1b\\b\x1bP?123$p\x9c"
+	if w := StringWidth(input); w != 2 {
+		t.Errorf("expected width 2, got %d", w)
+	}
+	if s := Strip(input); s != "ab" {
+		t.Errorf("expected %q, got %q", "ab", s)
+	}
+}
@@ -449,6 +449,26 @@ func FirstGraphemeCluster[T string | []byte](b T, state int) (T, T, int, int) {
 	panic("unreachable")
 }
 
+// Graphemes splits a string into its extended grapheme clusters, the units
+// measured by [StringWidth]. Escape sequences are kept whole as their own
+// zero-width segments, and never split or join the clusters around them.
+// Concatenating the segments returns the original string.
+func Graphemes(s string) []string {
+	var segs []string
+	for len(s) > 0 {
+		var seg string
+		if s[0] == ESC || s[0] >= 0x80 && s[0] <= 0x9F {
+			// C1 control characters are never part of a valid UTF-8 grapheme.
+			seg, _, _, _ = DecodeSequence(s, NormalState, nil)
+		} else {
+			seg, _, _, _ = FirstGraphemeCluster(s, -1)
+		}
+		segs = append(segs, seg)
+		s = s[len(seg):]
+	}
+	return segs
+}
+
 // Cmd represents a sequence command. This is used to pack/unpack a sequence
 // command with its intermediate and prefix characters. Those are commonly
 // found in CSI and DCS sequences.
@@ -1,6 +1,8 @@
 package ansi
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/x/ansi/parser"
@@ -508,3 +510,33 @@ func TestParameter(t *testing.T) {
 		})
 	}
 }
+
+func TestGraphemes(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"empty", "", nil},
+		{"ascii", "abc", []string{"a", "b", "c"}},
+		{"combining", "e\u0301x", []string{"e\u0301", "x"}},
+		{"zwj emoji", "a👨\u200d👩\u200d👦b", []string{"a", "👨\u200d👩\u200d👦", "b"}},
+		{"flags", "🇸🇦🇺🇸", []string{"🇸🇦", "🇺🇸"}},
+		{"crlf", "a\r\nb", []string{"a", "\r\n", "b"}},
+		{"sgr", "\x1b[31m你\x1b[1m好\x1b[m", []string{"\x1b[31m", "你", "\x1b[1m", "好", "\x1b[m"}},
+		{"sgr between combining", "e\x1b[31m\u0301", []string{"e", "\x1b[31m", "\u0301"}},
+		{"c1 csi", "a\x9b1mb", []string{"a", "\x9b1m", "b"}},
+		{"hyperlink", "\x1b]8;;https://example.com\x07go\x1b]8;;\x07", []string{"\x1b]8;;https://example.com\x07", "g", "o", "\x1b]8;;\x07"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Graphemes(c.input)
+			if !reflect.DeepEqual(got, c.expected) {
+				t.Errorf("expected %q, got %q", c.expected, got)
+			}
+			if strings.Join(got, "") != c.input {
+				t.Errorf("expected the segments to join to %q, got %q", c.input, strings.Join(got, ""))
+			}
+		})
+	}
+}
@@ -48,7 +48,12 @@ type Handler struct {
 	HandleOsc func(cmd int, data []byte)
 	// HandlePm is called when a PM sequence is encountered.
 	HandlePm func(data []byte)
-	// HandleApc is called when an APC sequence is encountered.
+	// HandleApc is called when an APC sequence is encountered, with the
+	// payload between the APC introducer and the string terminator. The
+	// payload is delivered once complete, even if the sequence is split across
+	// calls to [Parser.Parse]. For example, a Kitty graphics command's payload
+	// starts with 'G' followed by its key/value header up to the ';', which
+	// [kitty.Options.UnmarshalText] decodes.
 	HandleApc func(data []byte)
 	// HandleSos is called when a SOS sequence is encountered.
 	HandleSos func(data []byte)
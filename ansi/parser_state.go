@@ -0,0 +1,113 @@
+package ansi
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/charmbracelet/x/ansi/parser"
+)
+
+// parserStateVersion is the version of the serialized parser state format.
+const parserStateVersion = 1
+
+// ErrInvalidParserState is returned by [Parser.UnmarshalState] when the given
+// state is malformed or doesn't fit in the parser buffers.
+var ErrInvalidParserState = errors.New("ansi: invalid parser state")
+
+// MarshalState returns a snapshot of the parser's in-progress state. This
+// includes the current state, the collected parameters, command, and data of
+// a partially parsed sequence. Use [Parser.UnmarshalState] to restore it into
+// a parser, for example one taken from [GetParser], and resume parsing where
+// it left off. The handler and buffer sizes of the parser aren't included.
+func (p *Parser) MarshalState() []byte {
+	n := min(p.paramsLen+1, len(p.params))
+	data := p.data
+	if p.dataLen >= 0 {
+		data = data[:p.dataLen]
+	}
+
+	b := make([]byte, 0, 2+binary.MaxVarintLen64*(3+n)+len(data))
+	b = append(b, parserStateVersion, p.state)
+	b = appendVarint(b, int64(p.cmd))
+	b = appendVarint(b, int64(p.paramsLen))
+	b = appendVarint(b, int64(n))
+	for _, param := range p.params[:n] {
+		b = appendVarint(b, int64(param))
+	}
+	b = appendVarint(b, int64(len(data)))
+	return append(b, data...)
+}
+
+// UnmarshalState restores a parser state previously returned by
+// [Parser.MarshalState]. The parser keeps its handler and buffers, which must
+// be large enough to hold the restored parameters and data. It returns
+// [ErrInvalidParserState] if the state is malformed or doesn't fit, in which
+// case the parser is reset.
+func (p *Parser) UnmarshalState(state []byte) error {
+	if err := p.unmarshalState(state); err != nil {
+		p.Reset()
+		return err
+	}
+	return nil
+}
+
+func (p *Parser) unmarshalState(b []byte) error {
+	if len(b) < 2 || b[0] != parserStateVersion {
+		return ErrInvalidParserState
+	}
+	state := b[1]
+	b = b[2:]
+
+	var ok bool
+	var cmd, paramsLen, n, dataLen int64
+	if cmd, b, ok = readVarint(b); !ok {
+		return ErrInvalidParserState
+	}
+	if state > parser.Utf8State {
+		return ErrInvalidParserState
+	}
+	if paramsLen, b, ok = readVarint(b); !ok || paramsLen < 0 || paramsLen > int64(len(p.params)) {
+		return ErrInvalidParserState
+	}
+	if n, b, ok = readVarint(b); !ok || n < 0 || n > int64(len(p.params)) {
+		return ErrInvalidParserState
+	}
+	for i := int64(0); i < n; i++ {
+		var param int64
+		if param, b, ok = readVarint(b); !ok {
+			return ErrInvalidParserState
+		}
+		p.params[i] = int(param)
+	}
+	if dataLen, b, ok = readVarint(b); !ok || dataLen != int64(len(b)) {
+		return ErrInvalidParserState
+	}
+
+	if p.dataLen < 0 {
+		p.data = append(p.data[:0], b...)
+	} else {
+		if len(b) > len(p.data) {
+			return ErrInvalidParserState
+		}
+		p.dataLen = copy(p.data, b)
+	}
+
+	p.state = state
+	p.cmd = int(cmd)
+	p.paramsLen = int(paramsLen)
+	return nil
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func readVarint(b []byte) (int64, []byte, bool) {
+	v, n := binary.Varint(b)
+	if n <= 0 {
+		return 0, b, false
+	}
+	return v, b[n:], true
+}
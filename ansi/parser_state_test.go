@@ -0,0 +1,85 @@
+package ansi
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi/parser"
+)
+
+func TestParserMarshalState(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		split int
+	}{
+		{"csi params", "\x1b[38;2;255;128;0mA", 10},
+		{"csi sub params", "\x1b[4:3;58:2::1:2:3mA", 8},
+		{"csi prefix", "\x1b[?1049hA", 4},
+		{"osc", "\x1b]8;id=1;https://charm.sh\x07A", 12},
+		{"dcs", "\x1bP$qm\x1b\\A", 4},
+		{"utf8", "日本", 2},
+		{"esc", "\x1b(BA", 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			whole := &testDispatcher{}
+			testParser(whole).Parse([]byte(c.input))
+
+			first := &testDispatcher{}
+			p := testParser(first)
+			p.Parse([]byte(c.input[:c.split]))
+			state := p.MarshalState()
+
+			// Resume decoding using a different parser.
+			second := &testDispatcher{dispatched: first.dispatched}
+			p = testParser(second)
+			if err := p.UnmarshalState(state); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			p.Parse([]byte(c.input[c.split:]))
+
+			if !reflect.DeepEqual(whole.dispatched, second.dispatched) {
+				t.Errorf("expected %#v, got %#v", whole.dispatched, second.dispatched)
+			}
+		})
+	}
+}
+
+func TestParserUnmarshalStateInvalid(t *testing.T) {
+	p := testParser(&testDispatcher{})
+	p.Parse([]byte("\x1b]2;title"))
+	state := p.MarshalState()
+
+	cases := map[string][]byte{
+		"empty":       nil,
+		"version":     append([]byte{0}, state[1:]...),
+		"truncated":   state[:len(state)-1],
+		"trailing":    append(append([]byte{}, state...), 'x'),
+		"only header": state[:2],
+		"bad state":   append([]byte{state[0], 250}, state[2:]...),
+		// A CSI sequence claiming more parameters than the parser holds.
+		"params length": appendVarint(appendVarint(appendVarint(appendVarint(
+			[]byte{parserStateVersion, parser.CsiParamState}, 0), 1000), 0), 0),
+		"negative params length": appendVarint(appendVarint(appendVarint(appendVarint(
+			[]byte{parserStateVersion, parser.CsiParamState}, 0), -1), 0), 0),
+	}
+	for name, b := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := testParser(&testDispatcher{})
+			if err := p.UnmarshalState(b); !errors.Is(err, ErrInvalidParserState) {
+				t.Errorf("expected %v, got %v", ErrInvalidParserState, err)
+			}
+		})
+	}
+
+	t.Run("data too large", func(t *testing.T) {
+		p := NewParser()
+		p.SetDataSize(4)
+		if err := p.UnmarshalState(state); !errors.Is(err, ErrInvalidParserState) {
+			t.Errorf("expected %v, got %v", ErrInvalidParserState, err)
+		}
+	})
+}
@@ -1,7 +1,123 @@
 package ansi
 
+import (
+	"bytes"
+	"io"
+)
+
 // BracketedPasteStart is the control sequence to enable bracketed paste mode.
 const BracketedPasteStart = "\x1b[200~"
 
 // BracketedPasteEnd is the control sequence to disable bracketed paste mode.
 const BracketedPasteEnd = "\x1b[201~"
+
+// IsPasteStart reports whether seq is the bracketed paste start marker sent
+// by the terminal before pasted content when [BracketedPasteMode] is enabled.
+//
+//	CSI 200 ~
+func IsPasteStart(seq string) bool {
+	return seq == BracketedPasteStart || seq == "\x9b200~"
+}
+
+// IsPasteEnd reports whether seq is the bracketed paste end marker sent by
+// the terminal after pasted content when [BracketedPasteMode] is enabled.
+//
+//	CSI 201 ~
+func IsPasteEnd(seq string) bool {
+	return seq == BracketedPasteEnd || seq == "\x9b201~"
+}
+
+// PasteReader wraps an [io.Reader] receiving terminal input and splits it
+// into regular input and pasted content using the bracketed paste markers.
+// Pasted content is buffered until the end marker is read and returned as a
+// single chunk without the markers.
+//
+// Only the 7-bit form of the markers is recognized since the 8-bit CSI byte
+// is also a valid UTF-8 continuation byte.
+type PasteReader struct {
+	r       io.Reader
+	buf     []byte
+	rbuf    []byte
+	err     error
+	inPaste bool
+}
+
+// NewPasteReader returns a new [PasteReader] reading from r.
+func NewPasteReader(r io.Reader) *PasteReader {
+	return &PasteReader{r: r}
+}
+
+// Next returns the next chunk of input and whether it's pasted content. A
+// chunk of regular input never contains a paste marker, while a chunk of
+// pasted content holds everything between the start and end markers. If the
+// underlying reader ends in the middle of a paste, the content read so far is
+// returned as pasted content. Once all the input is consumed, Next returns
+// the error of the underlying reader, [io.EOF] at the end of the input.
+func (p *PasteReader) Next() (chunk []byte, paste bool, err error) {
+	for {
+		if p.inPaste {
+			if i := bytes.Index(p.buf, []byte(BracketedPasteEnd)); i >= 0 {
+				chunk = append([]byte{}, p.buf[:i]...)
+				p.buf = p.buf[i+len(BracketedPasteEnd):]
+				p.inPaste = false
+				return chunk, true, nil
+			}
+			if p.err != nil {
+				chunk = append([]byte{}, p.buf...)
+				p.buf = p.buf[:0]
+				p.inPaste = false
+				return chunk, true, nil
+			}
+		} else {
+			if i := bytes.Index(p.buf, []byte(BracketedPasteStart)); i > 0 {
+				chunk = append([]byte{}, p.buf[:i]...)
+				p.buf = p.buf[i:]
+				return chunk, false, nil
+			} else if i == 0 {
+				p.buf = p.buf[len(BracketedPasteStart):]
+				p.inPaste = true
+				continue
+			}
+
+			// Hold back a trailing partial start marker until we know
+			// whether it's followed by the rest of the marker.
+			n := len(p.buf)
+			if p.err == nil {
+				n -= partialSuffixLen(p.buf, BracketedPasteStart)
+			}
+			if n > 0 {
+				chunk = append([]byte{}, p.buf[:n]...)
+				p.buf = p.buf[n:]
+				return chunk, false, nil
+			}
+			if p.err != nil {
+				return nil, false, p.err
+			}
+		}
+
+		p.fill()
+	}
+}
+
+// fill reads more data from the underlying reader into the buffer.
+func (p *PasteReader) fill() {
+	if p.rbuf == nil {
+		p.rbuf = make([]byte, 4096)
+	}
+	n, err := p.r.Read(p.rbuf)
+	p.buf = append(p.buf, p.rbuf[:n]...)
+	if err != nil {
+		p.err = err
+	}
+}
+
+// partialSuffixLen returns the length of the longest suffix of b that is a
+// proper prefix of marker.
+func partialSuffixLen(b []byte, marker string) int {
+	for n := min(len(b), len(marker)-1); n > 0; n-- {
+		if string(b[len(b)-n:]) == marker[:n] {
+			return n
+		}
+	}
+	return 0
+}
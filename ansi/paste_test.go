@@ -0,0 +1,116 @@
+package ansi
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestIsPasteMarkers(t *testing.T) {
+	if !IsPasteStart(BracketedPasteStart) || !IsPasteStart("\x9b200~") {
+		t.Error("expected paste start markers to be recognized")
+	}
+	if !IsPasteEnd(BracketedPasteEnd) || !IsPasteEnd("\x9b201~") {
+		t.Error("expected paste end markers to be recognized")
+	}
+	for _, seq := range []string{"\x1b[200", "\x1b[20~", "\x1b[200~a", "\x1b[?200~"} {
+		if IsPasteStart(seq) || IsPasteEnd(seq) {
+			t.Errorf("unexpected paste marker %q", seq)
+		}
+	}
+	if IsPasteStart(BracketedPasteEnd) || IsPasteEnd(BracketedPasteStart) {
+		t.Error("paste markers must not be confused")
+	}
+}
+
+type pasteChunk struct {
+	data  string
+	paste bool
+}
+
+func readPasteChunks(t *testing.T, r io.Reader) []pasteChunk {
+	t.Helper()
+	var chunks []pasteChunk
+	pr := NewPasteReader(r)
+	for {
+		chunk, paste, err := pr.Next()
+		if errors.Is(err, io.EOF) {
+			return chunks
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// Merge consecutive regular input chunks since their boundaries
+		// depend on how the underlying reader splits the input.
+		if n := len(chunks); !paste && n > 0 && !chunks[n-1].paste {
+			chunks[n-1].data += string(chunk)
+			continue
+		}
+		chunks = append(chunks, pasteChunk{string(chunk), paste})
+	}
+}
+
+func TestPasteReader(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []pasteChunk
+	}{
+		{
+			name:  "no paste",
+			input: "abc\x1b[A",
+			want:  []pasteChunk{{"abc\x1b[A", false}},
+		},
+		{
+			name:  "interleaved keys and paste",
+			input: "ab" + BracketedPasteStart + "hello\nworld\x1b[A" + BracketedPasteEnd + "c\r",
+			want: []pasteChunk{
+				{"ab", false},
+				{"hello\nworld\x1b[A", true},
+				{"c\r", false},
+			},
+		},
+		{
+			name:  "consecutive pastes",
+			input: BracketedPasteStart + "one" + BracketedPasteEnd + BracketedPasteStart + BracketedPasteEnd,
+			want: []pasteChunk{
+				{"one", true},
+				{"", true},
+			},
+		},
+		{
+			name:  "unterminated paste",
+			input: "x" + BracketedPasteStart + "partial",
+			want: []pasteChunk{
+				{"x", false},
+				{"partial", true},
+			},
+		},
+		{
+			name:  "partial start marker at end",
+			input: "x\x1b[20",
+			want:  []pasteChunk{{"x\x1b[20", false}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			readers := map[string]io.Reader{
+				"whole":    strings.NewReader(tc.input),
+				"one byte": iotest.OneByteReader(strings.NewReader(tc.input)),
+			}
+			for name, r := range readers {
+				got := readPasteChunks(t, r)
+				if len(got) != len(tc.want) {
+					t.Fatalf("%s: expected %d chunks, got %d: %+v", name, len(tc.want), len(got), got)
+				}
+				for i := range got {
+					if got[i] != tc.want[i] {
+						t.Errorf("%s: chunk %d: expected %+v, got %+v", name, i, tc.want[i], got[i])
+					}
+				}
+			}
+		})
+	}
+}
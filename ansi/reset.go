@@ -9,3 +9,14 @@ const (
 	ResetInitialState = "\x1bc"
 	RIS               = ResetInitialState
 )
+
+// SoftTerminalReset (DECSTR) resets the terminal modes, margins, and graphic
+// rendition to their default values without clearing the screen.
+//
+//	CSI ! p
+//
+// See: https://vt100.net/docs/vt510-rm/DECSTR.html
+const (
+	SoftTerminalReset = "\x1b[!p"
+	DECSTR            = SoftTerminalReset
+)
@@ -408,3 +408,136 @@ func RepeatPreviousCharacter(n int) string {
 func REP(n int) string {
 	return RepeatPreviousCharacter(n)
 }
+
+// ScreenAlignmentPattern (DECALN) fills the whole screen with the letter E.
+// It also resets the margins and moves the cursor to the upper left corner.
+// It's used to test the screen alignment.
+//
+//	ESC # 8
+//
+// See: https://vt100.net/docs/vt510-rm/DECALN.html
+const (
+	ScreenAlignmentPattern = "\x1b#8"
+	DECALN                 = ScreenAlignmentPattern
+)
+
+// DoubleHeightTopLine (DECDHL) makes the cursor line the top half of a
+// double-height, double-width line.
+//
+//	ESC # 3
+//
+// See: https://vt100.net/docs/vt510-rm/DECDHL.html
+const (
+	DoubleHeightTopLine = "\x1b#3"
+	DECDHLTop           = DoubleHeightTopLine
+)
+
+// DoubleHeightBottomLine (DECDHL) makes the cursor line the bottom half of a
+// double-height, double-width line.
+//
+//	ESC # 4
+//
+// See: https://vt100.net/docs/vt510-rm/DECDHL.html
+const (
+	DoubleHeightBottomLine = "\x1b#4"
+	DECDHLBottom           = DoubleHeightBottomLine
+)
+
+// SingleWidthLine (DECSWL) makes the cursor line a single-width,
+// single-height line. This is the default for all lines.
+//
+//	ESC # 5
+//
+// See: https://vt100.net/docs/vt510-rm/DECSWL.html
+const (
+	SingleWidthLine = "\x1b#5"
+	DECSWL          = SingleWidthLine
+)
+
+// DoubleWidthLine (DECDWL) makes the cursor line a double-width,
+// single-height line. A double-width line holds half as many characters.
+//
+//	ESC # 6
+//
+// See: https://vt100.net/docs/vt510-rm/DECDWL.html
+const (
+	DoubleWidthLine = "\x1b#6"
+	DECDWL          = DoubleWidthLine
+)
+
+// FillRectangularArea (DECFRA) fills a rectangular area with the given
+// character using the current character attributes. The rectangle is given by
+// its top, left, bottom, and right edges. Zero values use the defaults.
+//
+// Default is the whole screen.
+//
+//	CSI Pch ; Pt ; Pl ; Pb ; Pr $ x
+//
+// See: https://vt100.net/docs/vt510-rm/DECFRA.html
+func FillRectangularArea(c byte, top, left, bottom, right int) string {
+	return "\x1b[" + strconv.Itoa(int(c)) + ";" + rectParams(top, left, bottom, right) + "$x"
+}
+
+// DECFRA is an alias for [FillRectangularArea].
+func DECFRA(c byte, top, left, bottom, right int) string {
+	return FillRectangularArea(c, top, left, bottom, right)
+}
+
+// EraseRectangularArea (DECERA) erases a rectangular area. The rectangle is
+// given by its top, left, bottom, and right edges. Zero values use the
+// defaults.
+//
+// Default is the whole screen.
+//
+//	CSI Pt ; Pl ; Pb ; Pr $ z
+//
+// See: https://vt100.net/docs/vt510-rm/DECERA.html
+func EraseRectangularArea(top, left, bottom, right int) string {
+	return "\x1b[" + rectParams(top, left, bottom, right) + "$z"
+}
+
+// DECERA is an alias for [EraseRectangularArea].
+func DECERA(top, left, bottom, right int) string {
+	return EraseRectangularArea(top, left, bottom, right)
+}
+
+// CopyRectangularArea (DECCRA) copies a rectangular area to another position.
+// The source rectangle is given by its top, left, bottom, and right edges, and
+// the destination by its top-left corner. Zero values use the defaults. The
+// page parameters are always omitted.
+//
+// Default is the whole screen copied to the upper left corner.
+//
+//	CSI Pts ; Pls ; Pbs ; Prs ; Pps ; Ptd ; Pld ; Ppd $ v
+//
+// See: https://vt100.net/docs/vt510-rm/DECCRA.html
+func CopyRectangularArea(top, left, bottom, right, dstTop, dstLeft int) string {
+	var t, l string
+	if dstTop > 0 {
+		t = strconv.Itoa(dstTop)
+	}
+	if dstLeft > 0 {
+		l = strconv.Itoa(dstLeft)
+	}
+	return "\x1b[" + rectParams(top, left, bottom, right) + ";;" + t + ";" + l + "$v"
+}
+
+// DECCRA is an alias for [CopyRectangularArea].
+func DECCRA(top, left, bottom, right, dstTop, dstLeft int) string {
+	return CopyRectangularArea(top, left, bottom, right, dstTop, dstLeft)
+}
+
+// rectParams returns the parameters of a rectangular area, leaving out the
+// zero values.
+func rectParams(top, left, bottom, right int) string {
+	var b strings.Builder
+	for i, n := range [4]int{top, left, bottom, right} {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+		if n > 0 {
+			b.WriteString(strconv.Itoa(n))
+		}
+	}
+	return b.String()
+}
@@ -73,6 +73,8 @@ var attrStrings = map[int]string{
 	WhiteBackgroundColorAttr:         "47",
 	ExtendedBackgroundColorAttr:      "48",
 	DefaultBackgroundColorAttr:       "49",
+	OverlineAttr:                     "53",
+	NoOverlineAttr:                   "55",
 	ExtendedUnderlineColorAttr:       "58",
 	DefaultUnderlineColorAttr:        "59",
 	BrightBlackForegroundColorAttr:   "90",
@@ -0,0 +1,326 @@
+package ansi
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// ErrSixelPaletteOverflow is returned by [DecodeSixel] when the sixel data
+// uses more color registers than fit in an [image.Paletted] image.
+var ErrSixelPaletteOverflow = errors.New("sixel: too many color registers")
+
+// ErrSixelTooLarge is returned by [DecodeSixel] when the image is wider or
+// taller than [SixelMaxSize] pixels.
+var ErrSixelTooLarge = errors.New("sixel: image too large")
+
+// SixelMaxSize is the maximum width and height in pixels of an image decoded
+// by [DecodeSixel]. It bounds the memory a small sixel sequence can make the
+// decoder allocate.
+const SixelMaxSize = 4096
+
+// sixelDefaultPalette is the default VT340 color palette used for color
+// registers that are selected without being defined first.
+var sixelDefaultPalette = [16]color.RGBA{
+	{0, 0, 0, 255},
+	{51, 51, 204, 255},
+	{204, 33, 33, 255},
+	{51, 204, 51, 255},
+	{204, 51, 204, 255},
+	{51, 204, 204, 255},
+	{204, 204, 51, 255},
+	{120, 120, 120, 255},
+	{69, 69, 69, 255},
+	{87, 87, 153, 255},
+	{153, 69, 69, 255},
+	{87, 153, 87, 255},
+	{153, 87, 153, 255},
+	{87, 153, 153, 255},
+	{153, 153, 87, 255},
+	{204, 204, 204, 255},
+}
+
+// DecodeSixel decodes sixel image data into a paletted image. The data is the
+// part of a sixel DCS sequence between the final "q" and the string
+// terminator.
+//
+//	DCS P1 ; P2 ; P3 q [sixel data] ST
+//
+// It handles raster attributes ("), color introducers (#), repeat introducers
+// (!), and graphics carriage returns ($) and new lines (-). Pixels that are
+// never painted use the first palette entry, which is transparent. When the
+// raster attributes specify the image size, it is used instead of the size of
+// the painted area. It returns [ErrSixelTooLarge] if either size is larger
+// than [SixelMaxSize].
+//
+// See: https://vt100.net/docs/vt3xx-gp/chapter14.html
+func DecodeSixel(data []byte) (*image.Paletted, error) {
+	d := sixelDecoder{
+		palette:   color.Palette{color.Transparent},
+		registers: map[int]uint8{},
+	}
+	if err := d.decode(data); err != nil {
+		return nil, err
+	}
+
+	w, h := d.width, d.height
+	if d.rasterWidth > 0 {
+		w = d.rasterWidth
+	}
+	if d.rasterHeight > 0 {
+		h = d.rasterHeight
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, w, h), d.palette)
+	for y := 0; y < h && y < len(d.pixels); y++ {
+		copy(img.Pix[y*img.Stride:y*img.Stride+w], d.pixels[y])
+	}
+
+	return img, nil
+}
+
+// sixelDecoder holds the state of a sixel image being decoded.
+type sixelDecoder struct {
+	palette   color.Palette
+	registers map[int]uint8 // color register to palette index
+	current   uint8         // current palette index
+	pixels    [][]uint8     // rows of palette indices
+	x, y      int
+
+	width, height             int
+	rasterWidth, rasterHeight int
+}
+
+func (d *sixelDecoder) decode(data []byte) error {
+	// Use color register 0 until another one is selected.
+	if err := d.selectColor(0); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(data); {
+		c := data[i]
+		i++
+
+		switch {
+		case c == '"':
+			var params []int
+			params, i = sixelParams(data, i)
+			if len(params) > 2 {
+				d.rasterWidth = params[2]
+			}
+			if len(params) > 3 {
+				d.rasterHeight = params[3]
+			}
+			if d.rasterWidth > SixelMaxSize || d.rasterHeight > SixelMaxSize {
+				return ErrSixelTooLarge
+			}
+		case c == '#':
+			var params []int
+			params, i = sixelParams(data, i)
+			if len(params) == 0 {
+				continue
+			}
+			if len(params) >= 5 {
+				if err := d.defineColor(params[0], params[1], params[2], params[3], params[4]); err != nil {
+					return err
+				}
+			}
+			if err := d.selectColor(params[0]); err != nil {
+				return err
+			}
+		case c == '!':
+			var params []int
+			params, i = sixelParams(data, i)
+			if i >= len(data) {
+				continue
+			}
+			n := 1
+			if len(params) > 0 && params[0] > 0 {
+				n = params[0]
+			}
+			if s := data[i]; s >= '?' && s <= '~' {
+				if err := d.paint(s, n); err != nil {
+					return err
+				}
+				i++
+			}
+		case c == '$':
+			d.x = 0
+		case c == '-':
+			d.x = 0
+			d.y += 6
+		case c >= '?' && c <= '~':
+			if err := d.paint(c, 1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// paint paints n columns of the given sixel character using the current
+// color. It returns [ErrSixelTooLarge] if the painted area grows larger than
+// [SixelMaxSize].
+func (d *sixelDecoder) paint(c byte, n int) error {
+	if n > SixelMaxSize-d.x || d.y+6 > SixelMaxSize {
+		return ErrSixelTooLarge
+	}
+
+	bits := c - '?'
+	for b := 0; b < 6; b++ {
+		if bits&(1<<b) == 0 {
+			continue
+		}
+		y := d.y + b
+		for len(d.pixels) <= y {
+			d.pixels = append(d.pixels, nil)
+		}
+		row := d.pixels[y]
+		if len(row) < d.x+n {
+			row = append(row, make([]uint8, d.x+n-len(row))...)
+		}
+		for x := d.x; x < d.x+n; x++ {
+			row[x] = d.current
+		}
+		d.pixels[y] = row
+	}
+
+	d.x += n
+	d.width = max(d.width, d.x)
+	d.height = max(d.height, d.y+6)
+	return nil
+}
+
+// register returns the palette index of the given color register, adding it
+// to the palette if necessary.
+func (d *sixelDecoder) register(reg int) (uint8, error) {
+	if idx, ok := d.registers[reg]; ok {
+		return idx, nil
+	}
+	if len(d.palette) > 255 {
+		return 0, ErrSixelPaletteOverflow
+	}
+
+	var c color.Color = color.RGBA{0, 0, 0, 255}
+	if reg >= 0 && reg < len(sixelDefaultPalette) {
+		c = sixelDefaultPalette[reg]
+	}
+
+	idx := uint8(len(d.palette)) //nolint:gosec
+	d.palette = append(d.palette, c)
+	d.registers[reg] = idx
+	return idx, nil
+}
+
+func (d *sixelDecoder) selectColor(reg int) error {
+	idx, err := d.register(reg)
+	if err != nil {
+		return err
+	}
+	d.current = idx
+	return nil
+}
+
+// defineColor defines the color of the given register using either the HLS
+// (1) or RGB (2) color coordinate system.
+func (d *sixelDecoder) defineColor(reg, system, x, y, z int) error {
+	var c color.RGBA
+	switch system {
+	case 1:
+		c = sixelHLS(x, y, z)
+	case 2:
+		c = color.RGBA{sixelPercent(x), sixelPercent(y), sixelPercent(z), 255}
+	default:
+		return fmt.Errorf("sixel: invalid color coordinate system %d", system)
+	}
+
+	idx, err := d.register(reg)
+	if err != nil {
+		return err
+	}
+	d.palette[idx] = c
+	return nil
+}
+
+// sixelMaxParam is the value sixel parameters saturate at, so they can't
+// overflow. It's larger than [SixelMaxSize] to still be rejected as a size.
+const sixelMaxParam = 1 << 24
+
+// sixelParams parses semicolon separated numeric parameters starting at i. It
+// returns the parameters and the index of the first byte after them.
+// Parameters larger than sixelMaxParam are clamped to it.
+func sixelParams(data []byte, i int) ([]int, int) {
+	var params []int
+	var param int
+	var hasParam bool
+	for ; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c >= '0' && c <= '9':
+			param = min(param*10+int(c-'0'), sixelMaxParam)
+			hasParam = true
+			continue
+		case c == ';':
+			params = append(params, param)
+			param, hasParam = 0, false
+			continue
+		}
+		break
+	}
+	if hasParam || len(params) > 0 {
+		params = append(params, param)
+	}
+	return params, i
+}
+
+// sixelPercent converts a 0-100 color component to 0-255.
+func sixelPercent(v int) uint8 {
+	v = min(max(v, 0), 100)
+	return uint8((v*255 + 50) / 100) //nolint:gosec
+}
+
+// sixelHLS converts a sixel HLS color to RGB. Sixel hues start with blue at
+// 0 degrees, red at 120 degrees, and green at 240 degrees.
+func sixelHLS(h, l, s int) color.RGBA {
+	hue := float64((h+240)%360) / 360
+	lum := float64(min(max(l, 0), 100)) / 100
+	sat := float64(min(max(s, 0), 100)) / 100
+
+	if sat == 0 {
+		v := uint8(lum*255 + 0.5)
+		return color.RGBA{v, v, v, 255}
+	}
+
+	var q float64
+	if lum < 0.5 {
+		q = lum * (1 + sat)
+	} else {
+		q = lum + sat - lum*sat
+	}
+	p := 2*lum - q
+
+	conv := func(t float64) uint8 {
+		if t < 0 {
+			t++
+		}
+		if t > 1 {
+			t--
+		}
+		var v float64
+		switch {
+		case t < 1.0/6:
+			v = p + (q-p)*6*t
+		case t < 1.0/2:
+			v = q
+		case t < 2.0/3:
+			v = p + (q-p)*(2.0/3-t)*6
+		default:
+			v = p
+		}
+		return uint8(v*255 + 0.5)
+	}
+
+	return color.RGBA{conv(hue + 1.0/3), conv(hue), conv(hue - 1.0/3), 255}
+}
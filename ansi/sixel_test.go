@@ -0,0 +1,123 @@
+package ansi
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestDecodeSixel(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+
+	// Register 0 is red and register 1 is blue. The first band paints two
+	// red columns, then returns to the start of the band and paints a blue
+	// column after repeating two empty sixels. The second band paints a
+	// single red pixel on its top row.
+	img, err := DecodeSixel([]byte("#0;2;100;0;0#1;2;0;0;100#0~~$#1!2?~-#0@"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != 3 || h != 12 {
+		t.Fatalf("expected 3x12 image, got %dx%d", w, h)
+	}
+
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 3; x++ {
+			var want color.Color = color.Transparent
+			switch {
+			case y < 6 && x < 2:
+				want = red
+			case y < 6 && x == 2:
+				want = blue
+			case y == 6 && x == 0:
+				want = red
+			}
+			if got := img.At(x, y); got != want {
+				t.Errorf("pixel (%d, %d): expected %v, got %v", x, y, want, got)
+			}
+		}
+	}
+}
+
+func TestDecodeSixelRasterAttributes(t *testing.T) {
+	img, err := DecodeSixel([]byte("\"1;1;3;2#1~"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != 3 || h != 2 {
+		t.Fatalf("expected 3x2 image, got %dx%d", w, h)
+	}
+
+	// Register 1 isn't defined, so it uses the default VT340 color.
+	if got, want := img.At(0, 1), sixelDefaultPalette[1]; got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := img.At(1, 0); got != color.Transparent {
+		t.Errorf("expected transparent pixel, got %v", got)
+	}
+}
+
+func TestDecodeSixelHLS(t *testing.T) {
+	cases := []struct {
+		hls  string
+		want color.RGBA
+	}{
+		{"0;50;100", color.RGBA{0, 0, 255, 255}},
+		{"120;50;100", color.RGBA{255, 0, 0, 255}},
+		{"240;50;100", color.RGBA{0, 255, 0, 255}},
+		{"0;100;0", color.RGBA{255, 255, 255, 255}},
+	}
+
+	for _, tc := range cases {
+		img, err := DecodeSixel([]byte("#1;1;" + tc.hls + "@"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := img.At(0, 0); got != tc.want {
+			t.Errorf("hls %s: expected %v, got %v", tc.hls, tc.want, got)
+		}
+	}
+}
+
+func TestDecodeSixelErrors(t *testing.T) {
+	if _, err := DecodeSixel([]byte("#1;3;0;0;0~")); err == nil {
+		t.Error("expected error for invalid color coordinate system")
+	}
+
+	var b strings.Builder
+	for i := 0; i < 256; i++ {
+		fmt.Fprintf(&b, "#%d~", i)
+	}
+	if _, err := DecodeSixel([]byte(b.String())); !errors.Is(err, ErrSixelPaletteOverflow) {
+		t.Errorf("expected %v, got %v", ErrSixelPaletteOverflow, err)
+	}
+
+	for _, data := range []string{
+		"\"1;1;4097;1~",
+		"\"1;1;1;4097~",
+		"\"1;1;99999999999999999999999;1~",
+		"!4097~",
+		"!99999999999999999999999~",
+		"!4000~!97~",
+		strings.Repeat("-", SixelMaxSize/6) + "~",
+	} {
+		if _, err := DecodeSixel([]byte(data)); !errors.Is(err, ErrSixelTooLarge) {
+			t.Errorf("%q: expected %v, got %v", data, ErrSixelTooLarge, err)
+		}
+	}
+
+	// The largest image is fine.
+	img, err := DecodeSixel([]byte("\"1;1;4096;4096!4096~"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds(); got != image.Rect(0, 0, SixelMaxSize, SixelMaxSize) {
+		t.Errorf("expected bounds %v, got %v", image.Rect(0, 0, SixelMaxSize, SixelMaxSize), got)
+	}
+}
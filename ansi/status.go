@@ -142,3 +142,61 @@ func ExtendedCursorPositionReport(line, column, page int) string {
 func DECXCPR(line, column, page int) string {
 	return ExtendedCursorPositionReport(line, column, page)
 }
+
+// DecodeCursorPosition decodes a cursor position report sent by the terminal
+// in response to [RequestCursorPositionReport] or
+// [RequestExtendedCursorPositionReport]. It reports false if seq isn't a
+// valid report.
+//
+//	CSI Pl ; Pc R
+//	CSI ? Pl ; Pc R
+//	CSI ? Pl ; Pc ; Pv R
+//
+// The returned row and column are 1-based, as sent by the terminal, matching
+// [CursorPositionReport]. The page number of the extended form is ignored.
+//
+// Note that a CPR is indistinguishable from a modified F3 key, CSI 1 ; 5 R
+// for example, in the legacy xterm key encoding, callers should only decode
+// it when a report is expected.
+//
+// See: https://vt100.net/docs/vt510-rm/CPR.html
+// See: https://vt100.net/docs/vt510-rm/DECXCPR.html
+func DecodeCursorPosition(seq string) (row, col int, ok bool) {
+	if !HasCsiPrefix(seq) {
+		return 0, 0, false
+	}
+
+	p := GetParser()
+	defer PutParser(p)
+
+	s, _, n, _ := DecodeSequence(seq, NormalState, p)
+	if n != len(seq) || len(s) == 0 {
+		return 0, 0, false
+	}
+
+	cmd := Cmd(p.Command())
+	if cmd.Final() != 'R' || cmd.Intermediate() != 0 {
+		return 0, 0, false
+	}
+
+	params := p.Params()
+	switch cmd.Prefix() {
+	case 0:
+		if len(params) != 2 {
+			return 0, 0, false
+		}
+	case '?':
+		if len(params) != 2 && len(params) != 3 {
+			return 0, 0, false
+		}
+	default:
+		return 0, 0, false
+	}
+
+	row, col = params[0].Param(0), params[1].Param(0)
+	if row < 1 || col < 1 {
+		return 0, 0, false
+	}
+
+	return row, col, true
+}
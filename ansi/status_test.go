@@ -0,0 +1,40 @@
+package ansi
+
+import "testing"
+
+func TestDecodeCursorPosition(t *testing.T) {
+	cases := []struct {
+		name     string
+		seq      string
+		row, col int
+		ok       bool
+	}{
+		{"cpr", "\x1b[12;34R", 12, 34, true},
+		{"cpr origin", "\x1b[1;1R", 1, 1, true},
+		{"cpr round trip", CursorPositionReport(5, 80), 5, 80, true},
+		{"decxcpr", "\x1b[?12;34R", 12, 34, true},
+		{"decxcpr with page", "\x1b[?12;34;2R", 12, 34, true},
+		{"decxcpr round trip", ExtendedCursorPositionReport(3, 7, 1), 3, 7, true},
+		{"c1 csi", "\x9b2;3R", 2, 3, true},
+		{"missing column", "\x1b[12R", 0, 0, false},
+		{"missing row", "\x1b[;34R", 0, 0, false},
+		{"zero row", "\x1b[0;34R", 0, 0, false},
+		{"page without prefix", "\x1b[12;34;2R", 0, 0, false},
+		{"too many params", "\x1b[?1;2;3;4R", 0, 0, false},
+		{"wrong final", "\x1b[12;34H", 0, 0, false},
+		{"wrong prefix", "\x1b[>12;34R", 0, 0, false},
+		{"intermediate", "\x1b[12;34$R", 0, 0, false},
+		{"unterminated", "\x1b[12;34", 0, 0, false},
+		{"trailing data", "\x1b[12;34Rx", 0, 0, false},
+		{"not a sequence", "12;34R", 0, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			row, col, ok := DecodeCursorPosition(tc.seq)
+			if row != tc.row || col != tc.col || ok != tc.ok {
+				t.Errorf("DecodeCursorPosition(%q) = %d, %d, %v; want %d, %d, %v",
+					tc.seq, row, col, ok, tc.row, tc.col, tc.ok)
+			}
+		})
+	}
+}
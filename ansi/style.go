@@ -127,6 +127,11 @@ func (s Style) Strikethrough() Style {
 	return append(s, strikethroughAttr)
 }
 
+// Overline appends the overline style attribute to the style.
+func (s Style) Overline() Style {
+	return append(s, overlineAttr)
+}
+
 // NormalIntensity appends the normal intensity style attribute to the style.
 func (s Style) NormalIntensity() Style {
 	return append(s, normalIntensityAttr)
@@ -162,6 +167,11 @@ func (s Style) NoStrikethrough() Style {
 	return append(s, noStrikethroughAttr)
 }
 
+// NoOverline appends the no overline style attribute to the style.
+func (s Style) NoOverline() Style {
+	return append(s, noOverlineAttr)
+}
+
 // DefaultForegroundColor appends the default foreground color style attribute to the style.
 func (s Style) DefaultForegroundColor() Style {
 	return append(s, defaultForegroundColorAttr)
@@ -258,6 +268,8 @@ const (
 	WhiteBackgroundColorAttr         Attr = 47
 	ExtendedBackgroundColorAttr      Attr = 48
 	DefaultBackgroundColorAttr       Attr = 49
+	OverlineAttr                     Attr = 53
+	NoOverlineAttr                   Attr = 55
 	ExtendedUnderlineColorAttr       Attr = 58
 	DefaultUnderlineColorAttr        Attr = 59
 	BrightBlackForegroundColorAttr   Attr = 90
@@ -319,6 +331,8 @@ const (
 	whiteBackgroundColorAttr         = "47"
 	extendedBackgroundColorAttr      = "48"
 	defaultBackgroundColorAttr       = "49"
+	overlineAttr                     = "53"
+	noOverlineAttr                   = "55"
 	extendedUnderlineColorAttr       = "58"
 	defaultUnderlineColorAttr        = "59"
 	brightBlackForegroundColorAttr   = "90"
@@ -46,6 +46,33 @@ func TestColorColor(t *testing.T) {
 	}
 }
 
+func TestStyleSequences(t *testing.T) {
+	cases := []struct {
+		name  string
+		style ansi.Style
+		want  string
+	}{
+		{"true color foreground and bold", ansi.Style{}.Bold().ForegroundColor(ansi.TrueColor(0xff8000)), "\x1b[1;38;2;255;128;0m"},
+		{"bold and true color foreground", ansi.Style{}.ForegroundColor(color.RGBA{1, 2, 3, 255}).Bold(), "\x1b[38;2;1;2;3;1m"},
+		{"basic colors", ansi.Style{}.ForegroundColor(ansi.Red).BackgroundColor(ansi.Blue), "\x1b[31;44m"},
+		{"bright basic colors", ansi.Style{}.ForegroundColor(ansi.BrightRed).BackgroundColor(ansi.BrightWhite), "\x1b[91;107m"},
+		{"256 colors", ansi.Style{}.ForegroundColor(ansi.ExtendedColor(208)).BackgroundColor(ansi.ExtendedColor(16)), "\x1b[38;5;208;48;5;16m"},
+		{"true color background", ansi.Style{}.BackgroundColor(ansi.TrueColor(0x102030)), "\x1b[48;2;16;32;48m"},
+		{"underline color", ansi.Style{}.Underline().UnderlineColor(ansi.ExtendedColor(1)), "\x1b[4;58;5;1m"},
+		{"reset then bold", ansi.Style{}.Reset().Bold(), "\x1b[0;1m"},
+		{"default colors", ansi.Style{}.DefaultForegroundColor().DefaultBackgroundColor(), "\x1b[39;49m"},
+		{"overline", ansi.Style{}.Overline().Strikethrough(), "\x1b[53;9m"},
+		{"no overline", ansi.Style{}.NoOverline(), "\x1b[55m"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.style.String(); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
 func BenchmarkStyle(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
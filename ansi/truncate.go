@@ -45,6 +45,40 @@ func cut(m Method, s string, left, right int) string {
 	return truncateLeft(truncate(s, right, ""), left, "")
 }
 
+// SplitAt splits the string at the given column into a left part holding
+// the first col cells and a right part holding the rest. A wide character
+// crossing the column goes whole to the right part. This function is aware of
+// ANSI escape codes and will not break them, and accounts for wide-characters
+// (such as East-Asian characters and emojis). Escape codes found in the left
+// part are also kept at the start of the right part so that it renders with
+// the style and hyperlink active at the split.
+// This treats the text as a sequence of graphemes.
+func SplitAt(s string, col int) (left, right string) {
+	return splitAt(GraphemeWidth, s, col)
+}
+
+// SplitAtWc splits the string at the given column into a left part holding
+// the first col cells and a right part holding the rest. A wide character
+// crossing the column goes whole to the right part. This function is aware of
+// ANSI escape codes and will not break them, and accounts for wide-characters
+// (such as East-Asian characters and emojis). Escape codes found in the left
+// part are also kept at the start of the right part so that it renders with
+// the style and hyperlink active at the split.
+// This treats the text as a sequence of wide characters and runes.
+func SplitAtWc(s string, col int) (left, right string) {
+	return splitAt(WcWidth, s, col)
+}
+
+func splitAt(m Method, s string, col int) (left, right string) {
+	if col <= 0 {
+		return "", s
+	}
+	if col >= m.StringWidth(s) {
+		return s, ""
+	}
+	return truncate(m, s, col, ""), truncateLeft(m, s, col, "")
+}
+
 // Truncate truncates a string to a given length, adding a tail to the end if
 // the string is longer than the given length. This function is aware of ANSI
 // escape codes and will not break them, and accounts for wide-characters (such
@@ -63,6 +97,32 @@ func TruncateWc(s string, length int, tail string) string {
 	return truncate(WcWidth, s, length, tail)
 }
 
+// TruncateStyled is like [Truncate], but lets the tail either inherit the
+// style active at the cut or be rendered unstyled. When styledTail is false,
+// the tail is written after a [ResetStyle]. Escape codes following the cut
+// are still kept after the tail.
+// This treats the text as a sequence of graphemes.
+func TruncateStyled(s string, length int, tail string, styledTail bool) string {
+	return truncateStyled(GraphemeWidth, s, length, tail, styledTail)
+}
+
+// TruncateStyledWc is like [TruncateWc], but lets the tail either inherit the
+// style active at the cut or be rendered unstyled. When styledTail is false,
+// the tail is written after a [ResetStyle]. Escape codes following the cut
+// are still kept after the tail.
+// This treats the text as a sequence of wide characters and runes.
+func TruncateStyledWc(s string, length int, tail string, styledTail bool) string {
+	return truncateStyled(WcWidth, s, length, tail, styledTail)
+}
+
+func truncateStyled(m Method, s string, length int, tail string, styledTail bool) string {
+	if !styledTail && tail != "" {
+		// The reset has no width, the tail is measured the same.
+		tail = ResetStyle + tail
+	}
+	return truncate(m, s, length, tail)
+}
+
 func truncate(m Method, s string, length int, tail string) string {
 	if sw := StringWidth(s); sw <= length {
 		return s
@@ -174,6 +234,12 @@ func truncate(m Method, s string, length int, tail string) string {
 // adding a prefix to the beginning if the string is longer than n.
 // This function is aware of ANSI escape codes and will not break them, and
 // accounts for wide-characters (such as East-Asian characters and emojis).
+// Escape codes found in the removed part are kept so that the style active at
+// the new left edge is preserved.
+//
+// To keep the rightmost columns of a string within a given width, remove
+// StringWidth(s) - width + StringWidth(prefix) characters.
+//
 // This treats the text as a sequence of graphemes.
 func TruncateLeft(s string, n int, prefix string) string {
 	return truncateLeft(GraphemeWidth, s, n, prefix)
@@ -273,6 +339,60 @@ func truncateLeft(m Method, s string, n int, prefix string) string {
 	return buf.String()
 }
 
+// TruncateMiddle truncates a string to a given length by removing characters
+// from the middle of the string and joining the remaining left and right
+// parts with sep. The left part gets the extra cell when the available width
+// is odd. If the string already fits within the given length, it is returned
+// unchanged. This function is aware of ANSI escape codes and will not break
+// them, and accounts for wide-characters (such as East-Asian characters and
+// emojis). Escape codes found in the removed part are kept so that the style
+// active at the start of the right part is preserved.
+// This treats the text as a sequence of graphemes.
+func TruncateMiddle(s string, length int, sep string) string {
+	return truncateMiddle(GraphemeWidth, s, length, sep)
+}
+
+// TruncateMiddleWc truncates a string to a given length by removing
+// characters from the middle of the string and joining the remaining left and
+// right parts with sep. The left part gets the extra cell when the available
+// width is odd. If the string already fits within the given length, it is
+// returned unchanged. This function is aware of ANSI escape codes and will not
+// break them, and accounts for wide-characters (such as East-Asian characters
+// and emojis). Escape codes found in the removed part are kept so that the
+// style active at the start of the right part is preserved.
+// This treats the text as a sequence of wide characters and runes.
+func TruncateMiddleWc(s string, length int, sep string) string {
+	return truncateMiddle(WcWidth, s, length, sep)
+}
+
+func truncateMiddle(m Method, s string, length int, sep string) string {
+	sw := m.StringWidth(s)
+	if sw <= length {
+		return s
+	}
+
+	length -= m.StringWidth(sep)
+	if length < 0 {
+		return ""
+	}
+
+	// The left part might be narrower than requested when a wide character
+	// lands on the cut. Give the leftover cells to the right part.
+	left := truncate(m, s, (length+1)/2, "")
+	rightWidth := length - m.StringWidth(left)
+
+	// Likewise, a wide character at the left edge of the right part is kept
+	// whole by truncateLeft, so we might need to drop one more cell.
+	n := sw - rightWidth
+	right := truncateLeft(m, s, n, "")
+	for n < sw && m.StringWidth(right) > rightWidth {
+		n++
+		right = truncateLeft(m, s, n, "")
+	}
+
+	return left + sep + right
+}
+
 // ByteToGraphemeRange takes start and stop byte positions and converts them to
 // grapheme-aware char positions.
 // You can use this with [Truncate], [TruncateLeft], and [Cut].
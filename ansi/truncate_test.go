@@ -345,6 +345,35 @@ func BenchmarkTruncateString(b *testing.B) {
 	})
 }
 
+func TestTruncateStyled(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		length int
+		tail   string
+		styled bool
+		expect string
+	}{
+		{"styled tail", "\x1b[31mhello world\x1b[m", 6, "…", true, "\x1b[31mhello…\x1b[m"},
+		{"unstyled tail", "\x1b[31mhello world\x1b[m", 6, "…", false, "\x1b[31mhello\x1b[m…\x1b[m"},
+		{"no truncation", "\x1b[31mhello\x1b[m", 6, "…", false, "\x1b[31mhello\x1b[m"},
+		{"empty tail", "\x1b[31mhello world\x1b[m", 5, "", false, "\x1b[31mhello\x1b[m"},
+		{"wide tail", "\x1b[1mabcdef", 4, "你", false, "\x1b[1mab\x1b[m你"},
+	}
+
+	for i, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := TruncateStyled(c.input, c.length, c.tail, c.styled); got != c.expect {
+				t.Errorf("test case %d failed: expected %q, got %q", i+1, c.expect, got)
+			}
+		})
+	}
+
+	if got, want := TruncateStyledWc("\x1b[31m你好世界", 5, "…", false), "\x1b[31m你好\x1b[m…"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
 func TestTruncateLeft(t *testing.T) {
 	for i, c := range tcases {
 		t.Run(c.name, func(t *testing.T) {
@@ -355,6 +384,109 @@ func TestTruncateLeft(t *testing.T) {
 	}
 }
 
+func TestTruncateLeftStyledPath(t *testing.T) {
+	for i, c := range []struct {
+		name   string
+		input  string
+		width  int
+		prefix string
+		expect string
+	}{
+		{
+			"style carried from removed part",
+			"\x1b[34m/home/user/\x1b[1mproject\x1b[0m/file.go",
+			12,
+			"…",
+			"\x1b[34m\x1b[1m…ect\x1b[0m/file.go",
+		},
+		{
+			"style changes inside removed part",
+			"\x1b[2m/usr/local/\x1b[22;32mbin/\x1b[0mgo",
+			7,
+			"…",
+			"\x1b[2m\x1b[22;32m…bin/\x1b[0mgo",
+		},
+		{
+			"hyperlink carried from removed part",
+			"\x1b]8;;file:///tmp\x1b\\/tmp/some/dir\x1b]8;;\x1b\\",
+			6,
+			"…",
+			"\x1b]8;;file:///tmp\x1b\\…e/dir\x1b]8;;\x1b\\",
+		},
+		{
+			"fits",
+			"\x1b[34m/tmp\x1b[0m",
+			4,
+			"…",
+			"\x1b[34m/tmp\x1b[0m",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			n := StringWidth(c.input) - c.width + StringWidth(c.prefix)
+			if StringWidth(c.input) <= c.width {
+				n = 0
+			}
+			result := TruncateLeft(c.input, n, c.prefix)
+			if result != c.expect {
+				t.Errorf("test case %d failed:\nexpected: %q\n     got: %q", i+1, c.expect, result)
+			}
+			if w := StringWidth(result); w > c.width {
+				t.Errorf("test case %d failed: expected width <= %d, got %d", i+1, c.width, w)
+			}
+		})
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	for i, c := range []struct {
+		name   string
+		input  string
+		width  int
+		sep    string
+		expect string
+	}{
+		{"empty", "", 5, "…", ""},
+		{"fits", "abcdef", 6, "…", "abcdef"},
+		{"fits styled", "\x1b[31mabc\x1b[0m", 3, "…", "\x1b[31mabc\x1b[0m"},
+		{"simple", "abcdefghijklmnopqrstuvwxyz", 13, "…", "abcdef…uvwxyz"},
+		{"odd width", "abcdefghij", 6, "…", "abc…ij"},
+		{"sep too wide", "abcdefghij", 2, "...", ""},
+		{"only sep", "abcdefghij", 3, "...", "..."},
+		{"empty sep", "abcdefghij", 4, "", "abij"},
+		{
+			"style crossing left cut",
+			"ab\x1b[31mcdefgh\x1b[0mij",
+			5, "…",
+			"ab\x1b[31m\x1b[0m…\x1b[31m\x1b[0mij",
+		},
+		{
+			"style crossing both cuts",
+			"a\x1b[1;32mbcdefghi\x1b[0mj",
+			5, "…",
+			"a\x1b[1;32mb\x1b[0m…\x1b[1;32mi\x1b[0mj",
+		},
+		{
+			"hyperlink crossing cut",
+			"\x1b]8;;https://example.com\x1b\\abcdefgh\x1b]8;;\x1b\\",
+			5, "…",
+			"\x1b]8;;https://example.com\x1b\\ab\x1b]8;;\x1b\\…\x1b]8;;https://example.com\x1b\\gh\x1b]8;;\x1b\\",
+		},
+		{"wide characters", "耐許ヱヨカハ調出", 9, "…", "耐許…調出"},
+		{"wide character on the cut", "耐許ヱヨカハ調出", 8, "…", "耐許…出"},
+		{"grapheme cluster", "👋🏽abcdefgh👋🏽", 7, "…", "👋🏽a…h👋🏽"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			result := TruncateMiddle(c.input, c.width, c.sep)
+			if result != c.expect {
+				t.Errorf("test case %d failed:\nexpected: %q\n     got: %q", i+1, c.expect, result)
+			}
+			if w := StringWidth(result); w > c.width {
+				t.Errorf("test case %d failed: expected width <= %d, got %d", i+1, c.width, w)
+			}
+		})
+	}
+}
+
 func TestCut(t *testing.T) {
 	for i, c := range []struct {
 		desc   string
@@ -458,3 +590,56 @@ func TestByteToGraphemeRange(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitAt(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		col   int
+		left  string
+		right string
+	}{
+		{"empty", "", 3, "", ""},
+		{"zero", "hello", 0, "", "hello"},
+		{"past end", "hello", 10, "hello", ""},
+		{"simple", "hello world", 5, "hello", " world"},
+		{"wide char at column", "a你b", 1, "a", "你b"},
+		{"inside wide char", "a你b", 2, "a", "你b"},
+		{"after wide char", "a你b", 3, "a你", "b"},
+		{"emoji cluster", "👨‍👩‍👧x", 1, "", "👨‍👩‍👧x"},
+		{
+			"inside sgr span",
+			"\x1b[31mhello\x1b[m world", 3,
+			"\x1b[31mhel\x1b[m", "\x1b[31mlo\x1b[m world",
+		},
+		{
+			"after sgr span",
+			"\x1b[1mab\x1b[m\x1b[32mcd\x1b[m", 2,
+			"\x1b[1mab\x1b[m\x1b[32m\x1b[m", "\x1b[1m\x1b[m\x1b[32mcd\x1b[m",
+		},
+		{
+			"inside hyperlink",
+			"\x1b]8;;https://charm.sh\x07charm\x1b]8;;\x07!", 2,
+			"\x1b]8;;https://charm.sh\x07ch\x1b]8;;\x07", "\x1b]8;;https://charm.sh\x07arm\x1b]8;;\x07!",
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			left, right := SplitAt(c.input, c.col)
+			if left != c.left || right != c.right {
+				t.Errorf("test case %d failed: expected %q, %q, got %q, %q", i+1, c.left, c.right, left, right)
+			}
+			if Strip(left)+Strip(right) != Strip(c.input) {
+				t.Errorf("test case %d failed: parts don't add up to the input text", i+1)
+			}
+		})
+	}
+}
+
+func TestSplitAtWc(t *testing.T) {
+	left, right := SplitAtWc("\x1b[31m你好\x1b[m", 3)
+	if left != "\x1b[31m你\x1b[m" || right != "\x1b[31m好\x1b[m" {
+		t.Errorf("expected %q, %q, got %q, %q", "\x1b[31m你\x1b[m", "\x1b[31m好\x1b[m", left, right)
+	}
+}
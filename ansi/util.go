@@ -104,3 +104,41 @@ func max[T ordered](a, b T) T { //nolint:predeclared
 	}
 	return b
 }
+
+func min[T ordered](a, b T) T { //nolint:predeclared
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// oscPayload returns the payload of an OSC sequence with the given command
+// number, without the command and the string terminator. It reports false if
+// seq isn't a complete OSC sequence terminated by BEL or ST with that command.
+func oscPayload(seq string, cmd string) (string, bool) {
+	switch {
+	case strings.HasPrefix(seq, "\x1b]"):
+		seq = seq[2:]
+	case strings.HasPrefix(seq, "\x9d"):
+		seq = seq[1:]
+	default:
+		return "", false
+	}
+
+	switch {
+	case strings.HasSuffix(seq, "\x07"):
+		seq = seq[:len(seq)-1]
+	case strings.HasSuffix(seq, "\x1b\\"):
+		seq = seq[:len(seq)-2]
+	case strings.HasSuffix(seq, "\x9c"):
+		seq = seq[:len(seq)-1]
+	default:
+		return "", false
+	}
+
+	if !strings.HasPrefix(seq, cmd+";") {
+		return "", false
+	}
+
+	return seq[len(cmd)+1:], true
+}
@@ -2,6 +2,9 @@ package ansi
 
 import (
 	"bytes"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/charmbracelet/x/ansi/parser"
 	"github.com/mattn/go-runewidth"
@@ -59,25 +62,83 @@ func Strip(s string) string {
 	return buf.String()
 }
 
+// StripSGR removes the SGR sequences, setting the colors and attributes of
+// the text, from a string. Other escape codes are kept.
+func StripSGR(s string) string {
+	return stripSequences(s, func(seq string, p *Parser) bool {
+		if !HasCsiPrefix(seq) {
+			return false
+		}
+		cmd := Cmd(p.Command())
+		return cmd.Final() == 'm' && cmd.Prefix() == 0 && cmd.Intermediate() == 0
+	})
+}
+
+// StripLinks removes the OSC 8 sequences, opening and closing hyperlinks,
+// from a string. The text of the links and other escape codes are kept.
+func StripLinks(s string) string {
+	return stripSequences(s, func(seq string, p *Parser) bool {
+		return HasOscPrefix(seq) && p.Command() == 8
+	})
+}
+
+// stripSequences removes the sequences from s for which drop returns true.
+// The parser holds the decoded sequence.
+func stripSequences(s string, drop func(seq string, p *Parser) bool) string {
+	p := GetParser()
+	defer PutParser(p)
+
+	var (
+		buf   strings.Builder
+		state byte
+	)
+	for len(s) > 0 {
+		seq, _, n, newState := DecodeSequence(s, state, p)
+		if !drop(seq, p) {
+			buf.WriteString(seq)
+		}
+		state = newState
+		s = s[n:]
+	}
+
+	return buf.String()
+}
+
 // StringWidth returns the width of a string in cells. This is the number of
 // cells that the string will occupy when printed in a terminal. ANSI escape
 // codes are ignored and wide characters (such as East Asians and emojis) are
-// accounted for.
+// accounted for. Like in a terminal, a carriage return moves back to the
+// first column, and the width is the furthest column reached, so
+// "hello\rworld" is 5 cells wide. Other control characters, including line
+// feeds, have no width.
 // This treats the text as a sequence of grapheme clusters.
 func StringWidth(s string) int {
-	return stringWidth(GraphemeWidth, s)
+	return stringWidth(GraphemeWidth, s, false)
+}
+
+// OverstrikeWidth is like [StringWidth], except that a backspace moves back
+// one column, as in a terminal. This measures overstruck text, such as the
+// bold "a\ba" and underlined "_\ba" of man pages, as a single cell per
+// overstruck character.
+func OverstrikeWidth(s string) int {
+	return stringWidth(GraphemeWidth, s, true)
 }
 
 // StringWidthWc returns the width of a string in cells. This is the number of
 // cells that the string will occupy when printed in a terminal. ANSI escape
 // codes are ignored and wide characters (such as East Asians and emojis) are
-// accounted for.
+// accounted for. Like in a terminal, a carriage return moves back to the
+// first column, and the width is the furthest column reached, so
+// "hello\rworld" is 5 cells wide. Other control characters, including line
+// feeds, have no width.
 // This treats the text as a sequence of wide characters and runes.
 func StringWidthWc(s string) int {
-	return stringWidth(WcWidth, s)
+	return stringWidth(WcWidth, s, false)
 }
 
-func stringWidth(m Method, s string) int {
+// stringWidth returns the width of s using the given method. Backspaces move
+// back one column if overstrike is true and have no width otherwise.
+func stringWidth(m Method, s string, overstrike bool) int {
 	if s == "" {
 		return 0
 	}
@@ -86,6 +147,7 @@ func stringWidth(m Method, s string) int {
 		pstate  = parser.GroundState // initial state
 		cluster string
 		width   int
+		col     int // the current column, reset by carriage returns
 	)
 
 	for i := 0; i < len(s); i++ {
@@ -96,18 +158,131 @@ func stringWidth(m Method, s string) int {
 			if m == WcWidth {
 				w = runewidth.StringWidth(cluster)
 			}
-			width += w
+			col += w
 			i += len(cluster) - 1
 			pstate = parser.GroundState
 			continue
 		}
 
-		if action == parser.PrintAction {
-			width++
+		switch {
+		case action == parser.PrintAction:
+			col++
+		case action == parser.ExecuteAction && s[i] == CR:
+			width = max(width, col)
+			col = 0
+		case action == parser.ExecuteAction && s[i] == BS && overstrike:
+			width = max(width, col)
+			col = max(col-1, 0)
 		}
 
 		pstate = state
 	}
 
-	return width
+	return max(width, col)
+}
+
+// WidthCache memoizes the width of strings, for callers measuring the same
+// strings over and over, such as the cells of a screen redrawn every frame.
+// It holds up to a maximum number of entries, evicting the entries that
+// weren't used recently first. Printable ASCII strings are cheaper to measure
+// than to look up and aren't cached. A WidthCache is safe for concurrent use.
+// Use [NewWidthCache] to create one.
+type WidthCache struct {
+	mu      sync.RWMutex
+	max     int
+	entries [2]map[string]*widthEntry // by [Method]
+	// ring holds the entries in insertion order, hand is the next entry to
+	// consider for eviction. Entries used since the hand last passed them
+	// get a second chance.
+	ring []*widthEntry
+	hand int
+}
+
+type widthEntry struct {
+	m     Method
+	s     string
+	width int
+	used  uint32 // accessed atomically
+}
+
+// NewWidthCache returns a [WidthCache] holding up to maxEntries widths. A
+// non-positive maxEntries disables the cache.
+func NewWidthCache(maxEntries int) *WidthCache {
+	return &WidthCache{
+		max:     maxEntries,
+		entries: [2]map[string]*widthEntry{{}, {}},
+	}
+}
+
+// StringWidth is like [StringWidth], with the result cached.
+// This treats the text as a sequence of grapheme clusters.
+func (c *WidthCache) StringWidth(s string) int {
+	return c.stringWidth(GraphemeWidth, s)
+}
+
+// StringWidthWc is like [StringWidthWc], with the result cached.
+// This treats the text as a sequence of wide characters and runes.
+func (c *WidthCache) StringWidthWc(s string) int {
+	return c.stringWidth(WcWidth, s)
+}
+
+// Len returns the number of cached widths.
+func (c *WidthCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.ring)
+}
+
+func (c *WidthCache) stringWidth(m Method, s string) int {
+	if c.max <= 0 || isPrintableASCII(s) {
+		return stringWidth(m, s, false)
+	}
+
+	c.mu.RLock()
+	e, ok := c.entries[m][s]
+	c.mu.RUnlock()
+	if ok {
+		if atomic.LoadUint32(&e.used) == 0 {
+			atomic.StoreUint32(&e.used, 1)
+		}
+		return e.width
+	}
+
+	// Measure without holding the lock, concurrent misses on the same string
+	// compute the same width.
+	w := stringWidth(m, s, false)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[m][s]; ok {
+		return w
+	}
+	e = &widthEntry{m: m, s: s, width: w}
+	c.entries[m][s] = e
+	if len(c.ring) < c.max {
+		c.ring = append(c.ring, e)
+		return w
+	}
+	for {
+		old := c.ring[c.hand]
+		if atomic.LoadUint32(&old.used) == 0 {
+			delete(c.entries[old.m], old.s)
+			c.ring[c.hand] = e
+			c.hand = (c.hand + 1) % len(c.ring)
+			return w
+		}
+		atomic.StoreUint32(&old.used, 0)
+		c.hand = (c.hand + 1) % len(c.ring)
+	}
+}
+
+// isPrintableASCII reports whether s only holds printable ASCII characters,
+// each one cell wide.
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
 }
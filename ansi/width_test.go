@@ -1,6 +1,8 @@
 package ansi
 
 import (
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -24,9 +26,19 @@ var cases = []struct {
 	{"oscwideemoji", "\x1b[31m👨‍👩‍👦\x1b[m", "👨\u200d👩\u200d👦", 2, 2},
 	{"multiemojicsi", "👨‍👩‍👦\x9b38;5;1mhello\x9bm", "👨‍👩‍👦hello", 7, 7},
 	{"osc8eastasianlink", "\x9d8;id=1;https://example.com/\x9c打豆豆\x9d8;id=1;\x07", "打豆豆", 6, 6},
+	{"apckitty", "a\x1b_Gf=100;AAAA\x1b\\b", "ab", 2, 2},
+	{"apc8", "a\x9fGf=100;AAAA\x9cb", "ab", 2, 2},
 	{"dcsarabic", "\x1bP?123$pسلام\x1b\\اهلا", "اهلا", 4, 4},
 	{"newline", "hello\nworld", "hello\nworld", 10, 10},
 	{"tab", "hello\tworld", "hello\tworld", 10, 10},
+	{"carriage_return", "hello\rworld", "hello\rworld", 5, 5},
+	{"carriage_return_longer", "ab\rwxyz", "ab\rwxyz", 4, 4},
+	{"carriage_return_newline", "abc\r\n", "abc\r\n", 3, 3},
+	{"carriage_return_lines", "hello\r\nhi\r\n", "hello\r\nhi\r\n", 5, 5},
+	{"vertical_tab", "hello\vworld", "hello\vworld", 10, 10},
+	{"form_feed", "hello\fworld", "hello\fworld", 10, 10},
+	{"vertical_tab_carriage_return", "hello\v\rhi", "hello\v\rhi", 5, 5},
+	{"styled_carriage_return", "\x1b[31m你好\r\x1b[mab", "你好\rab", 4, 4},
 	{"controlnewline", "\x1b[31mhello\x1b[0m\nworld", "hello\nworld", 10, 10},
 	{"style", "\x1B[38;2;249;38;114mfoo", "foo", 3, 3},
 	{"unicode", "\x1b[35m“box”\x1b[0m", "“box”", 5, 5},
@@ -46,6 +58,33 @@ func TestStrip(t *testing.T) {
 	}
 }
 
+func TestStripSGRAndLinks(t *testing.T) {
+	const input = "\x1b]2;title\x07\x1b[1;31mred\x1b[m " +
+		"\x1b]8;id=1;https://example.com\x1b\\link\x1b]8;;\x1b\\ \x1b[?25l你好\x9b4mu\x9bm"
+	cases := []struct {
+		name     string
+		strip    func(string) string
+		expected string
+	}{
+		{"sgr", StripSGR, "\x1b]2;title\x07red \x1b]8;id=1;https://example.com\x1b\\link\x1b]8;;\x1b\\ \x1b[?25l你好u"},
+		{"links", StripLinks, "\x1b]2;title\x07\x1b[1;31mred\x1b[m link \x1b[?25l你好\x9b4mu\x9bm"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.strip(input)
+			if got != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, got)
+			}
+			if Strip(got) != Strip(input) {
+				t.Errorf("expected the printable text %q, got %q", Strip(input), Strip(got))
+			}
+			if StringWidth(got) != StringWidth(input) {
+				t.Errorf("expected width %d, got %d", StringWidth(input), StringWidth(got))
+			}
+		})
+	}
+}
+
 func TestStringWidth(t *testing.T) {
 	for i, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -66,6 +105,36 @@ func TestWcStringWidth(t *testing.T) {
 	}
 }
 
+func TestOverstrikeWidth(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		width int
+	}{
+		{"bold", "a\x08a", 1},
+		{"underline", "_\x08x", 1},
+		{"bold word", "h\x08he\x08el\x08ll\x08lo\x08o", 5},
+		{"mixed", "N\x08NAME\x08E and _\x08x", 10},
+		{"wide", "你\x08\x08你", 2},
+		{"leading backspace", "\x08ab", 2},
+		{"backspace past start", "a\x08\x08\x08b", 1},
+		{"styled", "\x1b[1ma\x08a\x1b[m", 1},
+		{"no backspace", "hello", 5},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if width := OverstrikeWidth(c.input); width != c.width {
+				t.Errorf("expected %d, got %d", c.width, width)
+			}
+		})
+	}
+
+	// StringWidth ignores backspaces.
+	if width := StringWidth("a\x08a"); width != 2 {
+		t.Errorf("expected StringWidth to be 2, got %d", width)
+	}
+}
+
 func BenchmarkStringWidth(b *testing.B) {
 	b.RunParallel(func(pb *testing.PB) {
 		b.ReportAllocs()
@@ -75,3 +144,101 @@ func BenchmarkStringWidth(b *testing.B) {
 		}
 	})
 }
+
+func TestWidthCache(t *testing.T) {
+	cache := NewWidthCache(len(cases) * 2)
+	for i := 0; i < 2; i++ { // miss then hit
+		for _, c := range cases {
+			if width := cache.StringWidth(c.input); width != c.width {
+				t.Errorf("%s: expected width %d, got %d", c.name, c.width, width)
+			}
+			if width := cache.StringWidthWc(c.input); width != c.wcwidth {
+				t.Errorf("%s: expected wc width %d, got %d", c.name, c.wcwidth, width)
+			}
+		}
+	}
+}
+
+func TestWidthCacheEviction(t *testing.T) {
+	cache := NewWidthCache(2)
+	cache.StringWidth("你")
+	cache.StringWidth("好")
+	cache.StringWidth("你") // 你 was used since it was added
+	cache.StringWidth("世")
+	if n := cache.Len(); n != 2 {
+		t.Fatalf("expected 2 entries, got %d", n)
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for _, s := range []string{"你", "世"} {
+		if _, ok := cache.entries[GraphemeWidth][s]; !ok {
+			t.Errorf("expected %q to be cached", s)
+		}
+	}
+	if _, ok := cache.entries[GraphemeWidth]["好"]; ok {
+		t.Error("expected the unused entry to be evicted")
+	}
+}
+
+func TestWidthCacheDisabled(t *testing.T) {
+	cache := NewWidthCache(0)
+	if width := cache.StringWidth("你好"); width != 4 {
+		t.Errorf("expected width 4, got %d", width)
+	}
+	if n := cache.Len(); n != 0 {
+		t.Errorf("expected no entries, got %d", n)
+	}
+
+	// Printable ASCII isn't cached.
+	cache = NewWidthCache(10)
+	if width := cache.StringWidth("hello"); width != 5 {
+		t.Errorf("expected width 5, got %d", width)
+	}
+	if n := cache.Len(); n != 0 {
+		t.Errorf("expected no entries, got %d", n)
+	}
+}
+
+func TestWidthCacheConcurrent(t *testing.T) {
+	cache := NewWidthCache(8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s := strings.Repeat("你", (i+j)%16)
+				if width := cache.StringWidth(s); width != len([]rune(s))*2 {
+					t.Errorf("expected width %d for %q, got %d", len([]rune(s))*2, s, width)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := cache.Len(); n > 8 {
+		t.Errorf("expected at most 8 entries, got %d", n)
+	}
+}
+
+// cellContents is a realistic set of short strings measured by a renderer.
+var cellContents = []string{
+	"a", "b", "c", " ", "你", "好", "👋", "👨‍👩‍👦", "é", "─", "│", "┼",
+	"\x1b[31mfoo\x1b[m", "\x1b[1;4mbar\x1b[m", "hello", "world",
+}
+
+func BenchmarkStringWidthRepeated(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		StringWidth(cellContents[i%len(cellContents)])
+	}
+}
+
+func BenchmarkWidthCacheRepeated(b *testing.B) {
+	cache := NewWidthCache(1024)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.StringWidth(cellContents[i%len(cellContents)])
+	}
+}
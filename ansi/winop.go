@@ -19,6 +19,18 @@ const (
 	// the size of the terminal cell size in pixels. The response is in the form:
 	//  CSI 6 ; height ; width t
 	RequestCellSizeWinOp = 16
+
+	// PushTitleWinOp is a window operation that saves the icon name and/or
+	// window title on a stack. The second parameter selects what to save: 0
+	// for both, 1 for the icon name, and 2 for the window title.
+	//  CSI 22 ; Ps t
+	PushTitleWinOp = 22
+
+	// PopTitleWinOp is a window operation that restores the icon name and/or
+	// window title from the stack. The second parameter selects what to
+	// restore: 0 for both, 1 for the icon name, and 2 for the window title.
+	//  CSI 23 ; Ps t
+	PopTitleWinOp = 23
 )
 
 // WindowOp (XTWINOPS) is a sequence that manipulates the terminal window.
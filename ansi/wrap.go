@@ -2,6 +2,7 @@ package ansi
 
 import (
 	"bytes"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 
@@ -20,7 +21,7 @@ const nbsp = 0xA0
 // preserved.
 // This treats the text as a sequence of graphemes.
 func Hardwrap(s string, limit int, preserveSpace bool) string {
-	return hardwrap(GraphemeWidth, s, limit, preserveSpace)
+	return hardwrap(GraphemeWidth, s, limit, preserveSpace, nil)
 }
 
 // HardwrapWc wraps a string or a block of text to a given line length, breaking
@@ -30,10 +31,84 @@ func Hardwrap(s string, limit int, preserveSpace bool) string {
 // preserved.
 // This treats the text as a sequence of wide characters and runes.
 func HardwrapWc(s string, limit int, preserveSpace bool) string {
-	return hardwrap(WcWidth, s, limit, preserveSpace)
+	return hardwrap(WcWidth, s, limit, preserveSpace, nil)
 }
 
-func hardwrap(m Method, s string, limit int, preserveSpace bool) string {
+// HardwrapStyled is like [Hardwrap], but carries the active graphic
+// rendition and hyperlink across line breaks. They are reset before each
+// break and applied again after it, so each line renders on its own.
+// This treats the text as a sequence of graphemes.
+func HardwrapStyled(s string, limit int, preserveSpace bool) string {
+	return hardwrap(GraphemeWidth, s, limit, preserveSpace, &styleCarry{})
+}
+
+// HardwrapStyledWc is like [HardwrapWc], but carries the active graphic
+// rendition and hyperlink across line breaks. They are reset before each
+// break and applied again after it, so each line renders on its own.
+// This treats the text as a sequence of wide characters and runes.
+func HardwrapStyledWc(s string, limit int, preserveSpace bool) string {
+	return hardwrap(WcWidth, s, limit, preserveSpace, &styleCarry{})
+}
+
+// styleCarry tracks the graphic rendition and hyperlink active in a string,
+// to carry them across line breaks.
+type styleCarry struct {
+	sgr  [][]byte // the SGR sequences since the last reset
+	link []byte   // the sequence opening the active hyperlink
+}
+
+// update updates the active style with the given sequence decoded by p.
+func (c *styleCarry) update(seq []byte, p *Parser) {
+	switch {
+	case HasCsiPrefix(seq):
+		cmd := Cmd(p.Command())
+		if cmd.Final() != 'm' || cmd.Prefix() != 0 || cmd.Intermediate() != 0 {
+			return
+		}
+		params := p.Params()
+		if len(params) == 0 || params[0].Param(0) == 0 {
+			c.sgr = c.sgr[:0]
+			if len(params) <= 1 {
+				return
+			}
+		}
+		c.sgr = append(c.sgr, seq)
+	case HasOscPrefix(seq):
+		payload, ok := oscPayload(string(seq), "8")
+		if !ok {
+			return
+		}
+		if _, uri, _ := strings.Cut(payload, ";"); uri != "" {
+			c.link = seq
+		} else {
+			c.link = nil
+		}
+	}
+}
+
+// reset writes the sequences resetting the active style.
+func (c *styleCarry) reset(buf *bytes.Buffer) {
+	if len(c.sgr) > 0 {
+		buf.WriteString(ResetStyle)
+	}
+	if c.link != nil {
+		buf.WriteString(ResetHyperlink())
+	}
+}
+
+// apply writes the sequences applying the active style.
+func (c *styleCarry) apply(buf *bytes.Buffer) {
+	if c.link != nil {
+		buf.Write(c.link)
+	}
+	for _, seq := range c.sgr {
+		buf.Write(seq)
+	}
+}
+
+// hardwrap wraps s at limit cells. If carry isn't nil, the active style is
+// carried across line breaks.
+func hardwrap(m Method, s string, limit int, preserveSpace bool, carry *styleCarry) string {
 	if limit < 1 {
 		return s
 	}
@@ -52,8 +127,29 @@ func hardwrap(m Method, s string, limit int, preserveSpace bool) string {
 		curWidth = 0
 	}
 
+	var p *Parser
+	if carry != nil {
+		p = GetParser()
+		defer PutParser(p)
+		addNewline = func() {
+			carry.reset(&buf)
+			buf.WriteByte('\n')
+			carry.apply(&buf)
+			curWidth = 0
+		}
+	}
+
 	i := 0
 	for i < len(b) {
+		if carry != nil && pstate == parser.GroundState && (b[i] == ESC || b[i] == CSI || b[i] == OSC) {
+			// Decode the whole sequence to track the active style.
+			seq, _, n, _ := DecodeSequence(b[i:], NormalState, p)
+			carry.update(seq, p)
+			buf.Write(seq)
+			i += n
+			continue
+		}
+
 		state, action := parser.Table.Transition(pstate, b[i])
 		if state == parser.Utf8State {
 			var width int
@@ -65,13 +161,16 @@ func hardwrap(m Method, s string, limit int, preserveSpace bool) string {
 
 			if curWidth+width > limit {
 				addNewline()
+				forceNewline = true
 			}
-			if !preserveSpace && curWidth == 0 && len(cluster) <= 4 {
-				// Skip spaces at the beginning of a line
-				if r, _ := utf8.DecodeRune(cluster); r != utf8.RuneError && unicode.IsSpace(r) {
+			if curWidth == 0 {
+				// Skip spaces at the beginning of a wrapped line
+				if r, _ := utf8.DecodeRune(cluster); !preserveSpace && forceNewline && len(cluster) <= 4 &&
+					r != utf8.RuneError && unicode.IsSpace(r) {
 					pstate = parser.GroundState
 					continue
 				}
+				forceNewline = false
 			}
 
 			buf.Write(cluster)
@@ -353,17 +452,14 @@ func wrap(m Method, s string, limit int, breakpoints string) string {
 			case r != utf8.RuneError && unicode.IsSpace(r) && r != nbsp: // nbsp is a non-breaking space
 				addWord()
 				space.WriteRune(r)
-			case bytes.ContainsAny(cluster, breakpoints):
+			case bytes.ContainsAny(cluster, breakpoints) && curWidth+space.Len()+wordLen+width <= limit:
 				addSpace()
-				if curWidth+wordLen+width > limit {
-					word.Write(cluster)
-					wordLen += width
-				} else {
-					addWord()
-					buf.Write(cluster)
-					curWidth += width
-				}
+				addWord()
+				buf.Write(cluster)
+				curWidth += width
 			default:
+				// Breakpoints that can't fit in the current line are part of
+				// the word.
 				if wordLen+width > limit {
 					// Hardwrap the word if it's too long
 					addWord()
@@ -400,32 +496,22 @@ func wrap(m Method, s string, limit int, breakpoints string) string {
 			case unicode.IsSpace(r):
 				addWord()
 				space.WriteRune(r)
-			case r == '-':
-				fallthrough
-			case runeContainsAny(r, breakpoints):
+			case (r == '-' || runeContainsAny(r, breakpoints)) && curWidth+space.Len()+wordLen+1 <= limit:
 				addSpace()
-				if curWidth+wordLen >= limit {
-					// We can't fit the breakpoint in the current line, treat
-					// it as part of the word.
-					word.WriteRune(r)
-					wordLen++
-				} else {
-					addWord()
-					buf.WriteRune(r)
-					curWidth++
-				}
+				addWord()
+				buf.WriteRune(r)
+				curWidth++
 			default:
-				if curWidth == limit {
-					addNewline()
-				}
-				word.WriteRune(r)
-				wordLen++
-
-				if wordLen == limit {
+				// Breakpoints that can't fit in the current line are part of
+				// the word.
+				if wordLen+1 > limit {
 					// Hardwrap the word if it's too long
 					addWord()
 				}
 
+				word.WriteRune(r)
+				wordLen++
+
 				if curWidth+wordLen+space.Len() > limit {
 					addNewline()
 				}
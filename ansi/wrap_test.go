@@ -1,6 +1,7 @@
 package ansi_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/x/ansi"
@@ -33,6 +34,11 @@ var cases = []struct {
 	{"emoji", "foo🫧foobar", 4, "foo\n🫧fo\nobar", false},
 	{"osc8_wrap", "สวัสดีสวัสดี\x1b]8;;https://example.com\x1b\\สวัสดีสวัสดี\x1b]8;;\x1b\\", 8, "สวัสดีสวัสดี\x1b]8;;https://example.com\x1b\\\nสวัสดีสวัสดี\x1b]8;;\x1b\\", false},
 	{"column", "VERTICAL", 1, "V\nE\nR\nT\nI\nC\nA\nL", false},
+	{"preserve_indent", "    foo    bar    baz", 7, "    foo\n    bar\n    baz", true},
+	{"trim_indent", "    foo    bar    baz", 7, "    foo\nbar    \nbaz", false},
+	{"preserve_wide_space", "ab\u3000\u3000cd", 4, "ab\u3000\n\u3000cd", true},
+	{"trim_wide_space", "ab\u3000\u3000cd", 4, "ab\u3000\ncd", false},
+	{"begin_with_wide_space", "\u3000ab\n\u3000cd", 4, "\u3000ab\n\u3000cd", false},
 }
 
 func TestHardwrap(t *testing.T) {
@@ -72,6 +78,37 @@ var wwCases = []struct {
 	{"style_code_dont_affect_length", "\x1B[38;2;249;38;114mfoo\x1B[0m\x1B[38;2;248;248;242m \x1B[0m\x1B[38;2;230;219;116mbar\x1B[0m", 7, "", "\x1B[38;2;249;38;114mfoo\x1B[0m\x1B[38;2;248;248;242m \x1B[0m\x1B[38;2;230;219;116mbar\x1B[0m"},
 	{"style_code_dont_get_wrapped", "\x1B[38;2;249;38;114m(\x1B[0m\x1B[38;2;248;248;242mjust another test\x1B[38;2;249;38;114m)\x1B[0m", 3, "", "\x1B[38;2;249;38;114m(\x1B[0m\x1B[38;2;248;248;242mjust\nanother\ntest\x1B[38;2;249;38;114m)\x1B[0m"},
 	{"osc8_wrap", "สวัสดีสวัสดี\x1b]8;;https://example.com\x1b\\ สวัสดีสวัสดี\x1b]8;;\x1b\\", 8, "", "สวัสดีสวัสดี\x1b]8;;https://example.com\x1b\\\nสวัสดีสวัสดี\x1b]8;;\x1b\\"},
+	{"hyphen_always_breakpoint", "foo-bar-baz", 4, "", "foo-\nbar-\nbaz"},
+	{"custom_breakpoint", "foo/bar/baz", 5, "/", "foo/\nbar/\nbaz"},
+	{"style_hyphen", "\x1b[1mfoo-\x1b[22mbar baz", 4, "", "\x1b[1mfoo-\n\x1b[22mbar\nbaz"},
+	{"osc8_word", "go to \x1b]8;;https://example.com\x1b\\example\x1b]8;;\x1b\\ now", 8, "", "go to\n\x1b]8;;https://example.com\x1b\\example\x1b]8;;\x1b\\\nnow"},
+}
+
+func TestHardwrapStyled(t *testing.T) {
+	cases := []struct {
+		name          string
+		input         string
+		limit         int
+		preserveSpace bool
+		expected      string
+	}{
+		{"plain", "foobarfoo", 4, true, "foob\narfo\no"},
+		{"style", "\x1b[1;31mhello world\x1b[m ok", 3, false, "\x1b[1;31mhel\x1b[m\n\x1b[1;31mlo \x1b[m\n\x1b[1;31mwor\x1b[m\n\x1b[1;31mld\x1b[m \nok"},
+		{"stacked styles", "\x1b[1m\x1b[4mabcdef\x1b[0mgh", 3, false, "\x1b[1m\x1b[4mabc\x1b[m\n\x1b[1m\x1b[4mdef\x1b[0m\ngh"},
+		{"reset and style", "\x1b[1mab\x1b[0;32mcdef", 3, false, "\x1b[1mab\x1b[0;32mc\x1b[m\n\x1b[0;32mdef"},
+		{"exact fit", "\x1b[31mabc\x1b[m", 3, false, "\x1b[31mabc\x1b[m"},
+		{"hyperlink", "\x1b]8;;https://example.com\x1b\\linktext\x1b]8;;\x1b\\!", 4, false, "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\a\n\x1b]8;;https://example.com\x1b\\text\x1b]8;;\x1b\\\n!"},
+		{"wide straddling", "ab你好", 3, false, "ab\n你\n好"},
+		{"styled wide straddling", "\x1b[32mab你好\x1b[m", 3, false, "\x1b[32mab\x1b[m\n\x1b[32m你\x1b[m\n\x1b[32m好\x1b[m"},
+		{"line feed", "\x1b[31mab\ncd\x1b[m", 3, false, "\x1b[31mab\x1b[m\n\x1b[31mcd\x1b[m"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ansi.HardwrapStyled(tc.input, tc.limit, tc.preserveSpace); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
 }
 
 func TestWordwrap(t *testing.T) {
@@ -223,3 +260,38 @@ func TestWrap(t *testing.T) {
 		})
 	}
 }
+
+func TestWrapBreakpointAtLimit(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+		width    int
+	}{
+		{"hyphen after limit-1", "abc-def", "abc-\ndef", 4},
+		{"hyphen after limit", "abcd-ef", "abcd\n-ef", 4},
+		{"hyphen after limit+1", "abcde-f", "abcd\ne-f", 4},
+		{"breakpoint after limit-1", "abc/def", "abc/\ndef", 4},
+		{"breakpoint after limit", "abcd/ef", "abcd\n/ef", 4},
+		{"breakpoint after limit+1", "abcde/f", "abcd\ne/f", 4},
+		{"hyphen after space", "ab cd-ef", "ab\ncd-\nef", 4},
+		{"space then hyphen at limit", "abcd -ef", "abcd\n-ef", 4},
+		{"double hyphen at limit", "abcd--ef", "abcd\n--ef", 4},
+		{"wide then hyphen at limit", "ab你-c", "ab你\n-c", 4},
+		{"styled hyphen at limit", "a\x1b[31mbcd\x1b[m-ef", "a\x1b[31mbcd\x1b[m\n-ef", 4},
+		{"wide after hardwrap", "aaaaa你a", "aa\naa\na\n你\na", 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := ansi.Wrap(tc.input, tc.width, "/")
+			if output != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, output)
+			}
+			for _, line := range strings.Split(output, "\n") {
+				if w := ansi.StringWidth(line); w > tc.width {
+					t.Errorf("line %q is %d cells wide, over the limit of %d", line, w, tc.width)
+				}
+			}
+		})
+	}
+}
@@ -138,24 +138,14 @@ func (l Line) set(x int, c *Cell, clone bool) bool {
 
 	// When a wide cell is partially overwritten, we need
 	// to fill the rest of the cell with space cells to
-	// avoid rendering issues.
-	prev := l.At(x)
-	if prev != nil && prev.Width > 1 {
-		// Writing to the first wide cell
-		for j := 0; j < prev.Width && x+j < l.Width(); j++ {
-			l[x+j] = prev.Clone().Blank()
-		}
-	} else if prev != nil && prev.Width == 0 {
-		// Writing to wide cell placeholders
-		for j := 1; j < maxCellWidth && x-j >= 0; j++ {
-			wide := l.At(x - j)
-			if wide != nil && wide.Width > 1 && j < wide.Width {
-				for k := 0; k < wide.Width; k++ {
-					l[x-j+k] = wide.Clone().Blank()
-				}
-				break
-			}
-		}
+	// avoid rendering issues. A wide cell can overwrite
+	// parts of several cells.
+	n := 1
+	if c != nil && c.Width > 1 {
+		n = c.Width
+	}
+	for i := 0; i < n && x+i < width; i++ {
+		l.clearWide(x + i)
 	}
 
 	if clone && c != nil {
@@ -184,6 +174,29 @@ func (l Line) set(x int, c *Cell, clone bool) bool {
 	return true
 }
 
+// clearWide replaces the wide cell at the given x position, or the wide cell
+// whose placeholder is at x, and its placeholders with blank cells.
+func (l Line) clearWide(x int) {
+	prev := l.At(x)
+	if prev != nil && prev.Width > 1 {
+		// Writing to the first wide cell
+		for j := 0; j < prev.Width && x+j < l.Width(); j++ {
+			l[x+j] = prev.Clone().Blank()
+		}
+	} else if prev != nil && prev.Width == 0 {
+		// Writing to wide cell placeholders
+		for j := 1; j < maxCellWidth && x-j >= 0; j++ {
+			wide := l.At(x - j)
+			if wide != nil && wide.Width > 1 && j < wide.Width {
+				for k := 0; k < wide.Width && x-j+k < l.Width(); k++ {
+					l[x-j+k] = wide.Clone().Blank()
+				}
+				break
+			}
+		}
+	}
+}
+
 // Buffer is a 2D grid of cells representing a screen or terminal.
 type Buffer struct {
 	// Lines holds the lines of the buffer.
@@ -336,22 +349,30 @@ func (b *Buffer) InsertLineRect(y, n int, c *Cell, rect Rectangle) {
 		return
 	}
 
+	// Limit the bounds to the buffer
+	rect = rect.Intersect(b.Bounds())
+
 	// Limit number of lines to insert to available space
 	if y+n > rect.Max.Y {
 		n = rect.Max.Y - y
 	}
 
+	// Wide cells crossing the edges of the bounds would be split, so we erase
+	// them first. This lets us move the cells directly while keeping wide
+	// cells and their placeholders together.
+	b.eraseWideCellEdges(Rect(rect.Min.X, y, rect.Dx(), rect.Max.Y-y), c)
+	minX, maxX := rect.Min.X, rect.Max.X
+
 	// Move existing lines down within the bounds
 	for i := rect.Max.Y - 1; i >= y+n; i-- {
-		for x := rect.Min.X; x < rect.Max.X; x++ {
-			// We don't need to clone c here because we're just moving lines down.
-			b.setCell(x, i, b.Lines[i-n][x], false)
-		}
+		copy(b.Lines[i][minX:maxX], b.Lines[i-n][minX:maxX])
 	}
 
-	// Clear the newly inserted lines within bounds
+	// Clear the newly inserted lines within bounds. These still point to the
+	// moved cells, so we drop them before setting the new ones.
 	for i := y; i < y+n; i++ {
-		for x := rect.Min.X; x < rect.Max.X; x++ {
+		for x := minX; x < maxX; x++ {
+			b.Lines[i][x] = nil
 			b.setCell(x, i, c, true)
 		}
 	}
@@ -367,24 +388,30 @@ func (b *Buffer) DeleteLineRect(y, n int, c *Cell, rect Rectangle) {
 		return
 	}
 
+	// Limit the bounds to the buffer
+	rect = rect.Intersect(b.Bounds())
+
 	// Limit deletion count to available space in scroll region
 	if n > rect.Max.Y-y {
 		n = rect.Max.Y - y
 	}
 
+	// Wide cells crossing the edges of the bounds would be split, so we erase
+	// them first. This lets us move the cells directly while keeping wide
+	// cells and their placeholders together.
+	b.eraseWideCellEdges(Rect(rect.Min.X, y, rect.Dx(), rect.Max.Y-y), c)
+	minX, maxX := rect.Min.X, rect.Max.X
+
 	// Shift cells up within the bounds
 	for dst := y; dst < rect.Max.Y-n; dst++ {
-		src := dst + n
-		for x := rect.Min.X; x < rect.Max.X; x++ {
-			// We don't need to clone c here because we're just moving cells up.
-			// b.lines[dst][x] = b.lines[src][x]
-			b.setCell(x, dst, b.Lines[src][x], false)
-		}
+		copy(b.Lines[dst][minX:maxX], b.Lines[dst+n][minX:maxX])
 	}
 
-	// Fill the bottom n lines with blank cells
+	// Fill the bottom n lines with blank cells. These still point to the
+	// moved cells, so we drop them before setting the new ones.
 	for i := rect.Max.Y - n; i < rect.Max.Y; i++ {
-		for x := rect.Min.X; x < rect.Max.X; x++ {
+		for x := minX; x < maxX; x++ {
+			b.Lines[i][x] = nil
 			b.setCell(x, i, c, true)
 		}
 	}
@@ -409,6 +436,9 @@ func (b *Buffer) InsertCell(x, y, n int, c *Cell) {
 // optional cell, within the rectangle bounds. Only cells within the
 // rectangle's bounds are affected, following terminal [ansi.ICH] behavior.
 func (b *Buffer) InsertCellRect(x, y, n int, c *Cell, rect Rectangle) {
+	// The rectangle can reach past the buffer, e.g. with a right margin
+	// beyond the screen.
+	rect = rect.Intersect(b.Bounds())
 	if n <= 0 || y < rect.Min.Y || y >= rect.Max.Y || y >= b.Height() ||
 		x < rect.Min.X || x >= rect.Max.X || x >= b.Width() {
 		return
@@ -419,20 +449,52 @@ func (b *Buffer) InsertCellRect(x, y, n int, c *Cell, rect Rectangle) {
 		n = rect.Max.X - x
 	}
 
-	// Move existing cells within rectangle bounds to the right
+	// Inserting in the middle of a wide cell splits it, so we erase it first.
+	b.eraseWideCell(x, y, c)
+
+	// Move existing cells within rectangle bounds to the right. We move the
+	// cells directly to keep wide cells and their placeholders together.
+	line := b.Lines[y]
 	for i := rect.Max.X - 1; i >= x+n && i-n >= rect.Min.X; i-- {
-		// We don't need to clone c here because we're just moving cells to the
-		// right.
-		// b.lines[y][i] = b.lines[y][i-n]
-		b.setCell(i, y, b.Lines[y][i-n], false)
+		if i < len(line) {
+			line[i] = line[i-n]
+		}
+	}
+
+	// A wide cell pushed against the right edge loses its placeholders.
+	if last := rect.Max.X - 1; last >= x+n && last < len(line) {
+		if cell := line[last]; cell != nil && cell.Width > 1 {
+			line[last] = cell.Clone().Blank()
+		}
 	}
 
-	// Clear the newly inserted cells within rectangle bounds
+	// Clear the newly inserted cells within rectangle bounds. These still
+	// point to the moved cells, so we drop them before setting the new ones.
 	for i := x; i < x+n && i < rect.Max.X; i++ {
+		line[i] = nil
 		b.setCell(i, y, c, true)
 	}
 }
 
+// eraseWideCell replaces the cell at the given position with the given
+// optional cell if it's a wide cell placeholder. This blanks the rest of the
+// wide cell.
+func (b *Buffer) eraseWideCell(x, y int, c *Cell) {
+	if prev := b.Cell(x, y); prev != nil && prev.Width == 0 {
+		b.setCell(x, y, c, true)
+	}
+}
+
+// eraseWideCellEdges erases the wide cells crossing the left and right edges
+// of the given rectangle, replacing the split cells with the given optional
+// cell.
+func (b *Buffer) eraseWideCellEdges(rect Rectangle, c *Cell) {
+	for y := rect.Min.Y; y < rect.Max.Y && y < b.Height(); y++ {
+		b.eraseWideCell(rect.Min.X, y, c)
+		b.eraseWideCell(rect.Max.X, y, c)
+	}
+}
+
 // DeleteCell deletes cells at the given position, with the given optional
 // cell, within the specified rectangles. If no rectangles are specified, it
 // deletes cells in the entire buffer. This follows terminal [ansi.DCH]
@@ -445,6 +507,9 @@ func (b *Buffer) DeleteCell(x, y, n int, c *Cell) {
 // optional cell, within the rectangle bounds. Only cells within the
 // rectangle's bounds are affected, following terminal [ansi.DCH] behavior.
 func (b *Buffer) DeleteCellRect(x, y, n int, c *Cell, rect Rectangle) {
+	// The rectangle can reach past the buffer, e.g. with a right margin
+	// beyond the screen.
+	rect = rect.Intersect(b.Bounds())
 	if n <= 0 || y < rect.Min.Y || y >= rect.Max.Y || y >= b.Height() ||
 		x < rect.Min.X || x >= rect.Max.X || x >= b.Width() {
 		return
@@ -456,18 +521,24 @@ func (b *Buffer) DeleteCellRect(x, y, n int, c *Cell, rect Rectangle) {
 		n = remainingCells
 	}
 
-	// Shift the remaining cells to the left
-	for i := x; i < rect.Max.X-n; i++ {
-		if i+n < rect.Max.X {
-			// We don't need to clone c here because we're just moving cells to
-			// the left.
-			// b.lines[y][i] = b.lines[y][i+n]
-			b.setCell(i, y, b.Lines[y][i+n], false)
-		}
+	// Deleting part of a wide cell splits it, so we erase wide cells
+	// crossing either edge of the deleted range first.
+	b.eraseWideCell(x, y, c)
+	if x+n < rect.Max.X {
+		b.eraseWideCell(x+n, y, c)
+	}
+
+	// Shift the remaining cells to the left. We move the cells directly to
+	// keep wide cells and their placeholders together.
+	line := b.Lines[y]
+	for i := x; i < rect.Max.X-n && i+n < len(line); i++ {
+		line[i] = line[i+n]
 	}
 
-	// Fill the vacated positions with the given cell
-	for i := rect.Max.X - n; i < rect.Max.X; i++ {
+	// Fill the vacated positions with the given cell. These still point to
+	// the moved cells, so we drop them before setting the new ones.
+	for i := rect.Max.X - n; i < rect.Max.X && i < len(line); i++ {
+		line[i] = nil
 		b.setCell(i, y, c, true)
 	}
 }
@@ -144,6 +144,21 @@ func TestLine(t *testing.T) {
 	}
 }
 
+func TestLineSetWide(t *testing.T) {
+	line := make(Line, 5)
+	line.Set(0, NewCell('a'))
+	line.Set(1, NewCell('世'))
+	line.Set(3, NewCell('界'))
+
+	// Overwrite the right half of 世 and the left half of 界.
+	line.Set(2, NewCell('你'))
+	for x, want := range []*Cell{{Rune: 'a', Width: 1}, &BlankCell, {Rune: '你', Width: 2}, {}, &BlankCell} {
+		if got := line.At(x); !got.Equal(want) {
+			t.Errorf("cell %d = %#v, want %#v", x, got, want)
+		}
+	}
+}
+
 func TestBuffer(t *testing.T) {
 	t.Run("creation and resizing", func(t *testing.T) {
 		b := NewBuffer(3, 2)
@@ -225,3 +240,25 @@ func TestBufferBounds(t *testing.T) {
 		t.Errorf("Buffer bounds max = (%d,%d), want (4,3)", bounds.Max.X, bounds.Max.Y)
 	}
 }
+
+func TestBufferCellRectOutOfBounds(t *testing.T) {
+	// The rectangles reach past the right edge of the buffer.
+	rect := Rect(0, 0, 8, 1)
+
+	b := NewBuffer(4, 1)
+	for x, r := range "abcd" {
+		b.SetCell(x, 0, NewCell(r))
+	}
+	b.InsertCellRect(1, 0, 9, nil, rect)
+	if got, want := b.Line(0).String(), "a"; got != want {
+		t.Errorf("after InsertCellRect, line = %q, want %q", got, want)
+	}
+
+	for x, r := range "abcd" {
+		b.SetCell(x, 0, NewCell(r))
+	}
+	b.DeleteCellRect(1, 0, 2, nil, rect)
+	if got, want := b.Line(0).String(), "ad"; got != want {
+		t.Errorf("after DeleteCellRect, line = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,9 @@
 package cellbuf
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/charmbracelet/x/ansi"
 )
 
@@ -114,6 +117,28 @@ type Link struct {
 	Params string
 }
 
+// NewLink returns a hyperlink to the given URL with the given parameters,
+// such as an "id". The parameters are sorted by key and joined as key=value
+// pairs separated by colons.
+func NewLink(url string, params map[string]string) Link {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + params[k]
+	}
+	return Link{URL: url, Params: strings.Join(pairs, ":")}
+}
+
+// Sequence returns the OSC 8 sequence that sets the hyperlink. An empty
+// hyperlink returns the sequence that resets it.
+func (h Link) Sequence() string {
+	return "\x1b]8;" + h.Params + ";" + h.URL + "\x1b\\"
+}
+
 // String returns a string representation of the hyperlink.
 func (h Link) String() string {
 	return h.URL
@@ -137,7 +162,7 @@ func (h Link) Empty() bool {
 
 // AttrMask is a bitmask for text attributes that can change the look of text.
 // These attributes can be combined to create different styles.
-type AttrMask uint8
+type AttrMask uint16
 
 // These are the available text attributes that can be combined to create
 // different styles.
@@ -150,6 +175,7 @@ const (
 	ReverseAttr
 	ConcealAttr
 	StrikethroughAttr
+	OverlineAttr
 
 	ResetAttr AttrMask = 0
 )
@@ -209,6 +235,9 @@ func (s Style) Sequence() string {
 		if s.Attrs&StrikethroughAttr != 0 {
 			b = b.Strikethrough()
 		}
+		if s.Attrs&OverlineAttr != 0 {
+			b = b.Overline()
+		}
 	}
 	if s.UlStyle != NoUnderline {
 		switch s.UlStyle {
@@ -258,25 +287,23 @@ func (s Style) DiffSequence(o Style) string {
 		b = b.UnderlineColor(s.Ul)
 	}
 
-	var (
-		noBlink  bool
-		isNormal bool
-	)
-
 	if s.Attrs != o.Attrs {
-		if s.Attrs&BoldAttr != o.Attrs&BoldAttr {
+		// Normal intensity turns off both bold and faint, set the one that's
+		// kept again.
+		if o.Attrs&^s.Attrs&(BoldAttr|FaintAttr) != 0 {
+			b = b.NormalIntensity()
 			if s.Attrs&BoldAttr != 0 {
 				b = b.Bold()
-			} else if !isNormal {
-				isNormal = true
-				b = b.NormalIntensity()
 			}
-		}
-		if s.Attrs&FaintAttr != o.Attrs&FaintAttr {
 			if s.Attrs&FaintAttr != 0 {
 				b = b.Faint()
-			} else if !isNormal {
-				b = b.NormalIntensity()
+			}
+		} else {
+			if s.Attrs&^o.Attrs&BoldAttr != 0 {
+				b = b.Bold()
+			}
+			if s.Attrs&^o.Attrs&FaintAttr != 0 {
+				b = b.Faint()
 			}
 		}
 		if s.Attrs&ItalicAttr != o.Attrs&ItalicAttr {
@@ -286,19 +313,22 @@ func (s Style) DiffSequence(o Style) string {
 				b = b.NoItalic()
 			}
 		}
-		if s.Attrs&SlowBlinkAttr != o.Attrs&SlowBlinkAttr {
+		// No blink turns off both slow and rapid blink, set the one that's
+		// kept again.
+		if o.Attrs&^s.Attrs&(SlowBlinkAttr|RapidBlinkAttr) != 0 {
+			b = b.NoBlink()
 			if s.Attrs&SlowBlinkAttr != 0 {
 				b = b.SlowBlink()
-			} else if !noBlink {
-				noBlink = true
-				b = b.NoBlink()
 			}
-		}
-		if s.Attrs&RapidBlinkAttr != o.Attrs&RapidBlinkAttr {
 			if s.Attrs&RapidBlinkAttr != 0 {
 				b = b.RapidBlink()
-			} else if !noBlink {
-				b = b.NoBlink()
+			}
+		} else {
+			if s.Attrs&^o.Attrs&SlowBlinkAttr != 0 {
+				b = b.SlowBlink()
+			}
+			if s.Attrs&^o.Attrs&RapidBlinkAttr != 0 {
+				b = b.RapidBlink()
 			}
 		}
 		if s.Attrs&ReverseAttr != o.Attrs&ReverseAttr {
@@ -322,6 +352,13 @@ func (s Style) DiffSequence(o Style) string {
 				b = b.NoStrikethrough()
 			}
 		}
+		if s.Attrs&OverlineAttr != o.Attrs&OverlineAttr {
+			if s.Attrs&OverlineAttr != 0 {
+				b = b.Overline()
+			} else {
+				b = b.NoOverline()
+			}
+		}
 	}
 
 	if s.UlStyle != o.UlStyle {
@@ -331,7 +368,26 @@ func (s Style) DiffSequence(o Style) string {
 	return b.String()
 }
 
-// Equal returns true if the style is equal to the other style.
+// Diff returns the SGR sequence that transforms the style into the next
+// style, changing only the attributes and colors that differ. When resetting
+// the style and setting the next one is shorter, it returns that instead. It
+// returns an empty string when the styles are equal.
+func (s Style) Diff(next Style) string {
+	if s.Equal(&next) {
+		return ""
+	}
+	if next.Empty() {
+		return ansi.ResetStyle
+	}
+	seq := next.DiffSequence(s)
+	if full := "\x1b[0;" + strings.TrimPrefix(next.Sequence(), "\x1b["); len(full) < len(seq) {
+		return full
+	}
+	return seq
+}
+
+// Equal returns true if the style is equal to the other style. Colors are
+// compared by their RGBA values.
 func (s *Style) Equal(o *Style) bool {
 	return s.Attrs == o.Attrs &&
 		s.UlStyle == o.UlStyle &&
@@ -340,6 +396,30 @@ func (s *Style) Equal(o *Style) bool {
 		colorEqual(s.Ul, o.Ul)
 }
 
+// Merge returns the style with the other style layered over it. The colors
+// and underline style that are set in the other style replace the ones in the
+// style, and its attributes are added to the style attributes.
+//
+// A nil color and [NoUnderline] mean both unset and the terminal default, so
+// merging can't turn off a color, underline, or attribute. Use [Style.Reset]
+// or set the fields directly to do that.
+func (s Style) Merge(over Style) Style {
+	if over.Fg != nil {
+		s.Fg = over.Fg
+	}
+	if over.Bg != nil {
+		s.Bg = over.Bg
+	}
+	if over.Ul != nil {
+		s.Ul = over.Ul
+	}
+	if over.UlStyle != NoUnderline {
+		s.UlStyle = over.UlStyle
+	}
+	s.Attrs |= over.Attrs
+	return s
+}
+
 func colorEqual(c, o ansi.Color) bool {
 	if c == nil && o == nil {
 		return true
@@ -432,6 +512,16 @@ func (s *Style) Strikethrough(v bool) *Style {
 	return s
 }
 
+// Overline sets the overline attribute.
+func (s *Style) Overline(v bool) *Style {
+	if v {
+		s.Attrs |= OverlineAttr
+	} else {
+		s.Attrs &^= OverlineAttr
+	}
+	return s
+}
+
 // UnderlineStyle sets the underline style.
 func (s *Style) UnderlineStyle(style UnderlineStyle) *Style {
 	s.UlStyle = style
@@ -0,0 +1,157 @@
+package cellbuf
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestStyleDiff(t *testing.T) {
+	cases := []struct {
+		name       string
+		from, next Style
+		want       string
+	}{
+		{
+			name: "equal",
+			from: Style{Attrs: BoldAttr},
+			next: Style{Attrs: BoldAttr},
+			want: "",
+		},
+		{
+			name: "add attribute",
+			from: Style{Attrs: BoldAttr},
+			next: Style{Attrs: BoldAttr | ItalicAttr},
+			want: "\x1b[3m",
+		},
+		{
+			name: "remove attribute",
+			from: Style{Attrs: BoldAttr | ItalicAttr},
+			next: Style{Attrs: ItalicAttr},
+			want: "\x1b[22m",
+		},
+		{
+			name: "remove bold keep faint",
+			from: Style{Attrs: BoldAttr | FaintAttr | ItalicAttr},
+			next: Style{Attrs: FaintAttr | ItalicAttr},
+			want: "\x1b[22;2m",
+		},
+		{
+			name: "remove slow blink keep rapid blink",
+			from: Style{Attrs: SlowBlinkAttr | RapidBlinkAttr | ItalicAttr},
+			next: Style{Attrs: RapidBlinkAttr | ItalicAttr},
+			want: "\x1b[25;6m",
+		},
+		{
+			name: "change color",
+			from: Style{Fg: ansi.Red, Attrs: BoldAttr},
+			next: Style{Fg: ansi.Blue, Attrs: BoldAttr},
+			want: "\x1b[34m",
+		},
+		{
+			name: "default color",
+			from: Style{Fg: ansi.Red, Attrs: BoldAttr},
+			next: Style{Attrs: BoldAttr},
+			want: "\x1b[39m",
+		},
+		{
+			name: "from empty",
+			next: Style{Fg: ansi.Red},
+			want: "\x1b[31m",
+		},
+		{
+			name: "to empty",
+			from: Style{Fg: ansi.Red, Attrs: BoldAttr},
+			want: "\x1b[m",
+		},
+		{
+			name: "reset is shorter",
+			from: Style{Fg: ansi.Red, Bg: ansi.Blue, Attrs: BoldAttr | ItalicAttr | ReverseAttr},
+			next: Style{Attrs: StrikethroughAttr},
+			want: "\x1b[0;9m",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.from.Diff(tc.next); got != tc.want {
+				t.Errorf("want diff %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStyleEqual(t *testing.T) {
+	a := Style{Fg: ansi.Red, Ul: ansi.TrueColor(0xff0000), UlStyle: CurlyUnderline, Attrs: BoldAttr}
+	b := Style{Fg: ansi.Red, Ul: ansi.TrueColor(0xff0000), UlStyle: CurlyUnderline, Attrs: BoldAttr}
+	if !a.Equal(&b) {
+		t.Errorf("expected %#v to equal %#v", a, b)
+	}
+
+	b.Bg = ansi.Black
+	if a.Equal(&b) {
+		t.Errorf("expected %#v not to equal %#v", a, b)
+	}
+}
+
+func TestStyleMerge(t *testing.T) {
+	base := Style{Bg: ansi.Blue, Attrs: BoldAttr}
+	got := base.Merge(Style{Fg: ansi.Red})
+	want := Style{Fg: ansi.Red, Bg: ansi.Blue, Attrs: BoldAttr}
+	if !got.Equal(&want) {
+		t.Errorf("want style %#v, got %#v", want, got)
+	}
+
+	// Unset fields don't override the base style.
+	got = got.Merge(Style{Fg: ansi.Green, UlStyle: DoubleUnderline, Attrs: ItalicAttr})
+	want = Style{Fg: ansi.Green, Bg: ansi.Blue, UlStyle: DoubleUnderline, Attrs: BoldAttr | ItalicAttr}
+	if !got.Equal(&want) {
+		t.Errorf("want style %#v, got %#v", want, got)
+	}
+
+	if got := base.Merge(Style{}); !got.Equal(&base) {
+		t.Errorf("merging an empty style: want %#v, got %#v", base, got)
+	}
+}
+
+func TestStyleBuilder(t *testing.T) {
+	cases := []struct {
+		name  string
+		style *Style
+		want  string
+	}{
+		{
+			name:  "bold foreground",
+			style: new(Style).Bold(true).Foreground(ansi.Red),
+			want:  "\x1b[1;31m",
+		},
+		{
+			name: "all",
+			style: new(Style).
+				Bold(true).
+				Italic(true).
+				UnderlineStyle(CurlyUnderline).
+				Reverse(true).
+				Foreground(ansi.TrueColor(0x102030)).
+				Background(ansi.ExtendedColor(200)),
+			want: "\x1b[1;3;7;4:3;38;2;16;32;48;48;5;200m",
+		},
+		{
+			name:  "unset",
+			style: new(Style).Bold(true).Italic(true).Underline(true).Bold(false).Underline(false),
+			want:  "\x1b[3m",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.style.Sequence(); got != tc.want {
+				t.Errorf("want sequence %q, got %q", tc.want, got)
+			}
+			// The builder sets the same fields as reading the sequence.
+			if st := readSgrStyle(tc.want); !st.Equal(tc.style) {
+				t.Errorf("want style %#v, got %#v", st, *tc.style)
+			}
+		})
+	}
+}
@@ -97,6 +97,10 @@ func ReadStyle(params ansi.Params, pen *Style) {
 			}
 		case 49: // Default Background
 			pen.Background(nil)
+		case 53: // Overline
+			pen.Overline(true)
+		case 55: // Not overlined
+			pen.Overline(false)
 		case 58: // Set underline color
 			var c color.Color
 			n := ReadStyleColor(params[i:], &c)
@@ -114,9 +118,10 @@ func ReadStyle(params ansi.Params, pen *Style) {
 	}
 }
 
-// ReadLink reads a hyperlink escape sequence from a data buffer.
+// ReadLink reads a hyperlink escape sequence from a data buffer. The URL may
+// contain semicolons.
 func ReadLink(p []byte, link *Link) {
-	params := bytes.Split(p, []byte{';'})
+	params := bytes.SplitN(p, []byte{';'}, 3)
 	if len(params) != 3 {
 		return
 	}
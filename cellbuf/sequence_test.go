@@ -376,3 +376,247 @@ func TestReadStyleColor(t *testing.T) {
 		})
 	}
 }
+
+// readSgrStyle reads the style set by a sequence of SGR escape sequences.
+func readSgrStyle(s string) (st Style) {
+	p := ansi.NewParser()
+	for len(s) > 0 {
+		_, _, n, _ := ansi.DecodeSequence(s, 0, p)
+		ReadStyle(p.Params(), &st)
+		s = s[n:]
+	}
+	return st
+}
+
+func TestReadStyleUnderline(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	tests := []struct {
+		name string
+		sgr  string
+		want Style
+		seq  string
+	}{
+		{
+			name: "curly red underline",
+			sgr:  "\x1b[4:3;58:2::255:0:0m",
+			want: Style{Ul: red, UlStyle: CurlyUnderline},
+			seq:  "\x1b[4:3;58;2;255;0;0m",
+		},
+		{
+			name: "semicolon separated underline color",
+			sgr:  "\x1b[4:3;58;2;255;0;0m",
+			want: Style{Ul: red, UlStyle: CurlyUnderline},
+			seq:  "\x1b[4:3;58;2;255;0;0m",
+		},
+		{
+			name: "single underline with indexed color",
+			sgr:  "\x1b[4;58;5;200m",
+			want: Style{Ul: ansi.ExtendedColor(200), UlStyle: SingleUnderline},
+			seq:  "\x1b[4;58;5;200m",
+		},
+		{
+			name: "double underline",
+			sgr:  "\x1b[4:2m",
+			want: Style{UlStyle: DoubleUnderline},
+			seq:  "\x1b[4:2m",
+		},
+		{
+			name: "dotted underline",
+			sgr:  "\x1b[4:4m",
+			want: Style{UlStyle: DottedUnderline},
+			seq:  "\x1b[4:4m",
+		},
+		{
+			name: "dashed underline",
+			sgr:  "\x1b[4:5m",
+			want: Style{UlStyle: DashedUnderline},
+			seq:  "\x1b[4:5m",
+		},
+		{
+			name: "no underline subparameter",
+			sgr:  "\x1b[4:3m\x1b[4:0m",
+			want: Style{},
+			seq:  "\x1b[m",
+		},
+		{
+			name: "reset underline color",
+			sgr:  "\x1b[4:5;58;5;1m\x1b[59m",
+			want: Style{UlStyle: DashedUnderline},
+			seq:  "\x1b[4:5m",
+		},
+		{
+			name: "not underlined keeps the color",
+			sgr:  "\x1b[4:3;58;5;1m\x1b[24m",
+			want: Style{Ul: ansi.ExtendedColor(1)},
+			seq:  "\x1b[58;5;1m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readSgrStyle(tt.sgr)
+			if !got.Equal(&tt.want) {
+				t.Fatalf("want style %#v, got %#v", tt.want, got)
+			}
+			seq := got.Sequence()
+			if seq != tt.seq {
+				t.Errorf("want sequence %q, got %q", tt.seq, seq)
+			}
+			if rt := readSgrStyle(seq); !rt.Equal(&got) {
+				t.Errorf("round trip: want style %#v, got %#v", got, rt)
+			}
+		})
+	}
+}
+
+func TestReadStyleOverlineStrikethrough(t *testing.T) {
+	st := readSgrStyle("\x1b[1;9;53m")
+	want := Style{Attrs: BoldAttr | StrikethroughAttr | OverlineAttr}
+	if !st.Equal(&want) {
+		t.Fatalf("want style %#v, got %#v", want, st)
+	}
+
+	seq := st.Sequence()
+	if want := "\x1b[1;9;53m"; seq != want {
+		t.Errorf("want sequence %q, got %q", want, seq)
+	}
+	if rt := readSgrStyle(seq); !rt.Equal(&st) {
+		t.Errorf("round trip: want style %#v, got %#v", st, rt)
+	}
+
+	st = readSgrStyle("\x1b[1;9;53m\x1b[29;55m")
+	if want := (Style{Attrs: BoldAttr}); !st.Equal(&want) {
+		t.Errorf("want style %#v, got %#v", want, st)
+	}
+
+	from := Style{Attrs: OverlineAttr | StrikethroughAttr}
+	to := Style{Attrs: StrikethroughAttr}
+	if got, want := to.DiffSequence(from), "\x1b[55m"; got != want {
+		t.Errorf("want diff sequence %q, got %q", want, got)
+	}
+}
+
+func TestReadStyleFaintConceal(t *testing.T) {
+	st := readSgrStyle("\x1b[2;8m")
+	want := Style{Attrs: FaintAttr | ConcealAttr}
+	if !st.Equal(&want) {
+		t.Fatalf("want style %#v, got %#v", want, st)
+	}
+
+	seq := st.Sequence()
+	if want := "\x1b[2;8m"; seq != want {
+		t.Errorf("want sequence %q, got %q", want, seq)
+	}
+	if rt := readSgrStyle(seq); !rt.Equal(&st) {
+		t.Errorf("round trip: want style %#v, got %#v", st, rt)
+	}
+
+	// Normal intensity clears both bold and faint.
+	st = readSgrStyle("\x1b[1;2;8;3m\x1b[22m")
+	if want := (Style{Attrs: ConcealAttr | ItalicAttr}); !st.Equal(&want) {
+		t.Errorf("want style %#v, got %#v", want, st)
+	}
+	st = readSgrStyle("\x1b[2;8m\x1b[28m")
+	if want := (Style{Attrs: FaintAttr}); !st.Equal(&want) {
+		t.Errorf("want style %#v, got %#v", want, st)
+	}
+}
+
+func TestReadStyleBlink(t *testing.T) {
+	for _, tc := range []struct {
+		seq  string
+		want Style
+	}{
+		{"\x1b[5m", Style{Attrs: SlowBlinkAttr}},
+		{"\x1b[6m", Style{Attrs: RapidBlinkAttr}},
+		{"\x1b[1;5;6m", Style{Attrs: BoldAttr | SlowBlinkAttr | RapidBlinkAttr}},
+	} {
+		st := readSgrStyle(tc.seq)
+		if !st.Equal(&tc.want) {
+			t.Errorf("%q: want style %#v, got %#v", tc.seq, tc.want, st)
+		}
+		if seq := st.Sequence(); seq != tc.seq {
+			t.Errorf("want sequence %q, got %q", tc.seq, seq)
+		}
+		if rt := readSgrStyle(st.Sequence()); !rt.Equal(&st) {
+			t.Errorf("round trip: want style %#v, got %#v", st, rt)
+		}
+	}
+
+	// Blink off clears both blink attributes.
+	st := readSgrStyle("\x1b[1;5;6m\x1b[25m")
+	if want := (Style{Attrs: BoldAttr}); !st.Equal(&want) {
+		t.Errorf("want style %#v, got %#v", want, st)
+	}
+}
+
+func TestReadStyleReset(t *testing.T) {
+	const styled = "\x1b[1;3;4;5;9;38;5;123;48;2;1;2;3;58;5;200m"
+	boldRed := Style{Fg: ansi.Red, Attrs: BoldAttr}
+	tests := []struct {
+		name string
+		seq  string
+		want Style
+	}{
+		{"empty parameters", "\x1b[m", Style{}},
+		{"zero", "\x1b[0m", Style{}},
+		{"reset then apply", "\x1b[0;1;31m", boldRed},
+		{"empty first parameter", "\x1b[;1;31m", boldRed},
+		{"reset in the middle", "\x1b[7;0;1;31m", boldRed},
+		{"reset last", "\x1b[1;31;0m", Style{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := readSgrStyle(styled + tt.seq)
+			if !st.Equal(&tt.want) {
+				t.Errorf("want style %#v, got %#v", tt.want, st)
+			}
+		})
+	}
+}
+
+func TestLinkSequence(t *testing.T) {
+	tests := []struct {
+		name string
+		link Link
+		want string
+	}{
+		{
+			name: "id param",
+			link: NewLink("https://example.com", map[string]string{"id": "1"}),
+			want: "\x1b]8;id=1;https://example.com\x1b\\",
+		},
+		{
+			name: "several params",
+			link: NewLink("https://example.com", map[string]string{"z": "2", "id": "1"}),
+			want: "\x1b]8;id=1:z=2;https://example.com\x1b\\",
+		},
+		{
+			name: "no params",
+			link: NewLink("https://example.com/a;b", nil),
+			want: "\x1b]8;;https://example.com/a;b\x1b\\",
+		},
+		{
+			name: "reset",
+			want: "\x1b]8;;\x1b\\",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seq := tt.link.Sequence()
+			if seq != tt.want {
+				t.Fatalf("want sequence %q, got %q", tt.want, seq)
+			}
+
+			p := ansi.NewParser()
+			ansi.DecodeSequence(seq, 0, p)
+			var link Link
+			ReadLink(p.Data(), &link)
+			if !link.Equal(&tt.link) {
+				t.Errorf("round trip: want link %#v, got %#v", tt.link, link)
+			}
+		})
+	}
+}
@@ -29,3 +29,11 @@ func ConvertStyle(s Style, p colorprofile.Profile) Style {
 
 	return s
 }
+
+// SequenceFor returns the ANSI sequence that sets the style, with its colors
+// converted to respect the given color profile. Colors are downsampled to the
+// closest 256 or 16 color index on terminals that don't support them, and
+// removed on terminals that don't support colors at all.
+func (s Style) SequenceFor(p colorprofile.Profile) string {
+	return ConvertStyle(s, p).Sequence()
+}
@@ -0,0 +1,35 @@
+package cellbuf
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestStyleSequenceFor(t *testing.T) {
+	st := Style{
+		Fg:    ansi.TrueColor(0xff0000),
+		Bg:    ansi.TrueColor(0x0000ff),
+		Attrs: BoldAttr,
+	}
+
+	cases := []struct {
+		profile colorprofile.Profile
+		want    string
+	}{
+		{colorprofile.TrueColor, "\x1b[1;38;2;255;0;0;48;2;0;0;255m"},
+		{colorprofile.ANSI256, "\x1b[1;38;5;196;48;5;21m"},
+		{colorprofile.ANSI, "\x1b[1;91;104m"},
+		{colorprofile.Ascii, "\x1b[1m"},
+		{colorprofile.NoTTY, "\x1b[m"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.profile.String(), func(t *testing.T) {
+			if got := st.SequenceFor(tc.profile); got != tc.want {
+				t.Errorf("want sequence %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
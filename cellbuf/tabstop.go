@@ -3,6 +3,10 @@ package cellbuf
 // DefaultTabInterval is the default tab interval.
 const DefaultTabInterval = 8
 
+// tabStopsPerWord is the number of columns stored in each element of the tab
+// stops bit set.
+const tabStopsPerWord = 8
+
 // TabStops represents horizontal line tab stops.
 type TabStops struct {
 	stops    []int
@@ -16,7 +20,7 @@ func NewTabStops(width, interval int) *TabStops {
 	ts := new(TabStops)
 	ts.interval = interval
 	ts.width = width
-	ts.stops = make([]int, (width+(interval-1))/interval)
+	ts.stops = make([]int, (width+(tabStopsPerWord-1))/tabStopsPerWord)
 	ts.init(0, width)
 	return ts
 }
@@ -33,10 +37,10 @@ func (ts *TabStops) Resize(width int) {
 	}
 
 	if width < ts.width {
-		size := (width + (ts.interval - 1)) / ts.interval
+		size := (width + (tabStopsPerWord - 1)) / tabStopsPerWord
 		ts.stops = ts.stops[:size]
 	} else {
-		size := (width - ts.width + (ts.interval - 1)) / ts.interval
+		size := (width+(tabStopsPerWord-1))/tabStopsPerWord - len(ts.stops)
 		ts.stops = append(ts.stops, make([]int, size)...)
 	}
 
@@ -47,7 +51,7 @@ func (ts *TabStops) Resize(width int) {
 // IsStop returns true if the given column is a tab stop.
 func (ts TabStops) IsStop(col int) bool {
 	mask := ts.mask(col)
-	i := col >> 3
+	i := col / tabStopsPerWord
 	if i < 0 || i >= len(ts.stops) {
 		return false
 	}
@@ -106,13 +110,13 @@ func (ts TabStops) Find(col, delta int) int {
 // Set adds a tab stop at the given column.
 func (ts *TabStops) Set(col int) {
 	mask := ts.mask(col)
-	ts.stops[col>>3] |= mask
+	ts.stops[col/tabStopsPerWord] |= mask
 }
 
 // Reset removes the tab stop at the given column.
 func (ts *TabStops) Reset(col int) {
 	mask := ts.mask(col)
-	ts.stops[col>>3] &= ^mask
+	ts.stops[col/tabStopsPerWord] &= ^mask
 }
 
 // Clear removes all tab stops.
@@ -122,7 +126,7 @@ func (ts *TabStops) Clear() {
 
 // mask returns the mask for the given column.
 func (ts *TabStops) mask(col int) int {
-	return 1 << (col & (ts.interval - 1))
+	return 1 << (col % tabStopsPerWord)
 }
 
 // init initializes the tab stops starting from col until width.
@@ -143,6 +143,21 @@ func TestTabStopsClear(t *testing.T) {
 	}
 }
 
+func TestTabStopsCustomInterval(t *testing.T) {
+	ts := NewTabStops(20, 4)
+
+	// Setting and resetting stops must not affect other columns.
+	ts.Set(5)
+	ts.Reset(8)
+	ts.Resize(24)
+	for col := 0; col < 24; col++ {
+		want := (col%4 == 0 && col != 8) || col == 5
+		if got := ts.IsStop(col); got != want {
+			t.Errorf("IsStop(%d) = %v, want %v", col, got, want)
+		}
+	}
+}
+
 func TestTabStopsResize(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -228,7 +243,7 @@ func TestTabStopsResize(t *testing.T) {
 			}
 
 			// Verify stops slice has correct length
-			expectedStopsLen := (tt.newSize + (ts.interval - 1)) / ts.interval
+			expectedStopsLen := (tt.newSize + (tabStopsPerWord - 1)) / tabStopsPerWord
 			if len(ts.stops) != expectedStopsLen {
 				t.Errorf("stops slice length = %d, want %d",
 					len(ts.stops), expectedStopsLen)
@@ -2,6 +2,10 @@ package cellbuf
 
 import (
 	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
 )
 
 // Height returns the height of a string.
@@ -9,6 +13,23 @@ func Height(s string) int {
 	return strings.Count(s, "\n") + 1
 }
 
+// RuneWidth returns the width of a rune in cells using the given method,
+// [runewidth.RuneWidth] for [ansi.WcWidth] and [uniseg.StringWidth]
+// otherwise.
+func RuneWidth(m ansi.Method, r rune) int {
+	if m == ansi.WcWidth {
+		return runewidth.RuneWidth(r)
+	}
+	return uniseg.StringWidth(string(r))
+}
+
+// StringWidth returns the width of a string in cells using the given method.
+// ANSI escape codes are ignored. See [ansi.StringWidth] and
+// [ansi.StringWidthWc].
+func StringWidth(m ansi.Method, s string) int {
+	return m.StringWidth(s)
+}
+
 func min(a, b int) int { //nolint:predeclared
 	if a > b {
 		return b
@@ -0,0 +1,61 @@
+package cellbuf
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestRuneWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		r        rune
+		wc       int
+		grapheme int
+	}{
+		{"ascii", 'a', 1, 1},
+		{"wide", '你', 2, 2},
+		{"emoji", '👋', 2, 2},
+		{"ambiguous", '★', 1, 1},
+		{"combining", '́', 0, 0},
+		{"regional indicator", '🇸', 1, 2},
+		{"control", '\t', 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RuneWidth(ansi.WcWidth, tt.r); got != tt.wc {
+				t.Errorf("RuneWidth(WcWidth, %q) = %d, want %d", tt.r, got, tt.wc)
+			}
+			if got := RuneWidth(ansi.GraphemeWidth, tt.r); got != tt.grapheme {
+				t.Errorf("RuneWidth(GraphemeWidth, %q) = %d, want %d", tt.r, got, tt.grapheme)
+			}
+		})
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		wc       int
+		grapheme int
+	}{
+		{"ascii", "hello", 5, 5},
+		{"wide", "你好", 4, 4},
+		{"styled", "\x1b[31m你好\x1b[m", 4, 4},
+		{"combining", "é", 1, 1},
+		{"flag", "🇸🇦", 1, 2},
+		{"emoji presentation", "❤️", 1, 2},
+		{"zwj sequence", "👨‍👩‍👦", 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StringWidth(ansi.WcWidth, tt.s); got != tt.wc {
+				t.Errorf("StringWidth(WcWidth, %q) = %d, want %d", tt.s, got, tt.wc)
+			}
+			if got := StringWidth(ansi.GraphemeWidth, tt.s); got != tt.grapheme {
+				t.Errorf("StringWidth(GraphemeWidth, %q) = %d, want %d", tt.s, got, tt.grapheme)
+			}
+		})
+	}
+}
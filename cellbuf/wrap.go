@@ -0,0 +1,88 @@
+package cellbuf
+
+import (
+	"io"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/ansi/parser"
+)
+
+// NewWrapWriter returns a writer that wraps the text written to it to limit
+// cells with [ansi.Wrap] and writes the result to w. Lines are written once
+// complete, so escape sequences, grapheme clusters, and words split across
+// calls to Write are wrapped as if written at once. Close writes the last
+// incomplete line, it doesn't close w.
+func NewWrapWriter(w io.Writer, limit int) io.WriteCloser {
+	return &wrapWriter{w: w, limit: limit}
+}
+
+// wrapWriter is a [io.WriteCloser] that wraps complete lines.
+type wrapWriter struct {
+	w     io.Writer
+	limit int
+	buf   []byte // the incomplete line
+
+	// The scanner state of buf, to only break lines at line feeds outside
+	// of escape sequences, where [ansi.Wrap] starts over.
+	scanned int          // the number of bytes of buf scanned
+	state   parser.State // the parser state at scanned
+	rest    int          // the remaining bytes of the rune at scanned
+}
+
+// Write implements [io.Writer].
+func (w *wrapWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	end := 0
+	for ; w.scanned < len(w.buf); w.scanned++ {
+		b := w.buf[w.scanned]
+		if w.rest > 0 {
+			w.rest--
+			continue
+		}
+
+		state, action := parser.Table.Transition(w.state, b)
+		switch {
+		case state == parser.Utf8State:
+			// Skip the rest of the rune.
+			switch {
+			case b >= 0xF0:
+				w.rest = 3
+			case b >= 0xE0:
+				w.rest = 2
+			default:
+				w.rest = 1
+			}
+			state = parser.GroundState
+		case b == '\n' && action == parser.ExecuteAction && w.state == parser.GroundState:
+			end = w.scanned + 1
+		}
+		w.state = state
+	}
+
+	if end > 0 {
+		if err := w.flush(end); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Close implements [io.Closer]. It writes the last incomplete line.
+func (w *wrapWriter) Close() error {
+	return w.flush(len(w.buf))
+}
+
+// flush wraps and writes the first n bytes of the buffer.
+func (w *wrapWriter) flush(n int) error {
+	if n == 0 {
+		return nil
+	}
+	if _, err := io.WriteString(w.w, ansi.Wrap(string(w.buf[:n]), w.limit, "")); err != nil {
+		return err
+	}
+	w.buf = w.buf[:copy(w.buf, w.buf[n:])]
+	w.scanned -= n
+	return nil
+}
@@ -0,0 +1,59 @@
+package cellbuf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestWrapWriter(t *testing.T) {
+	const input = "\x1b[1;38;2;249;38;114mThe quick brown\x1b[m fox jumps over the " +
+		"\x1b]8;;https://example.com\x1b\\lazy\x1b]8;;\x1b\\ dog.\n" +
+		"你好世界 👨‍👩‍👦 étude, well-known/long-path\n\n" +
+		"  indented    trailing   \nno final line feed"
+	const limit = 10
+	want := ansi.Wrap(input, limit, "")
+
+	for size := 1; size <= len(input); size++ {
+		var buf bytes.Buffer
+		w := NewWrapWriter(&buf, limit)
+		for i := 0; i < len(input); i += size {
+			n, err := w.Write([]byte(input[i:min(i+size, len(input))]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != min(size, len(input)-i) {
+				t.Fatalf("expected to write %d bytes, wrote %d", min(size, len(input)-i), n)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != want {
+			t.Fatalf("writing chunks of %d bytes:\nexpected %q\ngot      %q", size, want, got)
+		}
+	}
+}
+
+func TestWrapWriterCompleteLines(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWrapWriter(&buf, 4)
+	w.Write([]byte("foo bar\nbaz")) //nolint:errcheck
+	if got, want := buf.String(), "foo\nbar\n"; got != want {
+		t.Errorf("expected %q before closing, got %q", want, got)
+	}
+
+	// A line feed in an escape sequence doesn't complete the line.
+	w.Write([]byte("\x1b]2;a\nb")) //nolint:errcheck
+	if got, want := buf.String(), "foo\nbar\n"; got != want {
+		t.Errorf("expected %q before closing, got %q", want, got)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "foo\nbar\nbaz\x1b]2;a\nb"; got != want {
+		t.Errorf("expected %q after closing, got %q", want, got)
+	}
+}
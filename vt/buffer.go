@@ -4,3 +4,6 @@ import "github.com/charmbracelet/x/cellbuf"
 
 // Buffer is a 2D grid of cells representing a screen or terminal.
 type Buffer = cellbuf.Buffer
+
+// Line represents a line in the terminal.
+type Line = cellbuf.Line
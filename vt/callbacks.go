@@ -2,7 +2,10 @@ package vt
 
 import "github.com/charmbracelet/x/cellbuf"
 
-// Callbacks represents a set of callbacks for a terminal.
+// Callbacks represents a set of callbacks for a terminal. They're called
+// while the terminal is locked, so they must not call the terminal methods
+// that lock it, such as [Terminal.Write], [Terminal.Render], or the viewport
+// scrolling methods.
 type Callbacks struct {
 	// Bell callback. When set, this function is called when a bell character is
 	// received.
@@ -24,6 +27,12 @@ type Callbacks struct {
 	// screen is activated or deactivated.
 	AltScreen func(bool)
 
+	// PrivateMode callback. When set, this function is called when a DEC
+	// private mode is set or reset with [ansi.SM] or [ansi.RM], after
+	// the terminal state is updated. It's called for unknown modes too, which
+	// lets applications observe and extend the supported modes.
+	PrivateMode func(mode int, set bool)
+
 	// CursorPosition callback. When set, this function is called when the cursor
 	// position changes.
 	CursorPosition func(old, new cellbuf.Position) //nolint:predeclared
@@ -35,4 +44,9 @@ type Callbacks struct {
 	// CursorStyle callback. When set, this function is called when the cursor
 	// style changes.
 	CursorStyle func(style CursorStyle, blink bool)
+
+	// Response callback. When set, this function is called with the replies
+	// to the host queries, such as [ansi.DA1] and [ansi.CPR], instead of
+	// writing them to the input buffer read with [Terminal.Read].
+	Response func([]byte)
 }
@@ -37,6 +37,8 @@ func (t *Terminal) handleControl(r byte) {
 		t.gl = 0
 	case ansi.IND: // Index [ansi.IND]
 		t.index()
+	case ansi.NEL: // Next Line [ansi.NEL]
+		t.nextLine()
 	case ansi.SS2: // Single Shift 2 [ansi.SS2]
 		t.gsingle = 2
 	case ansi.SS3: // Single Shift 3 [ansi.SS3]
@@ -46,10 +48,17 @@ func (t *Terminal) handleControl(r byte) {
 	}
 }
 
+// NewLineMode reports whether line feeds also move the cursor to the start of
+// the line, and the Enter key sends a carriage return and a line feed, see
+// [ansi.LNM].
+func (t *Terminal) NewLineMode() bool {
+	return t.isModeSet(ansi.LineFeedNewLineMode)
+}
+
 // linefeed is the same as [index], except that it respects [ansi.LNM] mode.
 func (t *Terminal) linefeed() {
 	t.index()
-	if t.isModeSet(ansi.LineFeedNewLineMode) {
+	if t.NewLineMode() {
 		t.carriageReturn()
 	}
 }
@@ -59,8 +68,13 @@ func (t *Terminal) linefeed() {
 func (t *Terminal) index() {
 	x, y := t.scr.CursorPosition()
 	scroll := t.scr.ScrollRegion()
-	// TODO: Handle scrollback whenever we add it.
 	if y == scroll.Max.Y-1 && x >= scroll.Min.X && x < scroll.Max.X {
+		// Only lines leaving the top of the main screen go to the scrollback,
+		// lines scrolled out of a partial scroll region are lost.
+		if t.scr == &t.scrs[0] && scroll.Min.Y == 0 &&
+			scroll.Min.X == 0 && scroll.Max.X == t.scr.Width() {
+			t.scrollback.push(t.scr.cloneLine(0), t.scr.isWrapped(0))
+		}
 		t.scr.ScrollUp(1)
 	} else if y < scroll.Max.Y-1 || !cellbuf.Pos(x, y).In(scroll) {
 		t.scr.moveCursor(0, 1)
@@ -68,10 +82,21 @@ func (t *Terminal) index() {
 	t.atPhantom = false
 }
 
+// nextLine moves the cursor to the start of the next line, scrolling up if
+// necessary, regardless of [ansi.LNM] mode.
+func (t *Terminal) nextLine() {
+	t.index()
+	t.carriageReturn()
+}
+
 // horizontalTabSet sets a horizontal tab stop at the current cursor position.
 func (t *Terminal) horizontalTabSet() {
 	x, _ := t.scr.CursorPosition()
 	t.tabstops.Set(x)
+	if t.htsStops == nil {
+		t.htsStops = map[int]struct{}{}
+	}
+	t.htsStops[x] = struct{}{}
 }
 
 // reverseIndex moves the cursor up one line, or scrolling down. This does not
@@ -84,4 +109,5 @@ func (t *Terminal) reverseIndex() {
 	} else {
 		t.scr.moveCursor(0, -1)
 	}
+	t.atPhantom = false
 }
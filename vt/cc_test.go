@@ -0,0 +1,93 @@
+package vt
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestBell(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{"standalone", "\a", 1},
+		{"between text", "a\ab\a", 2},
+		{"OSC terminator", ansi.SetWindowTitle("title"), 0},
+		{"after OSC", ansi.SetWindowTitle("title") + "\a", 1},
+		{"cancelled OSC", "\x1b]2;title\x18\a", 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 10, 2)
+			var bells int
+			term.Callbacks.Bell = func() { bells++ }
+			term.Write([]byte(tc.input))
+			if bells != tc.want {
+				t.Errorf("want %d bells, got %d", tc.want, bells)
+			}
+		})
+	}
+}
+
+func TestNewLineMode(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  Position
+	}{
+		{"LF", "ab\n", Position{X: 2, Y: 1}},
+		{"LF with LNM", ansi.SetLineFeedNewLineMode + "ab\n", Position{X: 0, Y: 1}},
+		{"VT with LNM", ansi.SetLineFeedNewLineMode + "ab\v", Position{X: 0, Y: 1}},
+		{"FF with LNM", ansi.SetLineFeedNewLineMode + "ab\f", Position{X: 0, Y: 1}},
+		{"LNM reset", ansi.SetLineFeedNewLineMode + ansi.ResetLineFeedNewLineMode + "ab\n", Position{X: 2, Y: 1}},
+		{"IND with LNM", ansi.SetLineFeedNewLineMode + "ab\x1bD", Position{X: 2, Y: 1}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 10, 3)
+			term.Write([]byte(tc.input))
+			if pos := term.CursorPosition(); pos != tc.want {
+				t.Errorf("want cursor at %v, got %v", tc.want, pos)
+			}
+		})
+	}
+
+	term := newTestTerminal(t, 10, 3)
+	term.SendKeys(Key{Code: KeyEnter}, Key{Code: KeyKpEnter})
+	if got, want := readString(&term.buf), "\r\r"; got != want {
+		t.Errorf("want Enter to send %q, got %q", want, got)
+	}
+	term.Write([]byte(ansi.SetLineFeedNewLineMode))
+	if !term.NewLineMode() {
+		t.Error("expected new line mode to be set")
+	}
+	term.SendKeys(Key{Code: KeyEnter}, Key{Code: KeyKpEnter})
+	if got, want := readString(&term.buf), "\r\n\r\n"; got != want {
+		t.Errorf("want Enter to send %q, got %q", want, got)
+	}
+}
+
+func TestUTF8C1Controls(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"NEL", "ab\u0085cd", []string{"ab    ", "cd    "}},
+		{"CSI", "abc\u009b2Dx", []string{"axc   ", "      "}},
+		{"OSC", "a\u009d2;title\u009cb", []string{"ab    ", "      "}},
+		{"after grapheme", "é\u0085x", []string{"é     ", "x     "}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 6, 2)
+			term.Write([]byte(tc.input))
+			assertLines(t, termText(term), tc.want)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package vt
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/x/cellbuf"
 )
 
@@ -18,3 +20,26 @@ type Rectangle = cellbuf.Rectangle
 
 // Position represents a position in the terminal screen.
 type Position = cellbuf.Position
+
+// cellText returns the plain text of the cell. Concealed cells are replaced
+// with spaces, see [cellbuf.ConcealAttr].
+func cellText(c *Cell) string {
+	if c.Style.Attrs&cellbuf.ConcealAttr != 0 && c.Width > 0 {
+		return strings.Repeat(" ", c.Width)
+	}
+	return c.String()
+}
+
+// lineText returns the plain text of the line like [cellbuf.Line.String],
+// with the concealed cells replaced with spaces.
+func lineText(l Line) string {
+	var b strings.Builder
+	for _, c := range l {
+		if c == nil {
+			b.WriteByte(' ')
+		} else if !c.Empty() {
+			b.WriteString(cellText(c))
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
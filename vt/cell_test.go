@@ -0,0 +1,169 @@
+package vt
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+func TestTerminalCell(t *testing.T) {
+	term := newTestTerminal(t, 6, 2)
+	term.Write([]byte("\x1b[1;31m" + ansi.SetHyperlink("https://example.com", "id=1") + "a" +
+		ansi.ResetHyperlink() + "\x1b[m你b"))
+
+	cell, ok := term.Cell(0, 0)
+	if !ok {
+		t.Fatal("expected cell at 0,0 to be in bounds")
+	}
+	if cell.String() != "a" || cell.Width != 1 {
+		t.Errorf("expected cell at 0,0 to be %q, got %q", "a", cell.String())
+	}
+	if cell.Style.Fg != ansi.Red || cell.Style.Attrs&cellbuf.BoldAttr == 0 {
+		t.Errorf("expected cell at 0,0 to be bold and red, got %#v", cell.Style)
+	}
+	if cell.Link.URL != "https://example.com" || cell.Link.Params != "id=1" {
+		t.Errorf("expected cell at 0,0 to link to %q, got %#v", "https://example.com", cell.Link)
+	}
+
+	cell, ok = term.Cell(1, 0)
+	if !ok || cell.String() != "你" || cell.Width != 2 {
+		t.Errorf("expected wide cell at 1,0, got %#v", cell)
+	}
+	if !cell.Style.Empty() || !cell.Link.Empty() {
+		t.Errorf("expected cell at 1,0 to have no style or link, got %#v", cell)
+	}
+
+	// The cell following a wide character is an empty continuation cell.
+	cell, ok = term.Cell(2, 0)
+	if !ok || cell.String() != "" || cell.Width != 0 {
+		t.Errorf("expected continuation cell at 2,0, got %#v", cell)
+	}
+
+	cell, ok = term.Cell(3, 0)
+	if !ok || cell.String() != "b" {
+		t.Errorf("expected cell at 3,0 to be %q, got %q", "b", cell.String())
+	}
+
+	cell, ok = term.Cell(0, 1)
+	if !ok || !cell.Equal(&cellbuf.BlankCell) {
+		t.Errorf("expected blank cell at 0,1, got %#v", cell)
+	}
+
+	for _, pos := range []Position{{X: -1, Y: 0}, {X: 6, Y: 0}, {X: 0, Y: 2}} {
+		if _, ok := term.Cell(pos.X, pos.Y); ok {
+			t.Errorf("expected cell at %v to be out of bounds", pos)
+		}
+	}
+}
+
+func TestTerminalCellHyperlink(t *testing.T) {
+	term := newTestTerminal(t, 8, 1)
+	term.Write([]byte("a\x1b]8;id=x;https://example.com/a;b\x1b\\link\x1b]8;id=x;\x1b\\b"))
+
+	for x, want := range []string{"", "https://example.com/a;b", "https://example.com/a;b",
+		"https://example.com/a;b", "https://example.com/a;b", "", ""} {
+		cell, _ := term.Cell(x, 0)
+		if cell.Link.URL != want {
+			t.Errorf("cell %d: expected link %q, got %q", x, want, cell.Link.URL)
+		}
+		if want == "" && !cell.Link.Empty() {
+			t.Errorf("cell %d: expected no link, got %#v", x, cell.Link)
+		}
+		if want != "" && cell.Link.Params != "id=x" {
+			t.Errorf("cell %d: expected link params %q, got %q", x, "id=x", cell.Link.Params)
+		}
+	}
+}
+
+func TestTerminalConcealedText(t *testing.T) {
+	term := newTestTerminal(t, 10, 2)
+	term.Write([]byte("pw: \x1b[2;8mse\u4f60\x1b[28m!\r\n\x1b[8mhidden"))
+	if got, want := term.String(), "pw:     !\n"; got != want {
+		t.Errorf("want text %q, got %q", want, got)
+	}
+
+	// The cells keep their content.
+	if cell, _ := term.Cell(4, 0); cell.String() != "s" || cell.Style.Attrs != cellbuf.FaintAttr|cellbuf.ConcealAttr {
+		t.Errorf("want a faint concealed %q, got %#v", "s", cell)
+	}
+}
+
+func TestTerminalWideCellOverwrite(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []string // "_" marks a wide cell placeholder
+	}{
+		{"wide", "你", []string{"你", "_", " ", " ", " ", " "}},
+		{"left half", "你\x1b[1;1Ha", []string{"a", " ", " ", " ", " ", " "}},
+		{"right half", "你\x1b[1;2Hb", []string{" ", "b", " ", " ", " ", " "}},
+		{"wide over right half", "a你\x1b[1;1H好", []string{"好", "_", " ", " ", " ", " "}},
+		{"wide over two halves", "你好\x1b[1;2H世", []string{" ", "世", "_", " ", " ", " "}},
+		{"wide over left half", "a你\x1b[1;3H好", []string{"a", " ", "好", "_", " ", " "}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 6, 1)
+			term.Write([]byte(tc.input))
+
+			var got []string
+			for x := 0; x < term.Width(); x++ {
+				cell, _ := term.Cell(x, 0)
+				if cell.Width == 0 {
+					got = append(got, "_")
+				} else {
+					got = append(got, cell.String())
+				}
+			}
+			assertLines(t, got, tc.want)
+		})
+	}
+}
+
+func TestTerminalCombining(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []string // "_" marks a wide cell placeholder
+		pos   Position
+	}{
+		{"combining mark", "a\u0301", []string{"a\u0301", " ", " ", " "}, cellbuf.Pos(1, 0)},
+		{"followed by text", "a\u0301b", []string{"a\u0301", "b", " ", " "}, cellbuf.Pos(2, 0)},
+		{"several marks", "a\u0323\u0301", []string{"a\u0323\u0301", " ", " ", " "}, cellbuf.Pos(1, 0)},
+		{"styled base", "a\x1b[1mb\u0301", []string{"a", "b\u0301", " ", " "}, cellbuf.Pos(2, 0)},
+		{"wide base", "\u4f60\u0301x", []string{"\u4f60\u0301", "_", "x", " "}, cellbuf.Pos(3, 0)},
+		{"pending wrap", "abcd\u0301", []string{"a", "b", "c", "d\u0301"}, cellbuf.Pos(3, 0)},
+		{"no base", "\u0301b", []string{"\u0301", "b", " ", " "}, cellbuf.Pos(2, 0)},
+		{"grapheme clustering", "\x1b[?2027ha\u0301b", []string{"a\u0301", "b", " ", " "}, cellbuf.Pos(2, 0)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 4, 1)
+			term.Write([]byte(tc.input))
+
+			var got []string
+			for x := 0; x < term.Width(); x++ {
+				cell, _ := term.Cell(x, 0)
+				if cell.Width == 0 {
+					got = append(got, "_")
+				} else {
+					got = append(got, cell.String())
+				}
+			}
+			assertLines(t, got, tc.want)
+			if pos := term.CursorPosition(); pos != tc.pos {
+				t.Errorf("want cursor at %v, got %v", tc.pos, pos)
+			}
+		})
+	}
+
+	// The base keeps its width.
+	term := newTestTerminal(t, 4, 1)
+	term.Write([]byte("a\u0301"))
+	if cell, _ := term.Cell(0, 0); cell.Width != 1 {
+		t.Errorf("want width 1, got %d", cell.Width)
+	}
+}
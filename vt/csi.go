@@ -13,7 +13,7 @@ func (t *Terminal) handleCsi(cmd ansi.Cmd, params ansi.Params) {
 	case ansi.Command(0, 0, 0):
 	}
 	if !t.handlers.handleCsi(cmd, params) {
-		t.logf("unhandled sequence: CSI %q", paramsString(cmd, params))
+		t.unhandled(fmt.Sprintf("CSI %q", paramsString(cmd, params)))
 	}
 }
 
@@ -29,7 +29,39 @@ func (t *Terminal) handleRequestMode(params ansi.Params, isAnsi bool) {
 	}
 
 	setting := t.modes[mode]
-	t.buf.WriteString(ansi.ReportMode(mode, setting))
+	t.respond(ansi.ReportMode(mode, setting))
+}
+
+// softReset performs a soft terminal reset as in [ansi.DECSTR]. It resets the
+// modes, margins, graphic rendition, character sets, and saved cursor, but
+// keeps the screen content and the cursor position.
+func (t *Terminal) softReset() {
+	for mode, setting := range map[ansi.Mode]ansi.ModeSetting{
+		ansi.InsertReplaceMode: ansi.ModeReset,
+		ansi.OriginMode:        ansi.ModeReset,
+		ansi.AutoWrapMode:      ansi.ModeSet,
+		ansi.CursorKeysMode:    ansi.ModeReset,
+		ansi.NumericKeypadMode: ansi.ModeReset,
+	} {
+		// Set the modes directly as setting them through [Terminal.setMode]
+		// would move the cursor.
+		t.modes[mode] = setting
+	}
+	t.setMode(ansi.TextCursorEnableMode, ansi.ModeSet)
+
+	t.scr.setHorizontalMargins(0, t.Width())
+	t.scr.setVerticalMargins(0, t.Height())
+
+	t.scr.cur.Pen = Style{}
+	t.scr.cur.Link = Link{}
+	t.scr.saved = t.scr.cur
+	t.scr.saved.Position = Position{}
+	t.scr.savedState = nil
+
+	t.gl, t.gr = 0, 1
+	t.gsingle = 0
+	t.charsets = [4]CharSet{}
+	t.atPhantom = false
 }
 
 func paramsString(cmd ansi.Cmd, params ansi.Params) string {
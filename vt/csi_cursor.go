@@ -10,6 +10,11 @@ import (
 func (t *Terminal) nextTab(n int) {
 	x, y := t.scr.CursorPosition()
 	scroll := t.scr.ScrollRegion()
+	// The right margin only applies when the cursor starts inside it.
+	rightMargin := t.Width()
+	if x < scroll.Max.X {
+		rightMargin = min(scroll.Max.X, rightMargin)
+	}
 	for i := 0; i < n; i++ {
 		ts := t.tabstops.Next(x)
 		if ts < x {
@@ -18,8 +23,8 @@ func (t *Terminal) nextTab(n int) {
 		x = ts
 	}
 
-	if x >= scroll.Max.X {
-		x = min(scroll.Max.X-1, t.Width()-1)
+	if x >= rightMargin {
+		x = rightMargin - 1
 	}
 
 	// NOTE: We use t.scr.setCursor here because we don't want to reset the
@@ -78,6 +83,20 @@ func (t *Terminal) setCursorPosition(x, y int) {
 	t.atPhantom = false
 }
 
+// relativeCursorPosition returns the cursor position relative to the top-left
+// of the scroll region when [ansi.DECOM], Origin Mode, is set. Otherwise, it
+// returns the absolute cursor position. This is the position that
+// [Terminal.setCursorPosition] expects.
+func (t *Terminal) relativeCursorPosition() (x, y int) {
+	x, y = t.scr.CursorPosition()
+	if t.isModeSet(ansi.DECOM) {
+		scroll := t.scr.ScrollRegion()
+		x -= scroll.Min.X
+		y -= scroll.Min.Y
+	}
+	return x, y
+}
+
 // carriageReturn moves the cursor to the leftmost column. If [ansi.DECOM] is
 // set, the cursor is set to the left margin. If not, and the cursor is on or
 // to the right of the left margin, the cursor is set to the left margin.
@@ -87,9 +106,11 @@ func (t *Terminal) carriageReturn() {
 	mode, ok := t.modes[ansi.DECOM]
 	margins := ok && mode.IsSet()
 	x, y := t.scr.CursorPosition()
+	region := t.scr.ScrollRegion()
 	if margins {
-		t.scr.setCursor(0, y, true)
-	} else if region := t.scr.ScrollRegion(); cellbuf.Pos(x, y).In(region) {
+		// The position is relative to the margins.
+		t.scr.setCursor(0, y-region.Min.Y, true)
+	} else if cellbuf.Pos(x, y).In(region) {
 		t.scr.setCursor(region.Min.X, y, false)
 	} else {
 		t.scr.setCursor(0, y, false)
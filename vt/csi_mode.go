@@ -29,11 +29,18 @@ func (t *Terminal) handleMode(params ansi.Params, set, isAnsi bool) {
 		}
 
 		t.setMode(mode, setting)
+		if !isAnsi && t.Callbacks.PrivateMode != nil {
+			t.Callbacks.PrivateMode(param, set)
+		}
 	}
 }
 
 // setAltScreenMode sets the alternate screen mode.
 func (t *Terminal) setAltScreenMode(on bool) {
+	if on == (t.scr == &t.scrs[1]) {
+		// Already on the requested screen.
+		return
+	}
 	if on {
 		t.scr = &t.scrs[1]
 		t.scrs[1].cur = t.scrs[0].cur
@@ -41,20 +48,67 @@ func (t *Terminal) setAltScreenMode(on bool) {
 		t.setCursor(0, 0)
 	} else {
 		t.scr = &t.scrs[0]
+		t.scr.damage(ScreenDamage{t.Width(), t.Height()})
 	}
 	if t.Callbacks.AltScreen != nil {
 		t.Callbacks.AltScreen(on)
 	}
 }
 
-// saveCursor saves the cursor position.
+// setSyncMode starts or ends a synchronized update. The damaged areas are held
+// during the update and reported to the damage callback once it ends.
+func (t *Terminal) setSyncMode(on bool) {
+	if on {
+		t.damage.startSync()
+		return
+	}
+	for _, d := range t.damage.endSync(t.Width(), t.Height()) {
+		if t.Callbacks.Damage != nil {
+			t.Callbacks.Damage(d)
+		}
+	}
+	if from, ok := t.damage.heldCursorMove(); ok && t.Callbacks.CursorPosition != nil {
+		if to := t.CursorPosition(); to != from {
+			t.Callbacks.CursorPosition(from, to)
+		}
+	}
+}
+
+// saveCursor saves the cursor position and pen, along with the origin mode
+// and the character sets.
 func (t *Terminal) saveCursor() {
 	t.scr.SaveCursor()
+	t.scr.mu.Lock()
+	t.scr.savedState = &savedCursor{
+		charsets: t.charsets,
+		gl:       t.gl,
+		gr:       t.gr,
+		origin:   t.isModeSet(ansi.OriginMode),
+	}
+	t.scr.mu.Unlock()
 }
 
-// restoreCursor restores the cursor position.
+// restoreCursor restores the state saved by [Terminal.saveCursor]. When
+// nothing was saved, the cursor moves to the top-left of the screen with the
+// default pen, origin mode and character sets.
 func (t *Terminal) restoreCursor() {
 	t.scr.RestoreCursor()
+	t.scr.mu.RLock()
+	st := savedCursor{gr: 1}
+	if t.scr.savedState != nil {
+		st = *t.scr.savedState
+	}
+	t.scr.mu.RUnlock()
+
+	t.charsets, t.gl, t.gr = st.charsets, st.gl, st.gr
+	// Set the mode directly as setting it through [Terminal.setMode] would
+	// move the cursor.
+	if st.origin {
+		t.modes[ansi.OriginMode] = ansi.ModeSet
+	} else {
+		t.modes[ansi.OriginMode] = ansi.ModeReset
+	}
+	t.atPhantom = false
 }
 
 // setMode sets the mode to the given value.
@@ -64,6 +118,17 @@ func (t *Terminal) setMode(mode ansi.Mode, setting ansi.ModeSetting) {
 	switch mode {
 	case ansi.TextCursorEnableMode:
 		t.scr.setCursorHidden(!setting.IsSet())
+	case ansi.AutoWrapMode:
+		if !setting.IsSet() {
+			// Disabling auto wrap cancels any pending wrap.
+			t.atPhantom = false
+		}
+	case ansi.ScreenMode:
+		// The whole screen is redrawn with the colors swapped.
+		t.scr.damage(ScreenDamage{t.Width(), t.Height()})
+	case ansi.OriginMode:
+		// Move the cursor to the top-left of the screen or scroll region.
+		t.setCursorPosition(0, 0)
 	case ansi.AltScreenMode:
 		t.setAltScreenMode(setting.IsSet())
 	case ansi.SaveCursorMode:
@@ -72,14 +137,20 @@ func (t *Terminal) setMode(mode ansi.Mode, setting ansi.ModeSetting) {
 		} else {
 			t.restoreCursor()
 		}
+	case ansi.SynchronizedOutputMode:
+		t.setSyncMode(setting.IsSet())
 	case ansi.AltScreenSaveCursorMode: // Alternate Screen Save Cursor (1047 & 1048)
-		// Save primary screen cursor position
-		// Switch to alternate screen
-		// Doesn't support scrollback
+		// Save the primary screen cursor and switch to a cleared alternate
+		// screen. When reset, switch back to the primary screen and restore
+		// its cursor. The alternate screen doesn't contribute to the
+		// scrollback.
 		if setting.IsSet() {
 			t.saveCursor()
+			t.setAltScreenMode(true)
+		} else {
+			t.setAltScreenMode(false)
+			t.restoreCursor()
 		}
-		t.setAltScreenMode(setting.IsSet())
 	}
 }
 
@@ -1,6 +1,9 @@
 package vt
 
-import "github.com/charmbracelet/x/cellbuf"
+import (
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+)
 
 // eraseCharacter erases n characters starting from the cursor position. It
 // does not move the cursor. This is equivalent to [ansi.ECH].
@@ -11,3 +14,61 @@ func (t *Terminal) eraseCharacter(n int) {
 	t.atPhantom = false
 	// ECH does not move the cursor.
 }
+
+// rectangularArea returns the rectangular area given by the top, left,
+// bottom, and right parameters starting at index i. The area is relative to
+// the scroll region when [ansi.DECOM], Origin Mode, is set, and it is clamped
+// to the screen or the scroll region. It returns false if the area is empty.
+func (t *Terminal) rectangularArea(params ansi.Params, i int) (Rectangle, bool) {
+	bounds := t.scr.Bounds()
+	if t.isModeSet(ansi.DECOM) {
+		bounds = t.scr.ScrollRegion()
+	}
+
+	param := func(i, def int) int {
+		n, _, _ := params.Param(i, def)
+		if n < 1 {
+			n = def
+		}
+		return n
+	}
+	top := param(i, 1)
+	left := param(i+1, 1)
+	bottom := param(i+2, bounds.Dy())
+	right := param(i+3, bounds.Dx())
+	if top > bottom || left > right {
+		return Rectangle{}, false
+	}
+
+	rect := cellbuf.Rect(bounds.Min.X+left-1, bounds.Min.Y+top-1, right-left+1, bottom-top+1)
+	rect = rect.Intersect(bounds)
+	return rect, !rect.Empty()
+}
+
+// fillRectangularArea fills the rectangular area with the given character
+// using the cursor pen. This is equivalent to [ansi.DECFRA].
+func (t *Terminal) fillRectangularArea(c rune, rect Rectangle) {
+	cell := cellbuf.NewCell(c)
+	cell.Style = t.scr.cursorPen()
+	t.scr.Fill(cell, rect)
+}
+
+// eraseRectangularArea erases the rectangular area. This is equivalent to
+// [ansi.DECERA].
+func (t *Terminal) eraseRectangularArea(rect Rectangle) {
+	t.scr.Fill(t.scr.blankCell(), rect)
+}
+
+// scrollUp scrolls the content of the scroll region up n lines. The lines
+// leaving the top of the main screen go to the scrollback like with
+// [Terminal.index]. This is equivalent to [ansi.SU].
+func (t *Terminal) scrollUp(n int) {
+	scroll := t.scr.ScrollRegion()
+	if t.scr == &t.scrs[0] && scroll.Min.Y == 0 &&
+		scroll.Min.X == 0 && scroll.Max.X == t.scr.Width() {
+		for y := 0; y < min(n, scroll.Max.Y); y++ {
+			t.scrollback.push(t.scr.cloneLine(y), t.scr.isWrapped(y))
+		}
+	}
+	t.scr.ScrollUp(n)
+}
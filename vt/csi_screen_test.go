@@ -0,0 +1,116 @@
+package vt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+func TestRectangularArea(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "fill",
+			input: "\x1b[88;2;2;3;4$x",
+			want:  []string{"abcde", "fXXXj", "kXXXo", "pqrst"},
+		},
+		{
+			name:  "fill clamped",
+			input: "\x1b[88;3;4;9;9$x",
+			want:  []string{"abcde", "fghij", "klmXX", "pqrXX"},
+		},
+		{
+			name:  "fill invalid character",
+			input: "\x1b[7;1;1;2;2$x",
+			want:  []string{"abcde", "fghij", "klmno", "pqrst"},
+		},
+		{
+			name:  "erase",
+			input: "\x1b[1;2;2;3$z",
+			want:  []string{"a  de", "f  ij", "klmno", "pqrst"},
+		},
+		{
+			name:  "erase inverted",
+			input: "\x1b[3;1;1;2$z",
+			want:  []string{"abcde", "fghij", "klmno", "pqrst"},
+		},
+		{
+			name:  "copy",
+			input: "\x1b[1;1;2;2;1;3;4;1$v",
+			want:  []string{"abcde", "fghij", "klmab", "pqrfg"},
+		},
+		{
+			name:  "copy overlapping",
+			input: "\x1b[1;1;3;3;1;2;2;1$v",
+			want:  []string{"abcde", "fabcj", "kfgho", "pklmt"},
+		},
+		{
+			name:  "copy past the screen",
+			input: "\x1b[1;1;2;3;1;4;4;1$v",
+			want:  []string{"abcde", "fghij", "klmno", "pqrab"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 5, 4)
+			term.Write([]byte("abcde\r\nfghij\r\nklmno\r\npqrst"))
+			term.Write([]byte(tc.input))
+			assertLines(t, termText(term), tc.want)
+		})
+	}
+}
+
+func TestFillRectangularAreaPen(t *testing.T) {
+	term := newTestTerminal(t, 3, 2)
+	term.Write([]byte("\x1b[1;31m\x1b[42;1;1;1;2$x\x1b[m"))
+	for x := 0; x < 2; x++ {
+		c, _ := term.Cell(x, 0)
+		if c.Rune != '*' || c.Style.Attrs&cellbuf.BoldAttr == 0 || c.Style.Fg != ansi.Red {
+			t.Errorf("cell %d: expected a bold red '*', got %#v", x, c)
+		}
+	}
+	if c, _ := term.Cell(2, 0); c.Rune == '*' {
+		t.Errorf("cell 2: expected no fill, got %#v", c)
+	}
+}
+
+func TestEditingRightMarginPastScreen(t *testing.T) {
+	// The right margin is past the right edge of the screen.
+	for _, tc := range []struct {
+		input string
+		want  string
+	}{
+		{"\x1b[1;2H\x1b[99@", "a"},
+		{"\x1b[1;2H\x1b[2P", "ade"},
+	} {
+		term := newTestTerminal(t, 5, 1)
+		term.Write([]byte("abcde\x1b[?69h\x1b[1;37s" + tc.input))
+		assertLines(t, termText(term), []string{tc.want + strings.Repeat(" ", 5-len(tc.want))})
+	}
+}
+
+func TestEditingZeroCount(t *testing.T) {
+	// A zero count is treated like the default of one.
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"ICH", "\x1b[1;2H\x1b[0@", []string{"a bc", "de  "}},
+		{"DCH", "\x1b[1;2H\x1b[0P", []string{"ac  ", "de  "}},
+		{"IL", "\x1b[1;1H\x1b[0L", []string{"    ", "abc "}},
+		{"DL", "\x1b[1;1H\x1b[0M", []string{"de  ", "    "}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 4, 2)
+			term.Write([]byte("abc\r\nde" + tc.input))
+			assertLines(t, termText(term), tc.want)
+		})
+	}
+}
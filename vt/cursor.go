@@ -21,3 +21,11 @@ type Cursor struct {
 	Steady bool // Not blinking
 	Hidden bool
 }
+
+// savedCursor is the terminal state saved along with the cursor by
+// [ansi.DECSC], see [Terminal.saveCursor].
+type savedCursor struct {
+	charsets [4]CharSet
+	gl, gr   int
+	origin   bool
+}
@@ -0,0 +1,258 @@
+package vt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+func TestCursorVisible(t *testing.T) {
+	term := newTestTerminal(t, 10, 2)
+	if !term.CursorVisible() {
+		t.Fatal("expected cursor to be visible by default")
+	}
+
+	term.Write([]byte(ansi.HideCursor))
+	if term.CursorVisible() {
+		t.Error("expected cursor to be hidden")
+	}
+
+	// The cursor visibility is global to both screens.
+	term.Write([]byte(ansi.SetAltScreenSaveCursorMode))
+	if term.CursorVisible() {
+		t.Error("expected cursor to stay hidden on the alternate screen")
+	}
+	term.Write([]byte(ansi.ShowCursor + ansi.ResetAltScreenSaveCursorMode))
+	if !term.CursorVisible() {
+		t.Error("expected cursor to be visible")
+	}
+}
+
+func TestCursorStyle(t *testing.T) {
+	cases := []struct {
+		seq   string
+		style CursorStyle
+		blink bool
+	}{
+		{"\x1b[ q", CursorBlock, true},
+		{"\x1b[0 q", CursorBlock, true},
+		{"\x1b[1 q", CursorBlock, true},
+		{"\x1b[2 q", CursorBlock, false},
+		{"\x1b[3 q", CursorUnderline, true},
+		{"\x1b[4 q", CursorUnderline, false},
+		{"\x1b[5 q", CursorBar, true},
+		{"\x1b[6 q", CursorBar, false},
+	}
+
+	for _, tc := range cases {
+		term := newTestTerminal(t, 10, 2)
+		// Start from a non-default style.
+		term.Write([]byte("\x1b[6 q"))
+		term.Write([]byte(tc.seq))
+		if got := term.CursorStyle(); got != tc.style {
+			t.Errorf("%q: want style %v, got %v", tc.seq, tc.style, got)
+		}
+		if got := term.CursorBlink(); got != tc.blink {
+			t.Errorf("%q: want blink %v, got %v", tc.seq, tc.blink, got)
+		}
+	}
+}
+
+func TestCursorStyleInvalid(t *testing.T) {
+	term := newTestTerminal(t, 10, 2)
+	term.Write([]byte("\x1b[4 q\x1b[7 q"))
+	if term.CursorStyle() != CursorUnderline || term.CursorBlink() {
+		t.Errorf("expected invalid style to be ignored, got %v (blink %v)", term.CursorStyle(), term.CursorBlink())
+	}
+}
+
+func TestCursorPositionReportOriginMode(t *testing.T) {
+	term := newTestTerminal(t, 10, 5)
+	term.Write([]byte("\x1b[2;4r\x1b[?6h\x1b[2;3H\x1b[6n"))
+	if got, want := term.buf.String(), "\x1b[2;3R"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	term.buf.Reset()
+	term.Write([]byte("\x1b[?6l\x1b[2;3H\x1b[6n"))
+	if got, want := term.buf.String(), "\x1b[2;3R"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if pos := term.CursorPosition(); pos.X != 2 || pos.Y != 1 {
+		t.Errorf("want absolute position (2,1), got %v", pos)
+	}
+}
+
+func TestCursorMovementBounds(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  Position
+	}{
+		{"CUU past top", "\x1b[3;4H\x1b[99A", cellbuf.Pos(3, 0)},
+		{"CUD past bottom", "\x1b[3;4H\x1b[99B", cellbuf.Pos(3, 4)},
+		{"CUF past right", "\x1b[3;4H\x1b[99C", cellbuf.Pos(9, 2)},
+		{"CUB past left", "\x1b[3;4H\x1b[99D", cellbuf.Pos(0, 2)},
+		{"CNL past bottom", "\x1b[3;4H\x1b[99E", cellbuf.Pos(0, 4)},
+		{"CPL past top", "\x1b[3;4H\x1b[99F", cellbuf.Pos(0, 0)},
+		{"CHA past right", "\x1b[3;4H\x1b[99G", cellbuf.Pos(9, 2)},
+		{"CUP past bottom right", "\x1b[99;99H", cellbuf.Pos(9, 4)},
+		{"VPA past bottom", "\x1b[3;4H\x1b[99d", cellbuf.Pos(3, 4)},
+
+		// A zero parameter is the same as the default of one.
+		{"CUU zero", "\x1b[3;4H\x1b[0A", cellbuf.Pos(3, 1)},
+		{"CUD zero", "\x1b[3;4H\x1b[0B", cellbuf.Pos(3, 3)},
+		{"CUF zero", "\x1b[3;4H\x1b[0C", cellbuf.Pos(4, 2)},
+		{"CUB zero", "\x1b[3;4H\x1b[0D", cellbuf.Pos(2, 2)},
+		{"CNL zero", "\x1b[3;4H\x1b[0E", cellbuf.Pos(0, 3)},
+		{"CPL zero", "\x1b[3;4H\x1b[0F", cellbuf.Pos(0, 1)},
+		{"CHA zero", "\x1b[3;4H\x1b[0G", cellbuf.Pos(0, 2)},
+		{"CUP zero", "\x1b[3;4H\x1b[0;0H", cellbuf.Pos(0, 0)},
+		{"VPA zero", "\x1b[3;4H\x1b[0d", cellbuf.Pos(3, 0)},
+
+		// With origin mode, the cursor stays within the margins.
+		{"CUU origin mode", "\x1b[2;4r\x1b[?6h\x1b[2;4H\x1b[99A", cellbuf.Pos(3, 1)},
+		{"CUD origin mode", "\x1b[2;4r\x1b[?6h\x1b[2;4H\x1b[99B", cellbuf.Pos(3, 3)},
+		{"CUP origin mode", "\x1b[2;4r\x1b[?6h\x1b[99;99H", cellbuf.Pos(9, 3)},
+		{"HVP origin mode", "\x1b[2;4r\x1b[?6h\x1b[99;99f", cellbuf.Pos(9, 3)},
+		{"HVP origin mode home", "\x1b[2;4r\x1b[?6h\x1b[3;4H\x1b[f", cellbuf.Pos(0, 1)},
+		{"VPA origin mode", "\x1b[2;4r\x1b[?6h\x1b[99d", cellbuf.Pos(0, 3)},
+		{"CR origin mode", "\x1b[3;5r\x1b[?6hab\r", cellbuf.Pos(0, 2)},
+		{"CR LF origin mode", "\x1b[3;5r\x1b[?6hab\r\n", cellbuf.Pos(0, 3)},
+		{"LF new line mode origin mode", "\x1b[3;5r\x1b[?6h\x1b[20hab\n", cellbuf.Pos(0, 3)},
+		{"CNL origin mode", "\x1b[3;5r\x1b[?6h\x1b[E", cellbuf.Pos(0, 3)},
+		{"CPL origin mode", "\x1b[3;5r\x1b[?6h\x1b[2;3H\x1b[F", cellbuf.Pos(0, 2)},
+		{"CR origin mode with margins", "\x1b[?69h\x1b[3;6s\x1b[2;4r\x1b[?6h\x1b[2;3Hab\r", cellbuf.Pos(2, 2)},
+		{
+			"CUF origin mode with margins",
+			"\x1b[?69h\x1b[3;6s\x1b[?6h\x1b[1;2H\x1b[99C",
+			cellbuf.Pos(5, 0),
+		},
+		{
+			"CHA origin mode with margins",
+			"\x1b[?69h\x1b[3;6s\x1b[?6h\x1b[2;2H\x1b[99G",
+			cellbuf.Pos(5, 1),
+		},
+		{
+			"CHA origin mode with margins home",
+			"\x1b[?69h\x1b[3;6s\x1b[?6h\x1b[2;2H\x1b[G",
+			cellbuf.Pos(2, 1),
+		},
+		{
+			"CUB origin mode with margins",
+			"\x1b[?69h\x1b[3;6s\x1b[?6h\x1b[1;2H\x1b[99D",
+			cellbuf.Pos(2, 0),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 10, 5)
+			term.Write([]byte(tc.input))
+			if pos := term.CursorPosition(); pos != tc.want {
+				t.Errorf("want cursor at %v, got %v", tc.want, pos)
+			}
+		})
+	}
+}
+
+func TestSaveRestoreCursor(t *testing.T) {
+	cases := []struct {
+		name          string
+		save, restore string
+	}{
+		{"DECSC DECRC", ansi.DECSC, ansi.DECRC},
+		{"SCOSC SCORC", ansi.SCOSC, ansi.SCORC},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 10, 5)
+			// Save a bold red cursor at (2, 2) within the scroll region in
+			// origin mode, with the special drawing character set.
+			term.Write([]byte("\x1b[2;4r\x1b[?6h\x1b[2;3H\x1b[1;31m\x1b(0" + tc.save))
+			// Change everything and restore.
+			term.Write([]byte("\x1b[m\x1b(B\x1b[?6l\x1b[5;8H" + tc.restore))
+
+			if pos, want := term.CursorPosition(), cellbuf.Pos(2, 2); pos != want {
+				t.Errorf("want cursor at %v, got %v", want, pos)
+			}
+			var pen Style
+			pen.Bold(true).Foreground(ansi.Red)
+			if got := term.scr.cur.Pen; !got.Equal(&pen) {
+				t.Errorf("want pen %q, got %q", pen.Sequence(), got.Sequence())
+			}
+			if !term.isModeSet(ansi.OriginMode) {
+				t.Error("expected origin mode to be restored")
+			}
+			term.Write([]byte("q"))
+			if c, ok := term.Cell(2, 2); !ok || c.String() != "─" || !c.Style.Equal(&pen) {
+				t.Errorf("want a bold red %q, got %#v", "─", c)
+			}
+		})
+	}
+}
+
+func TestRestoreCursorWithoutSave(t *testing.T) {
+	term := newTestTerminal(t, 10, 5)
+	term.Write([]byte("\x1b[2;4r\x1b[?6h\x1b[1;31m\x1b(0\x1b[3;5H" + ansi.DECRC))
+
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(0, 0) {
+		t.Errorf("want cursor at the top-left, got %v", pos)
+	}
+	if pen := term.scr.cur.Pen; !pen.Empty() {
+		t.Errorf("want the default pen, got %q", pen.Sequence())
+	}
+	if term.isModeSet(ansi.OriginMode) {
+		t.Error("expected origin mode to be reset")
+	}
+	term.Write([]byte("q"))
+	if c, ok := term.Cell(0, 0); !ok || c.String() != "q" {
+		t.Errorf("want %q, got %#v", "q", c)
+	}
+}
+
+func TestCursorPositionCallback(t *testing.T) {
+	type move struct{ from, to Position }
+
+	term := newTestTerminal(t, 3, 3)
+	var moves []move
+	term.Callbacks.CursorPosition = func(from, to Position) {
+		moves = append(moves, move{from, to})
+	}
+
+	// The cursor stays on the last column until the next character wraps.
+	term.Write([]byte("abcd"))
+	want := []move{
+		{cellbuf.Pos(0, 0), cellbuf.Pos(1, 0)},
+		{cellbuf.Pos(1, 0), cellbuf.Pos(2, 0)},
+		{cellbuf.Pos(2, 0), cellbuf.Pos(2, 1)},
+		{cellbuf.Pos(2, 1), cellbuf.Pos(1, 1)},
+	}
+	if !reflect.DeepEqual(moves, want) {
+		t.Errorf("want moves %v, got %v", want, moves)
+	}
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(1, 1) {
+		t.Errorf("want cursor at %v, got %v", cellbuf.Pos(1, 1), pos)
+	}
+
+	// Moves during a synchronized update are reported once it ends.
+	moves = nil
+	term.Write([]byte(ansi.SetSynchronizedOutputMode + "e\r\nf\x1b[H\x1b[3;3H"))
+	if len(moves) != 0 {
+		t.Errorf("want no moves during the update, got %v", moves)
+	}
+	term.Write([]byte(ansi.ResetSynchronizedOutputMode))
+	if want := []move{{cellbuf.Pos(1, 1), cellbuf.Pos(2, 2)}}; !reflect.DeepEqual(moves, want) {
+		t.Errorf("want moves %v, got %v", want, moves)
+	}
+
+	// An update that leaves the cursor in place reports nothing.
+	moves = nil
+	term.Write([]byte(ansi.SetSynchronizedOutputMode + "\x1b[H\x1b[3;3H" + ansi.ResetSynchronizedOutputMode))
+	if len(moves) != 0 {
+		t.Errorf("want no moves, got %v", moves)
+	}
+}
@@ -1,6 +1,11 @@
 package vt
 
-import "github.com/charmbracelet/x/cellbuf"
+import (
+	"sort"
+	"sync"
+
+	"github.com/charmbracelet/x/cellbuf"
+)
 
 // Damage represents a damaged area.
 type Damage interface {
@@ -69,3 +74,258 @@ type ScrollDamage struct {
 	Rectangle
 	Dx, Dy int
 }
+
+// screenDamageRatio is the ratio of damaged cells to screen cells above which
+// coalesced damage collapses into a single [ScreenDamage].
+const screenDamageRatio = 0.5
+
+// maxTrackedDamage is the number of damaged areas a [damageTracker] keeps
+// before it considers the whole screen damaged.
+const maxTrackedDamage = 4096
+
+// damageTracker accumulates damaged areas until they're flushed.
+type damageTracker struct {
+	damages []Damage
+	full    bool // the whole screen is damaged
+	mu      sync.Mutex
+
+	// synced is true during a synchronized update. The damaged areas are
+	// held until the update ends.
+	synced   bool
+	held     []Damage
+	heldFull bool
+	// heldCursor is the cursor position before the first cursor move of a
+	// synchronized update, nil if the cursor didn't move.
+	heldCursor *Position
+}
+
+// add records a damaged area. It reports whether the area should be reported
+// right away, which isn't the case during a synchronized update.
+func (d *damageTracker) add(dmg Damage) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.synced && !d.heldFull {
+		if _, ok := dmg.(ScreenDamage); ok || len(d.held) >= maxTrackedDamage {
+			d.heldFull = true
+			d.held = d.held[:0]
+		} else {
+			d.held = append(d.held, dmg)
+		}
+	}
+	if !d.full {
+		if _, ok := dmg.(ScreenDamage); ok || len(d.damages) >= maxTrackedDamage {
+			d.full = true
+			d.damages = d.damages[:0]
+		} else {
+			d.damages = append(d.damages, dmg)
+		}
+	}
+	return !d.synced
+}
+
+// startSync starts a synchronized update.
+func (d *damageTracker) startSync() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.synced = true
+}
+
+// holdCursorMove records a cursor move from the given position during a
+// synchronized update. It reports whether the move is held until the update
+// ends, see [damageTracker.heldCursorMove].
+func (d *damageTracker) holdCursorMove(from Position) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.synced {
+		return false
+	}
+	if d.heldCursor == nil {
+		d.heldCursor = &from
+	}
+	return true
+}
+
+// heldCursorMove returns and forgets the cursor position before the cursor
+// moves held during the last synchronized update.
+func (d *damageTracker) heldCursorMove() (from Position, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.synced || d.heldCursor == nil {
+		return Position{}, false
+	}
+	from = *d.heldCursor
+	d.heldCursor = nil
+	return from, true
+}
+
+// endSync ends a synchronized update and returns the coalesced areas damaged
+// during the update within a screen of the given size.
+func (d *damageTracker) endSync(width, height int) []Damage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.synced {
+		return nil
+	}
+	var rects []Rectangle
+	full := d.heldFull
+	if !full {
+		rects, full = coalesceDamage(d.held, width, height)
+	}
+	d.synced = false
+	d.held = d.held[:0]
+	d.heldFull = false
+	return rectsDamage(rects, full, width, height)
+}
+
+// flush returns the coalesced damaged areas within a screen of the given size
+// and resets the tracker. It returns nil during a synchronized update.
+func (d *damageTracker) flush(width, height int) []Damage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.synced {
+		return nil
+	}
+	rects, full := d.coalesce(width, height)
+	d.damages = d.damages[:0]
+	d.full = false
+	return rectsDamage(rects, full, width, height)
+}
+
+// rectsDamage converts coalesced damaged areas within a screen of the given
+// size to [RectDamage], or a single [ScreenDamage] when full is true.
+func rectsDamage(rects []Rectangle, full bool, width, height int) []Damage {
+	if full {
+		return []Damage{ScreenDamage{width, height}}
+	}
+	if len(rects) == 0 {
+		return nil
+	}
+	damages := make([]Damage, len(rects))
+	for i, r := range rects {
+		damages[i] = RectDamage(r)
+	}
+	return damages
+}
+
+// rects returns the coalesced damaged areas within a screen of the given size
+// without resetting the tracker. When the whole screen is damaged, it returns
+// the screen bounds. It returns nil during a synchronized update.
+func (d *damageTracker) rects(width, height int) []Rectangle {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.synced {
+		return nil
+	}
+	rects, full := d.coalesce(width, height)
+	if full {
+		return []Rectangle{cellbuf.Rect(0, 0, width, height)}
+	}
+	return rects
+}
+
+// reset discards the damaged areas.
+func (d *damageTracker) reset() {
+	d.mu.Lock()
+	d.damages = d.damages[:0]
+	d.full = false
+	d.mu.Unlock()
+}
+
+// coalesce is like [coalesceDamage] for the tracked damaged areas. The caller
+// must hold the lock.
+func (d *damageTracker) coalesce(width, height int) (rects []Rectangle, full bool) {
+	if d.full {
+		return nil, true
+	}
+	return coalesceDamage(d.damages, width, height)
+}
+
+// damageSpan is a horizontal span of damaged cells in a single line.
+type damageSpan struct {
+	x0, x1 int
+}
+
+// coalesceDamage merges the given damaged areas into a set of non-overlapping
+// rectangles within a screen of the given size. The rectangles are sorted by
+// their top-left corner. It reports full when the whole screen should be
+// considered damaged.
+//
+// Damaged areas are first split into lines, where overlapping and adjacent
+// spans are merged. Spans that cover the same columns on consecutive lines are
+// then merged into a single rectangle.
+func coalesceDamage(damages []Damage, width, height int) (rects []Rectangle, full bool) {
+	if len(damages) == 0 || width <= 0 || height <= 0 {
+		return nil, false
+	}
+
+	bounds := cellbuf.Rect(0, 0, width, height)
+	lines := make([][]damageSpan, height)
+	for _, d := range damages {
+		if _, ok := d.(ScreenDamage); ok {
+			return nil, true
+		}
+		r := d.Bounds().Intersect(bounds)
+		if r.Empty() {
+			continue
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			lines[y] = append(lines[y], damageSpan{r.Min.X, r.Max.X})
+		}
+	}
+
+	var area int
+	var prev, cur []int // indices of rectangles that end on the previous and current line
+	for y, spans := range lines {
+		cur = cur[:0]
+		for _, span := range mergeSpans(spans) {
+			area += span.x1 - span.x0
+
+			// Extend a rectangle from the previous line covering the same
+			// columns, or start a new one.
+			extended := false
+			for _, i := range prev {
+				if rects[i].Min.X == span.x0 && rects[i].Max.X == span.x1 {
+					rects[i].Max.Y++
+					cur = append(cur, i)
+					extended = true
+					break
+				}
+			}
+			if !extended {
+				cur = append(cur, len(rects))
+				rects = append(rects, cellbuf.Rect(span.x0, y, span.x1-span.x0, 1))
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	if float64(area) > screenDamageRatio*float64(width*height) {
+		return nil, true
+	}
+
+	return rects, false
+}
+
+// mergeSpans sorts the given spans and merges the overlapping and adjacent
+// ones.
+func mergeSpans(spans []damageSpan) []damageSpan {
+	if len(spans) < 2 {
+		return spans
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].x0 < spans[j].x0
+	})
+
+	merged := spans[:1]
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span.x0 <= last.x1 {
+			last.x1 = max(last.x1, span.x1)
+			continue
+		}
+		merged = append(merged, span)
+	}
+
+	return merged
+}
@@ -0,0 +1,246 @@
+package vt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+func TestCoalesceDamage(t *testing.T) {
+	cases := []struct {
+		name    string
+		damages []Damage
+		want    []Rectangle
+		full    bool
+	}{
+		{
+			name: "no damage",
+		},
+		{
+			name: "scattered cells",
+			damages: []Damage{
+				CellDamage{X: 5, Y: 3, Width: 1},
+				CellDamage{X: 1, Y: 0, Width: 1},
+				CellDamage{X: 8, Y: 0, Width: 2},
+			},
+			want: []Rectangle{
+				cellbuf.Rect(1, 0, 1, 1),
+				cellbuf.Rect(8, 0, 2, 1),
+				cellbuf.Rect(5, 3, 1, 1),
+			},
+		},
+		{
+			name: "contiguous cells",
+			damages: []Damage{
+				CellDamage{X: 2, Y: 1, Width: 1},
+				CellDamage{X: 3, Y: 1, Width: 2},
+				CellDamage{X: 5, Y: 1, Width: 1},
+			},
+			want: []Rectangle{cellbuf.Rect(2, 1, 4, 1)},
+		},
+		{
+			name: "stacked cells",
+			damages: []Damage{
+				CellDamage{X: 2, Y: 1, Width: 2},
+				CellDamage{X: 2, Y: 2, Width: 2},
+				CellDamage{X: 2, Y: 3, Width: 2},
+			},
+			want: []Rectangle{cellbuf.Rect(2, 1, 2, 3)},
+		},
+		{
+			name: "overlapping rectangles",
+			damages: []Damage{
+				RectDamage(cellbuf.Rect(0, 0, 4, 2)),
+				RectDamage(cellbuf.Rect(2, 0, 4, 2)),
+				CellDamage{X: 1, Y: 1, Width: 1},
+			},
+			want: []Rectangle{cellbuf.Rect(0, 0, 6, 2)},
+		},
+		{
+			name: "rectangles with different columns",
+			damages: []Damage{
+				RectDamage(cellbuf.Rect(0, 0, 4, 2)),
+				RectDamage(cellbuf.Rect(0, 2, 2, 1)),
+			},
+			want: []Rectangle{
+				cellbuf.Rect(0, 0, 4, 2),
+				cellbuf.Rect(0, 2, 2, 1),
+			},
+		},
+		{
+			name: "out of bounds",
+			damages: []Damage{
+				RectDamage(cellbuf.Rect(8, 4, 10, 10)),
+				CellDamage{X: 20, Y: 0, Width: 1},
+			},
+			want: []Rectangle{cellbuf.Rect(8, 4, 2, 1)},
+		},
+		{
+			name: "above threshold",
+			damages: []Damage{
+				RectDamage(cellbuf.Rect(0, 0, 10, 3)),
+				CellDamage{X: 0, Y: 3, Width: 1},
+			},
+			full: true,
+		},
+		{
+			name: "screen damage",
+			damages: []Damage{
+				CellDamage{X: 0, Y: 0, Width: 1},
+				ScreenDamage{Width: 10, Height: 5},
+			},
+			full: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rects, full := coalesceDamage(tc.damages, 10, 5)
+			if full != tc.full {
+				t.Errorf("expected full %v, got %v", tc.full, full)
+			}
+			if !reflect.DeepEqual(rects, tc.want) {
+				t.Errorf("expected rectangles %v, got %v", tc.want, rects)
+			}
+		})
+	}
+}
+
+func TestFlushDamage(t *testing.T) {
+	term := newTestTerminal(t, 10, 5)
+	term.FlushDamage()
+
+	term.Write([]byte("abc\x1b[3;1Hde"))
+	want := []Damage{
+		RectDamage(cellbuf.Rect(0, 0, 3, 1)),
+		RectDamage(cellbuf.Rect(0, 2, 2, 1)),
+	}
+	if got := term.FlushDamage(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected damage %v, got %v", want, got)
+	}
+
+	if got := term.FlushDamage(); got != nil {
+		t.Errorf("expected no damage after flush, got %v", got)
+	}
+
+	term.Write([]byte("\x1b[2J"))
+	want = []Damage{ScreenDamage{Width: 10, Height: 5}}
+	if got := term.FlushDamage(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected damage %v, got %v", want, got)
+	}
+}
+
+func TestSyncDamage(t *testing.T) {
+	term := newTestTerminal(t, 10, 5)
+	term.FlushDamage()
+	var reported []Damage
+	term.Callbacks.Damage = func(d Damage) {
+		reported = append(reported, d)
+	}
+
+	term.Write([]byte("\x1b[?2026h"))
+	if !term.SyncActive() {
+		t.Fatal("expected synchronized update to be active")
+	}
+	term.Write([]byte("abc"))
+	term.Write([]byte("\x1b[3;1Hde"))
+	term.Write([]byte("\x1b[3;3Hf"))
+	if len(reported) != 0 {
+		t.Errorf("expected no damage to be reported during the update, got %v", reported)
+	}
+	if got := term.FlushDamage(); got != nil {
+		t.Errorf("expected no damage to be flushed during the update, got %v", got)
+	}
+
+	term.Write([]byte("\x1b[?2026l"))
+	if term.SyncActive() {
+		t.Fatal("expected synchronized update to be inactive")
+	}
+	want := []Damage{
+		RectDamage(cellbuf.Rect(0, 0, 3, 1)),
+		RectDamage(cellbuf.Rect(0, 2, 3, 1)),
+	}
+	if !reflect.DeepEqual(reported, want) {
+		t.Errorf("expected reported damage %v, got %v", want, reported)
+	}
+	if got := term.FlushDamage(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected damage %v, got %v", want, got)
+	}
+
+	// Damage is reported right away again.
+	reported = nil
+	term.Write([]byte("g"))
+	if len(reported) != 1 {
+		t.Errorf("expected damage to be reported, got %v", reported)
+	}
+}
+
+func TestDamagedCells(t *testing.T) {
+	term := newTestTerminal(t, 10, 5)
+	term.ClearDamage()
+
+	// Overwrite "b" so that it's damaged twice.
+	term.Write([]byte("abc\x1b[1;2Hx\x1b[3;4Hde"))
+
+	want := map[Position]string{
+		{X: 0, Y: 0}: "a",
+		{X: 1, Y: 0}: "x",
+		{X: 2, Y: 0}: "c",
+		{X: 3, Y: 2}: "d",
+		{X: 4, Y: 2}: "e",
+	}
+	got := map[Position]string{}
+	term.DamagedCells(func(pos Position, cell Cell) {
+		if _, ok := got[pos]; ok {
+			t.Errorf("cell at %v visited more than once", pos)
+		}
+		got[pos] = cell.String()
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected damaged cells %v, got %v", want, got)
+	}
+
+	term.ClearDamage()
+	term.DamagedCells(func(pos Position, _ Cell) {
+		t.Errorf("expected no damaged cells, got cell at %v", pos)
+	})
+}
+
+func TestResizeDamage(t *testing.T) {
+	term := newTestTerminal(t, 10, 4)
+	term.Write([]byte("hello"))
+	term.ClearDamage()
+
+	if term.Resize(10, 4) {
+		t.Error("expected resizing to the same size not to change it")
+	}
+	if dmg := term.FlushDamage(); dmg != nil {
+		t.Errorf("expected no damage, got %v", dmg)
+	}
+
+	if !term.Resize(12, 3) {
+		t.Error("expected resizing to a new size to change it")
+	}
+	if w, h := term.Size(); w != 12 || h != 3 {
+		t.Errorf("expected size 12x3, got %dx%d", w, h)
+	}
+	want := []Damage{ScreenDamage{Width: 12, Height: 3}}
+	if dmg := term.FlushDamage(); !reflect.DeepEqual(dmg, want) {
+		t.Errorf("expected %v, got %v", want, dmg)
+	}
+
+	// The alternate screen is resized the same.
+	term.Write([]byte("\x1b[?1049h"))
+	term.ClearDamage()
+	if term.Resize(12, 3) {
+		t.Error("expected resizing the alternate screen to the same size not to change it")
+	}
+	if !term.Resize(8, 2) {
+		t.Error("expected resizing the alternate screen to a new size to change it")
+	}
+	want = []Damage{ScreenDamage{Width: 8, Height: 2}}
+	if dmg := term.FlushDamage(); !reflect.DeepEqual(dmg, want) {
+		t.Errorf("expected %v, got %v", want, dmg)
+	}
+}
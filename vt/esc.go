@@ -1,7 +1,11 @@
 package vt
 
 import (
+	"fmt"
+	"image/color"
+
 	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
 )
 
 // handleEsc handles an escape sequence.
@@ -14,21 +18,41 @@ func (t *Terminal) handleEsc(cmd ansi.Cmd) {
 		if final := cmd.Final(); final != 0 {
 			str += string(final)
 		}
-		t.logf("unhandled sequence: ESC %q", str)
+		t.unhandled(fmt.Sprintf("ESC %q", str))
 	}
 }
 
-// fullReset performs a full terminal reset as in [ansi.RIS].
+// fullReset performs a full terminal reset as in [ansi.RIS]. It switches back
+// to the main screen, clears the screens and the scrollback, and resets the
+// modes, tab stops, character sets, and colors.
 func (t *Terminal) fullReset() {
+	t.setAltScreenMode(false)
 	t.scrs[0].Reset()
 	t.scrs[1].Reset()
+	t.scrollback.clear()
 	t.resetTabStops()
-
-	// TODO: Do we reset all modes here? Investigate.
 	t.resetModes()
 
 	t.gl, t.gr = 0, 1
 	t.gsingle = 0
 	t.charsets = [4]CharSet{}
 	t.atPhantom = false
+
+	t.fg = defaultFg
+	t.bg = defaultBg
+	t.cur = defaultCur
+	t.colors = [256]color.Color{}
+	t.titles = nil
+}
+
+// screenAlignment fills the screen with the letter E using the default style
+// as in [ansi.DECALN]. It resets the margins and moves the cursor to the upper
+// left corner.
+func (t *Terminal) screenAlignment() {
+	width, height := t.Width(), t.Height()
+	t.scr.setHorizontalMargins(0, width)
+	t.scr.setVerticalMargins(0, height)
+	t.setLineAttrs(0, height, LineSingleWidth)
+	t.scr.Fill(cellbuf.NewCell('E'), cellbuf.Rect(0, 0, width, height))
+	t.setCursor(0, 0)
 }
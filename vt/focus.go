@@ -17,11 +17,20 @@ func (t *Terminal) Blur() {
 }
 
 func (t *Terminal) focus(focus bool) {
-	if mode, ok := t.modes[ansi.FocusEventMode]; ok && mode.IsSet() {
-		if focus {
-			t.buf.WriteString(ansi.Focus)
-		} else {
-			t.buf.WriteString(ansi.Blur)
-		}
+	t.buf.WriteString(t.FocusEvent(focus))
+}
+
+// FocusEvent returns the focus in or focus out report sequence for the given
+// focus state when the focus event mode is enabled, and an empty string
+// otherwise.
+//
+// See [ansi.FocusEventMode].
+func (t *Terminal) FocusEvent(focused bool) string {
+	if !t.isModeSet(ansi.FocusEventMode) {
+		return ""
+	}
+	if focused {
+		return ansi.Focus
 	}
+	return ansi.Blur
 }
@@ -0,0 +1,41 @@
+package vt
+
+import (
+	"io"
+	"testing"
+)
+
+func TestFocusEvent(t *testing.T) {
+	term := newTestTerminal(t, 10, 4)
+	if got := term.FocusEvent(true); got != "" {
+		t.Errorf("expected no focus event when disabled, got %q", got)
+	}
+	term.Focus()
+	term.Blur()
+	if got := readString(&term.buf); got != "" {
+		t.Errorf("expected no focus events to be sent, got %q", got)
+	}
+
+	term.Write([]byte("\x1b[?1004h"))
+	if got, want := term.FocusEvent(true), "\x1b[I"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got, want := term.FocusEvent(false), "\x1b[O"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	term.Focus()
+	term.Blur()
+	if got, want := readString(&term.buf), "\x1b[I\x1b[O"; got != want {
+		t.Errorf("expected sent events %q, got %q", want, got)
+	}
+
+	term.Write([]byte("\x1b[?1004l"))
+	if got := term.FocusEvent(false); got != "" {
+		t.Errorf("expected no focus event when disabled, got %q", got)
+	}
+}
+
+func readString(r io.Reader) string {
+	b, _ := io.ReadAll(r)
+	return string(b)
+}
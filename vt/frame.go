@@ -0,0 +1,43 @@
+package vt
+
+// Frame is a consistent view of the focused screen cells and cursor of a
+// terminal, taken between writes. It isn't affected by later writes to the
+// terminal, so a renderer can read it while the terminal keeps processing
+// output. See [Terminal.BeginFrame].
+type Frame struct {
+	Snapshot
+
+	// Cursor is the cursor of the screen when the frame was taken.
+	Cursor Cursor
+}
+
+// BeginFrame returns a frame of the current focused screen. It waits for any
+// in-progress [Terminal.Write] to finish, so the frame never holds a partially
+// processed write. Call [Terminal.EndFrame] once done with the frame to let
+// the terminal reuse its memory for the next one.
+func (t *Terminal) BeginFrame() *Frame {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	f := t.spareFrame
+	t.spareFrame = nil
+	if f == nil {
+		f = new(Frame)
+	}
+	t.scr.snapshotInto(&f.Snapshot)
+	f.Cursor = t.scr.Cursor()
+	t.frame = f
+	return f
+}
+
+// EndFrame releases the frame returned by the last call to
+// [Terminal.BeginFrame]. The frame must not be used afterwards.
+func (t *Terminal) EndFrame() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.frame != nil {
+		t.spareFrame = t.frame
+		t.frame = nil
+	}
+}
@@ -0,0 +1,74 @@
+package vt
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFrame(t *testing.T) {
+	const w, h = 10, 4
+	term := newTestTerminal(t, w, h)
+	term.Write([]byte("\x1b[H" + strings.Repeat("a", w*h-1) + "\x1b[2;3H")) //nolint:errcheck
+
+	f := term.BeginFrame()
+	if f.Width() != w || f.Height() != h {
+		t.Fatalf("expected a %dx%d frame, got %dx%d", w, h, f.Width(), f.Height())
+	}
+	if got := f.Cursor.Position; got.X != 2 || got.Y != 1 {
+		t.Fatalf("expected the frame cursor at 2,1, got %v", got)
+	}
+
+	// Each write fills the whole screen with the same character.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			c := string(rune('b' + i%25))
+			term.Write([]byte("\x1b[H" + strings.Repeat(c, w*h-1) + "\x1b[4;1H")) //nolint:errcheck
+		}
+	}()
+
+	// The frame doesn't change while the terminal is written to.
+	for i := 0; i < 100; i++ {
+		for y := 0; y < h; y++ {
+			for x := 0; x < w-1; x++ {
+				if c, _ := f.Cell(x, y); c.Rune != 'a' {
+					t.Fatalf("expected frame cell %d,%d to stay 'a', got %q", x, y, c.Rune)
+				}
+			}
+		}
+	}
+	if got := f.Cursor.Position; got.X != 2 || got.Y != 1 {
+		t.Fatalf("expected the frame cursor to stay at 2,1, got %v", got)
+	}
+	term.EndFrame()
+
+	// A frame taken during the writes never holds a partial write.
+	for i := 0; i < 100; i++ {
+		f := term.BeginFrame()
+		first, _ := f.Cell(0, 0)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if y == h-1 && x == w-1 {
+					continue
+				}
+				if c, _ := f.Cell(x, y); c.Rune != first.Rune {
+					t.Fatalf("expected frame cell %d,%d to be %q, got %q", x, y, first.Rune, c.Rune)
+				}
+			}
+		}
+		term.EndFrame()
+	}
+	wg.Wait()
+
+	f = term.BeginFrame()
+	defer term.EndFrame()
+	if c, _ := f.Cell(0, 0); c.Rune != 'b'+99%25 {
+		t.Errorf("expected the last frame to hold the last write, got %q", c.Rune)
+	}
+	if got := f.Cursor.Position; got.X != 0 || got.Y != 3 {
+		t.Errorf("expected the last frame cursor at 0,3, got %v", got)
+	}
+}
@@ -153,6 +153,7 @@ func (t *Terminal) registerDefaultOscHandlers() {
 		1, // Set icon name
 		2, // Set window title
 	} {
+		cmd := cmd
 		t.RegisterOscHandler(cmd, func(data []byte) bool {
 			t.handleTitle(cmd, data)
 			return true
@@ -173,6 +174,7 @@ func (t *Terminal) registerDefaultOscHandlers() {
 		111, // Reset background color
 		112, // Reset cursor color
 	} {
+		cmd := cmd
 		t.RegisterOscHandler(cmd, func(data []byte) bool {
 			t.handleDefaultColor(cmd, data)
 			return true
@@ -196,13 +198,13 @@ func (t *Terminal) registerDefaultEscHandlers() {
 
 	t.RegisterEscHandler('7', func() bool {
 		// Save Cursor [ansi.DECSC]
-		t.scr.SaveCursor()
+		t.saveCursor()
 		return true
 	})
 
 	t.RegisterEscHandler('8', func() bool {
 		// Restore Cursor [ansi.DECRC]
-		t.scr.RestoreCursor()
+		t.restoreCursor()
 		return true
 	})
 
@@ -220,6 +222,7 @@ func (t *Terminal) registerDefaultEscHandlers() {
 		ansi.Command(0, '*', '0'), // Special G2
 		ansi.Command(0, '+', '0'), // Special G3
 	} {
+		cmd := cmd
 		t.RegisterEscHandler(cmd, func() bool {
 			// Select Character Set [ansi.SCS]
 			c := ansi.Cmd(cmd)
@@ -238,12 +241,60 @@ func (t *Terminal) registerDefaultEscHandlers() {
 		})
 	}
 
+	t.RegisterEscHandler('\\', func() bool {
+		// String Terminator [ansi.ST]
+		// The string was already dispatched, nothing to do.
+		return true
+	})
+
+	for _, cmd := range []int{
+		ansi.Command(0, '#', '3'), // Double-height top half
+		ansi.Command(0, '#', '4'), // Double-height bottom half
+		ansi.Command(0, '#', '5'), // Single-width
+		ansi.Command(0, '#', '6'), // Double-width
+	} {
+		cmd := cmd
+		t.RegisterEscHandler(cmd, func() bool {
+			// Line Attributes [ansi.DECDHLTop] [ansi.DECDHLBottom]
+			// [ansi.DECSWL] [ansi.DECDWL]
+			var attr LineAttr
+			switch ansi.Cmd(cmd).Final() {
+			case '3':
+				attr = LineDoubleHeightTop
+			case '4':
+				attr = LineDoubleHeightBottom
+			case '5':
+				attr = LineSingleWidth
+			case '6':
+				attr = LineDoubleWidth
+			default:
+				return false
+			}
+			_, y := t.scr.CursorPosition()
+			t.scr.setLineAttr(y, attr)
+			t.atPhantom = false
+			return true
+		})
+	}
+
+	t.RegisterEscHandler(ansi.Command(0, '#', '8'), func() bool {
+		// Screen Alignment Pattern [ansi.DECALN]
+		t.screenAlignment()
+		return true
+	})
+
 	t.RegisterEscHandler('D', func() bool {
 		// Index [ansi.IND]
 		t.index()
 		return true
 	})
 
+	t.RegisterEscHandler('E', func() bool {
+		// Next Line [ansi.NEL]
+		t.nextLine()
+		return true
+	})
+
 	t.RegisterEscHandler('H', func() bool {
 		// Horizontal Tab Set [ansi.HTS]
 		t.horizontalTabSet()
@@ -293,46 +344,53 @@ func (t *Terminal) registerDefaultEscHandlers() {
 	})
 }
 
+// countParam returns the parameter at the given index, or 1 if it's missing
+// or zero, as count parameters treat both like the default.
+func countParam(params ansi.Params, i int) int {
+	n, _, _ := params.Param(i, 1)
+	return max(n, 1)
+}
+
 // registerDefaultCsiHandlers registers the default CSI escape sequence handlers.
 func (t *Terminal) registerDefaultCsiHandlers() {
 	t.RegisterCsiHandler('@', func(params ansi.Params) bool {
 		// Insert Character [ansi.ICH]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		t.scr.InsertCell(n)
 		return true
 	})
 
 	t.RegisterCsiHandler('A', func(params ansi.Params) bool {
 		// Cursor Up [ansi.CUU]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		t.moveCursor(0, -n)
 		return true
 	})
 
 	t.RegisterCsiHandler('B', func(params ansi.Params) bool {
 		// Cursor Down [ansi.CUD]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		t.moveCursor(0, n)
 		return true
 	})
 
 	t.RegisterCsiHandler('C', func(params ansi.Params) bool {
 		// Cursor Forward [ansi.CUF]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		t.moveCursor(n, 0)
 		return true
 	})
 
 	t.RegisterCsiHandler('D', func(params ansi.Params) bool {
 		// Cursor Backward [ansi.CUB]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		t.moveCursor(-n, 0)
 		return true
 	})
 
 	t.RegisterCsiHandler('E', func(params ansi.Params) bool {
 		// Cursor Next Line [ansi.CNL]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		t.moveCursor(0, n)
 		t.carriageReturn()
 		return true
@@ -340,7 +398,7 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 
 	t.RegisterCsiHandler('F', func(params ansi.Params) bool {
 		// Cursor Previous Line [ansi.CPL]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		t.moveCursor(0, -n)
 		t.carriageReturn()
 		return true
@@ -348,22 +406,24 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 
 	t.RegisterCsiHandler('G', func(params ansi.Params) bool {
 		// Cursor Horizontal Absolute [ansi.CHA]
-		n, _, _ := params.Param(0, 1)
-		_, y := t.scr.CursorPosition()
-		t.setCursor(n-1, y)
+		n := countParam(params, 0)
+		width := t.Width()
+		_, y := t.relativeCursorPosition()
+		t.setCursorPosition(min(width-1, n-1), y)
 		return true
 	})
 
-	t.RegisterCsiHandler('H', func(params ansi.Params) bool {
+	cup := func(params ansi.Params) bool {
 		// Cursor Position [ansi.CUP]
 		width, height := t.Width(), t.Height()
-		row, _, _ := params.Param(0, 1)
-		col, _, _ := params.Param(1, 1)
+		row := countParam(params, 0)
+		col := countParam(params, 1)
 		y := min(height-1, row-1)
 		x := min(width-1, col-1)
 		t.setCursorPosition(x, y)
 		return true
-	})
+	}
+	t.RegisterCsiHandler('H', cup)
 
 	t.RegisterCsiHandler('I', func(params ansi.Params) bool {
 		// Cursor Horizontal Tabulation [ansi.CHT]
@@ -384,17 +444,30 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 			for _, rect := range []Rectangle{rect1, rect2} {
 				t.scr.Fill(t.scr.blankCell(), rect)
 			}
+			t.setLineAttrs(y+1, height, LineSingleWidth)
 		case 1: // Erase screen above (including cursor)
-			rect := cellbuf.Rect(0, 0, width, y+1)
-			t.scr.Fill(t.scr.blankCell(), rect)
+			rect1 := cellbuf.Rect(0, 0, width, y) // start of screen to previous line
+			rect2 := cellbuf.Rect(0, y, x+1, 1)   // start of line to cursor
+			for _, rect := range []Rectangle{rect1, rect2} {
+				t.scr.Fill(t.scr.blankCell(), rect)
+			}
+			t.setLineAttrs(0, y, LineSingleWidth)
 		case 2: // erase screen
-			fallthrough
-		case 3: // erase display
-			// TODO: Scrollback buffer support?
-			t.scr.Clear()
+			t.scr.Fill(t.scr.blankCell(), cellbuf.Rect(0, 0, width, height))
+			t.setLineAttrs(0, height, LineSingleWidth)
+		case 3: // erase scrollback
+			// Like xterm, only the saved lines are erased, the screen and
+			// the cursor are kept.
+			if t.scrollOffset() > 0 {
+				// The viewport moves back to the screen.
+				t.scr.damage(ScreenDamage{width, height})
+			}
+			t.scrollback.clear()
+			return true
 		default:
 			return false
 		}
+		t.atPhantom = false
 		return true
 	})
 
@@ -418,12 +491,13 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 		default:
 			return false
 		}
+		t.atPhantom = false
 		return true
 	})
 
 	t.RegisterCsiHandler('L', func(params ansi.Params) bool {
 		// Insert Line [ansi.IL]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		if t.scr.InsertLine(n) {
 			// Move the cursor to the left margin.
 			t.scr.setCursorX(0, true)
@@ -433,7 +507,7 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 
 	t.RegisterCsiHandler('M', func(params ansi.Params) bool {
 		// Delete Line [ansi.DL]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		if t.scr.DeleteLine(n) {
 			// If the line was deleted successfully, move the cursor to the
 			// left.
@@ -445,21 +519,21 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 
 	t.RegisterCsiHandler('P', func(params ansi.Params) bool {
 		// Delete Character [ansi.DCH]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		t.scr.DeleteCell(n)
 		return true
 	})
 
 	t.RegisterCsiHandler('S', func(params ansi.Params) bool {
 		// Scroll Up [ansi.SU]
-		n, _, _ := params.Param(0, 1)
-		t.scr.ScrollUp(n)
+		n := countParam(params, 0)
+		t.scrollUp(n)
 		return true
 	})
 
 	t.RegisterCsiHandler('T', func(params ansi.Params) bool {
 		// Scroll Down [ansi.SD]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		t.scr.ScrollDown(n)
 		return true
 	})
@@ -489,18 +563,18 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 
 	t.RegisterCsiHandler('`', func(params ansi.Params) bool {
 		// Horizontal Position Absolute [ansi.HPA]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		width := t.Width()
-		_, y := t.scr.CursorPosition()
+		_, y := t.relativeCursorPosition()
 		t.setCursorPosition(min(width-1, n-1), y)
 		return true
 	})
 
 	t.RegisterCsiHandler('a', func(params ansi.Params) bool {
 		// Horizontal Position Relative [ansi.HPR]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		width := t.Width()
-		x, y := t.scr.CursorPosition()
+		x, y := t.relativeCursorPosition()
 		t.setCursorPosition(min(width-1, x+n), y)
 		return true
 	})
@@ -519,12 +593,11 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 			return false
 		}
 
-		// Do we fully support VT220?
-		t.buf.WriteString(ansi.PrimaryDeviceAttributes(
+		// Only advertise the features that are implemented.
+		t.respond(ansi.PrimaryDeviceAttributes(
 			62, // VT220
-			1,  // 132 columns
-			6,  // Selective Erase
 			22, // ANSI color
+			28, // Rectangular editing
 		))
 		return true
 	})
@@ -537,7 +610,7 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 		}
 
 		// Do we fully support VT220?
-		t.buf.WriteString(ansi.SecondaryDeviceAttributes(
+		t.respond(ansi.SecondaryDeviceAttributes(
 			1,  // VT220
 			10, // Version 1.0
 			0,  // ROM Cartridge is always zero
@@ -547,32 +620,24 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 
 	t.RegisterCsiHandler('d', func(params ansi.Params) bool {
 		// Vertical Position Absolute [ansi.VPA]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		height := t.Height()
-		x, _ := t.scr.CursorPosition()
+		x, _ := t.relativeCursorPosition()
 		t.setCursorPosition(x, min(height-1, n-1))
 		return true
 	})
 
 	t.RegisterCsiHandler('e', func(params ansi.Params) bool {
 		// Vertical Position Relative [ansi.VPR]
-		n, _, _ := params.Param(0, 1)
+		n := countParam(params, 0)
 		height := t.Height()
-		x, y := t.scr.CursorPosition()
+		x, y := t.relativeCursorPosition()
 		t.setCursorPosition(x, min(height-1, y+n))
 		return true
 	})
 
-	t.RegisterCsiHandler('f', func(params ansi.Params) bool {
-		// Horizontal and Vertical Position [ansi.HVP]
-		width, height := t.Width(), t.Height()
-		row, _, _ := params.Param(0, 1)
-		col, _, _ := params.Param(1, 1)
-		y := min(height-1, row-1)
-		x := min(width-1, col-1)
-		t.setCursor(x, y)
-		return true
-	})
+	// Horizontal and Vertical Position [ansi.HVP] is the same as [ansi.CUP].
+	t.RegisterCsiHandler('f', cup)
 
 	t.RegisterCsiHandler('g', func(params ansi.Params) bool {
 		// Tab Clear [ansi.TBC]
@@ -581,8 +646,10 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 		case 0:
 			x, _ := t.scr.CursorPosition()
 			t.tabstops.Reset(x)
+			delete(t.htsStops, x)
 		case 3:
 			t.tabstops.Clear()
+			t.htsStops = nil
 		default:
 			return false
 		}
@@ -631,10 +698,10 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 		case 5: // Operating Status
 			// We're always ready ;)
 			// See: https://vt100.net/docs/vt510-rm/DSR-OS.html
-			t.buf.WriteString(ansi.DeviceStatusReport(ansi.DECStatusReport(0)))
+			t.respond(ansi.DeviceStatusReport(ansi.ANSIStatusReport(0)))
 		case 6: // Cursor Position Report [ansi.CPR]
-			x, y := t.scr.CursorPosition()
-			t.buf.WriteString(ansi.CursorPositionReport(x+1, y+1))
+			x, y := t.relativeCursorPosition()
+			t.respond(ansi.CursorPositionReport(y+1, x+1))
 		default:
 			return false
 		}
@@ -650,8 +717,8 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 
 		switch n {
 		case 6: // Extended Cursor Position Report [ansi.DECXCPR]
-			x, y := t.scr.CursorPosition()
-			t.buf.WriteString(ansi.ExtendedCursorPositionReport(x+1, y+1, 0)) // We don't support page numbers
+			x, y := t.relativeCursorPosition()
+			t.respond(ansi.ExtendedCursorPositionReport(y+1, x+1, 0)) // We don't support page numbers
 		default:
 			return false
 		}
@@ -659,6 +726,49 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 		return true
 	})
 
+	t.RegisterCsiHandler(ansi.Command(0, '!', 'p'), func(ansi.Params) bool {
+		// Soft Terminal Reset [ansi.DECSTR]
+		t.softReset()
+		return true
+	})
+
+	t.RegisterCsiHandler(ansi.Command(0, '$', 'x'), func(params ansi.Params) bool {
+		// Fill Rectangular Area [ansi.DECFRA]
+		c, _, _ := params.Param(0, 0)
+		if (c < 32 || c > 126) && (c < 160 || c > 255) {
+			return false
+		}
+		if rect, ok := t.rectangularArea(params, 1); ok {
+			t.fillRectangularArea(rune(c), rect)
+		}
+		return true
+	})
+
+	t.RegisterCsiHandler(ansi.Command(0, '$', 'z'), func(params ansi.Params) bool {
+		// Erase Rectangular Area [ansi.DECERA]
+		if rect, ok := t.rectangularArea(params, 0); ok {
+			t.eraseRectangularArea(rect)
+		}
+		return true
+	})
+
+	t.RegisterCsiHandler(ansi.Command(0, '$', 'v'), func(params ansi.Params) bool {
+		// Copy Rectangular Area [ansi.DECCRA]
+		// The page parameters are ignored.
+		rect, ok := t.rectangularArea(params, 0)
+		if !ok {
+			return true
+		}
+		origin := cellbuf.Pos(0, 0)
+		if t.isModeSet(ansi.DECOM) {
+			origin = t.scr.ScrollRegion().Min
+		}
+		top, _, _ := params.Param(5, 1)
+		left, _, _ := params.Param(6, 1)
+		t.scr.CopyRect(rect, cellbuf.Pos(origin.X+max(left, 1)-1, origin.Y+max(top, 1)-1))
+		return true
+	})
+
 	t.RegisterCsiHandler(ansi.Command(0, '$', 'p'), func(params ansi.Params) bool {
 		// Request Mode [ansi.DECRQM] - ANSI
 		t.handleRequestMode(params, true)
@@ -673,11 +783,17 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 
 	t.RegisterCsiHandler(ansi.Command(0, ' ', 'q'), func(params ansi.Params) bool {
 		// Set Cursor Style [ansi.DECSCUSR]
-		style := 1
-		if param, _, ok := params.Param(0, 0); ok && param > style {
-			style = param
+		// 0 and 1 are a blinking block, 2 is a steady block, 3 and 4 are a
+		// blinking and steady underline, and 5 and 6 are a blinking and
+		// steady bar.
+		style, _, _ := params.Param(0, 1)
+		if style < 1 {
+			style = 1
 		}
-		t.scr.setCursorStyle(CursorStyle((style/2)+1), style%2 == 1)
+		if style > 6 {
+			return false
+		}
+		t.scr.setCursorStyle(CursorStyle((style-1)/2), style%2 == 1)
 		return true
 	})
 
@@ -698,6 +814,13 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 			return false
 		}
 
+		// Keep the margins inside the screen so that scrolling still
+		// happens at the bottom of the screen.
+		bottom = min(bottom, height)
+		if top > bottom {
+			return false
+		}
+
 		// Rect is [x, y) which means y is exclusive. So the top margin
 		// is the top of the screen minus one.
 		t.scr.setVerticalMargins(top-1, bottom)
@@ -738,9 +861,30 @@ func (t *Terminal) registerDefaultCsiHandlers() {
 			t.setCursorPosition(0, 0)
 		} else {
 			// Save Current Cursor Position [ansi.SCOSC]
-			t.scr.SaveCursor()
+			t.saveCursor()
 		}
 
 		return true
 	})
+
+	t.RegisterCsiHandler('u', func(params ansi.Params) bool {
+		// Restore Current Cursor Position [ansi.SCORC]
+		t.restoreCursor()
+		return true
+	})
+
+	t.RegisterCsiHandler('t', func(params ansi.Params) bool {
+		// Window Operations [ansi.XTWINOPS]
+		op, _, _ := params.Param(0, 0)
+		which, _, _ := params.Param(1, 0)
+		switch op {
+		case ansi.PushTitleWinOp:
+			t.pushTitle(which)
+		case ansi.PopTitleWinOp:
+			t.popTitle(which)
+		default:
+			return false
+		}
+		return true
+	})
 }
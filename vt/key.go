@@ -26,6 +26,13 @@ func (t *Terminal) SendKey(k Key) {
 	ack := t.isModeSet(ansi.CursorKeysMode)    // Application cursor keys mode
 	akk := t.isModeSet(ansi.NumericKeypadMode) // Application keypad keys mode
 
+	// Enter sends a carriage return, followed by a line feed in
+	// [ansi.LineFeedNewLineMode].
+	enter := "\r"
+	if t.NewLineMode() {
+		enter = "\r\n"
+	}
+
 	switch k {
 	// Control keys
 	case Key{Code: KeySpace, Mod: ModCtrl}:
@@ -88,7 +95,7 @@ func (t *Terminal) SendKey(k Key) {
 		seq = "\x1f"
 
 	case Key{Code: KeyEnter}:
-		seq = "\r"
+		seq = enter
 	case Key{Code: KeyTab}:
 		seq = "\t"
 	case Key{Code: KeyBackspace}:
@@ -223,7 +230,7 @@ func (t *Terminal) SendKey(k Key) {
 		if akk {
 			seq = "\x1bOM"
 		} else {
-			seq = "\r"
+			seq = enter
 		}
 	case Key{Code: KeyKpEqual}:
 		if akk {
@@ -0,0 +1,103 @@
+package vt
+
+import "github.com/charmbracelet/x/cellbuf"
+
+// LineAttr represents the size attribute of a screen line.
+type LineAttr int
+
+// Line attributes.
+const (
+	// LineSingleWidth is a normal line, see [ansi.DECSWL].
+	LineSingleWidth LineAttr = iota
+	// LineDoubleWidth is a double-width line, see [ansi.DECDWL].
+	LineDoubleWidth
+	// LineDoubleHeightTop is the top half of a double-height, double-width
+	// line, see [ansi.DECDHLTop].
+	LineDoubleHeightTop
+	// LineDoubleHeightBottom is the bottom half of a double-height,
+	// double-width line, see [ansi.DECDHLBottom].
+	LineDoubleHeightBottom
+)
+
+// DoubleWidth reports whether the line characters are twice as wide, which
+// is the case of all lines but single-width ones.
+func (a LineAttr) DoubleWidth() bool {
+	return a != LineSingleWidth
+}
+
+// LineAttr returns the attribute of the line at y.
+func (s *Screen) LineAttr(y int) LineAttr {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lineAttr(y)
+}
+
+// lineAttr returns the attribute of the line at y. The caller must hold the
+// lock.
+func (s *Screen) lineAttr(y int) LineAttr {
+	if y >= 0 && y < len(s.lineAttrs) {
+		return s.lineAttrs[y]
+	}
+	return LineSingleWidth
+}
+
+// LineWidth returns the number of columns of the line at y, which is half the
+// screen width for double-width lines.
+func (s *Screen) LineWidth(y int) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lineWidth(y)
+}
+
+// lineWidth returns the number of columns of the line at y, which is half the
+// screen width for double-width lines. The caller must hold the lock.
+func (s *Screen) lineWidth(y int) int {
+	w := s.buf.Width()
+	if s.lineAttr(y).DoubleWidth() {
+		w = max(w/2, 1)
+	}
+	return w
+}
+
+// setLineAttr sets the attribute of the line at y. The cells that no longer
+// fit on a double-width line are erased and the cursor is moved back onto
+// the line.
+func (s *Screen) setLineAttr(y int, a LineAttr) {
+	s.mu.Lock()
+	if y < 0 || y >= len(s.lineAttrs) || s.lineAttrs[y] == a {
+		s.mu.Unlock()
+		return
+	}
+	s.lineAttrs[y] = a
+	width, w := s.buf.Width(), s.lineWidth(y)
+	if w < width {
+		s.buf.ClearRect(cellbuf.Rect(w, y, width-w, 1))
+	}
+	// The whole line is redrawn with the new size.
+	s.damage(RectDamage(cellbuf.Rect(0, y, width, 1)))
+	cur := s.cur.Position
+	s.mu.Unlock()
+	if cur.Y == y && cur.X >= w {
+		s.setCursor(w-1, y, false)
+	}
+}
+
+// resetLineAttrs resets the lines from y0 to y1 exclusive to single-width.
+// The caller must hold the lock.
+func (s *Screen) resetLineAttrs(y0, y1 int) {
+	for y := max(y0, 0); y < y1 && y < len(s.lineAttrs); y++ {
+		s.lineAttrs[y] = LineSingleWidth
+	}
+}
+
+// LineAttr returns the attribute of the line at y of the current screen.
+func (t *Terminal) LineAttr(y int) LineAttr {
+	return t.scr.LineAttr(y)
+}
+
+// setLineAttrs sets the attribute of the lines from y0 to y1 exclusive.
+func (t *Terminal) setLineAttrs(y0, y1 int, a LineAttr) {
+	for y := y0; y < y1; y++ {
+		t.scr.setLineAttr(y, a)
+	}
+}
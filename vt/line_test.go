@@ -0,0 +1,82 @@
+package vt
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+func TestDoubleWidthLine(t *testing.T) {
+	term := newTestTerminal(t, 10, 3)
+	term.Write([]byte("abcdefghij\x1b[1;8H\x1b#6"))
+
+	if got := term.LineAttr(0); got != LineDoubleWidth {
+		t.Fatalf("expected double-width line, got %v", got)
+	}
+	if got := term.scr.LineWidth(0); got != 5 {
+		t.Errorf("expected 5 columns, got %d", got)
+	}
+	// The cells past the half of the line are erased and the cursor is moved
+	// back onto the line.
+	assertLines(t, termText(term), []string{"abcde     ", "          ", "          "})
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(4, 0) {
+		t.Errorf("expected cursor at (4, 0), got %v", pos)
+	}
+
+	// Cursor movement is clamped to the line.
+	term.Write([]byte("\x1b[1;1H\x1b[20C"))
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(4, 0) {
+		t.Errorf("expected cursor at (4, 0), got %v", pos)
+	}
+	term.Write([]byte("\x1b[1;9H"))
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(4, 0) {
+		t.Errorf("expected cursor at (4, 0), got %v", pos)
+	}
+
+	// Moving to a single-width line allows the full width again.
+	term.Write([]byte("\x1b[2;9H"))
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(8, 1) {
+		t.Errorf("expected cursor at (8, 1), got %v", pos)
+	}
+
+	// Text wraps at the end of the double-width line.
+	term.Write([]byte("\x1b[1;1H1234567"))
+	assertLines(t, termText(term), []string{"12345     ", "67        ", "          "})
+
+	// Back to single-width.
+	term.Write([]byte("\x1b[1;1H\x1b#5\x1b[1;9H"))
+	if got := term.LineAttr(0); got != LineSingleWidth {
+		t.Errorf("expected single-width line, got %v", got)
+	}
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(8, 0) {
+		t.Errorf("expected cursor at (8, 0), got %v", pos)
+	}
+}
+
+func TestLineAttrScroll(t *testing.T) {
+	term := newTestTerminal(t, 4, 3)
+	term.Write([]byte("\x1b#3a\r\n\x1b#4a\r\nb"))
+	want := []LineAttr{LineDoubleHeightTop, LineDoubleHeightBottom, LineSingleWidth}
+	for y, a := range want {
+		if got := term.LineAttr(y); got != a {
+			t.Errorf("line %d: expected attribute %v, got %v", y, a, got)
+		}
+	}
+
+	// The attributes move with the lines.
+	term.Write([]byte("\r\n"))
+	want = []LineAttr{LineDoubleHeightBottom, LineSingleWidth, LineSingleWidth}
+	for y, a := range want {
+		if got := term.LineAttr(y); got != a {
+			t.Errorf("line %d: expected attribute %v, got %v", y, a, got)
+		}
+	}
+
+	// Erasing the display resets the attributes.
+	term.Write([]byte("\x1b[2J"))
+	for y := 0; y < 3; y++ {
+		if got := term.LineAttr(y); got != LineSingleWidth {
+			t.Errorf("line %d: expected single-width line, got %v", y, got)
+		}
+	}
+}
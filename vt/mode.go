@@ -6,7 +6,9 @@ import "github.com/charmbracelet/x/ansi"
 func (t *Terminal) resetModes() {
 	t.modes = map[ansi.Mode]ansi.ModeSetting{
 		// Recognized modes and their default values.
+		ansi.InsertReplaceMode:       ansi.ModeReset,
 		ansi.CursorKeysMode:          ansi.ModeReset,
+		ansi.ScreenMode:              ansi.ModeReset,
 		ansi.OriginMode:              ansi.ModeReset,
 		ansi.AutoWrapMode:            ansi.ModeSet,
 		ansi.X10MouseMode:            ansi.ModeReset,
@@ -20,10 +22,12 @@ func (t *Terminal) resetModes() {
 		ansi.AnyEventMouseMode:       ansi.ModeReset,
 		ansi.FocusEventMode:          ansi.ModeReset,
 		ansi.SgrExtMouseMode:         ansi.ModeReset,
+		ansi.SgrPixelExtMouseMode:    ansi.ModeReset,
 		ansi.AltScreenMode:           ansi.ModeReset,
 		ansi.SaveCursorMode:          ansi.ModeReset,
 		ansi.AltScreenSaveCursorMode: ansi.ModeReset,
 		ansi.BracketedPasteMode:      ansi.ModeReset,
+		ansi.SynchronizedOutputMode:  ansi.ModeReset,
 	}
 
 	// Set mode effects.
@@ -0,0 +1,64 @@
+package vt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestPrivateMode(t *testing.T) {
+	type call struct {
+		mode int
+		set  bool
+	}
+
+	term := newTestTerminal(t, 10, 4)
+	var calls []call
+	term.Callbacks.PrivateMode = func(mode int, set bool) {
+		calls = append(calls, call{mode, set})
+		// The terminal state is updated before the callback.
+		if mode == ansi.TextCursorEnableMode.Mode() && term.CursorVisible() != set {
+			t.Errorf("expected cursor visibility %v in callback", set)
+		}
+	}
+
+	term.Write([]byte("\x1b[?25l\x1b[?2004;1000;1006h\x1b[?1049h\x1b[?7l\x1b[?6h\x1b[?9999h"))
+	if term.CursorVisible() {
+		t.Error("expected cursor to be hidden")
+	}
+	if term.scr != &term.scrs[1] {
+		t.Error("expected the alternate screen to be active")
+	}
+	for _, mode := range []ansi.DECMode{
+		ansi.BracketedPasteMode,
+		ansi.NormalMouseMode,
+		ansi.SgrExtMouseMode,
+		ansi.OriginMode,
+	} {
+		if !term.isModeSet(mode) {
+			t.Errorf("expected mode %d to be set", mode)
+		}
+	}
+	if term.isModeSet(ansi.AutoWrapMode) {
+		t.Error("expected auto wrap mode to be reset")
+	}
+
+	// ANSI modes don't call the callback.
+	term.Write([]byte("\x1b[4h\x1b[?25h\x1b[?1049;9999l"))
+	if !term.CursorVisible() {
+		t.Error("expected cursor to be visible")
+	}
+	if term.scr != &term.scrs[0] {
+		t.Error("expected the main screen to be active")
+	}
+
+	want := []call{
+		{25, false}, {2004, true}, {1000, true}, {1006, true}, {1049, true},
+		{7, false}, {6, true}, {9999, true},
+		{25, true}, {1049, false}, {9999, false},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected calls %v, got %v", want, calls)
+	}
+}
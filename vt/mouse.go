@@ -1,7 +1,10 @@
 package vt
 
 import (
+	"fmt"
+
 	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
 	"github.com/charmbracelet/x/input"
 )
 
@@ -57,14 +60,20 @@ type MouseMotion = input.MouseMotionEvent
 
 // SendMouse sends a mouse event to the terminal. This can be any kind of mouse
 // events such as [MouseClick], [MouseRelease], [MouseWheel], or [MouseMotion].
+// Nothing is sent unless the event is reported by the active mouse mode, see
+// [Terminal.EncodeMouse].
 func (t *Terminal) SendMouse(m Mouse) {
-	// TODO: Support [Utf8ExtMouseMode], [UrxvtExtMouseMode], and
-	// [SgrPixelExtMouseMode].
-	var (
-		enc  ansi.Mode
-		mode ansi.Mode
-	)
+	t.buf.WriteString(t.EncodeMouse(m))
+}
 
+// MouseMode returns the active mouse tracking and encoding modes as their DEC
+// mode numbers. The tracking mode is one of [ansi.X10MouseMode],
+// [ansi.NormalMouseMode], [ansi.HighlightMouseMode],
+// [ansi.ButtonEventMouseMode], or [ansi.AnyEventMouseMode], or 0 when mouse
+// tracking is disabled. The encoding is one of [ansi.Utf8ExtMouseMode],
+// [ansi.SgrExtMouseMode], [ansi.UrxvtExtMouseMode], or
+// [ansi.SgrPixelExtMouseMode], or 0 for the default X10 encoding.
+func (t *Terminal) MouseMode() (tracking, encoding int) {
 	for _, m := range []ansi.DECMode{
 		ansi.X10MouseMode,         // Button press
 		ansi.NormalMouseMode,      // Button press/release
@@ -73,41 +82,99 @@ func (t *Terminal) SendMouse(m Mouse) {
 		ansi.AnyEventMouseMode,    // Button press/release/all motion
 	} {
 		if t.isModeSet(m) {
-			mode = m
+			tracking = m.Mode()
 		}
 	}
 
-	if mode == nil {
-		return
-	}
-
 	for _, e := range []ansi.DECMode{
-		// ansi.Utf8ExtMouseMode,
+		ansi.Utf8ExtMouseMode,
 		ansi.SgrExtMouseMode,
-		// ansi.UrxvtExtMouseMode,
-		// ansi.SgrPixelExtMouseMode,
+		ansi.UrxvtExtMouseMode,
+		ansi.SgrPixelExtMouseMode,
 	} {
 		if t.isModeSet(e) {
-			enc = e
+			encoding = e.Mode()
 		}
 	}
 
-	// Encode button
+	return tracking, encoding
+}
+
+// EncodeMouse returns the report sequence of a mouse event for the active
+// mouse tracking and encoding modes, see [Terminal.MouseMode]. It returns an
+// empty string when the event isn't reported by the active tracking mode, or
+// when its position can't be encoded.
+//
+// The event position is in cells, except with the
+// [ansi.SgrPixelExtMouseMode] encoding where it's in pixels. Use
+// [Terminal.PixelToCell] to get the cell position of pixel coordinates for
+// the other encodings.
+func (t *Terminal) EncodeMouse(m Mouse) string {
+	tracking, encoding := t.MouseMode()
 	mouse := m.Mouse()
 	_, isMotion := m.(MouseMotion)
 	_, isRelease := m.(MouseRelease)
-	b := ansi.EncodeMouseButton(mouse.Button, isMotion,
+
+	// TODO: Support [ansi.HighlightMouseMode] highlight tracking.
+	switch tracking {
+	case 0:
+		return ""
+	case ansi.X10MouseMode.Mode():
+		// Only button presses are reported, without modifiers.
+		if isMotion || isRelease {
+			return ""
+		}
+		mouse.Mod = 0
+	case ansi.NormalMouseMode.Mode(), ansi.HighlightMouseMode.Mode():
+		if isMotion {
+			return ""
+		}
+	case ansi.ButtonEventMouseMode.Mode():
+		if isMotion && mouse.Button == MouseNone {
+			return ""
+		}
+	}
+
+	button := mouse.Button
+	sgr := encoding == ansi.SgrExtMouseMode.Mode() || encoding == ansi.SgrPixelExtMouseMode.Mode()
+	if isRelease && !sgr {
+		// Only the SGR encoding reports which button was released.
+		button = MouseNone
+	}
+	b := ansi.EncodeMouseButton(button, isMotion,
 		mouse.Mod.Contains(ModShift),
 		mouse.Mod.Contains(ModAlt),
 		mouse.Mod.Contains(ModCtrl))
+	if b == 0xff {
+		return ""
+	}
 
-	switch enc {
-	// TODO: Support [ansi.HighlightMouseMode].
-	// TODO: Support [ansi.Utf8ExtMouseMode], [ansi.UrxvtExtMouseMode], and
-	// [ansi.SgrPixelExtMouseMode].
-	case nil: // X10 mouse encoding
-		t.buf.WriteString(ansi.MouseX10(b, mouse.X, mouse.Y))
-	case ansi.SgrExtMouseMode: // SGR mouse encoding
-		t.buf.WriteString(ansi.MouseSgr(b, mouse.X, mouse.Y, isRelease))
+	switch encoding {
+	case ansi.SgrExtMouseMode.Mode(), ansi.SgrPixelExtMouseMode.Mode(): // SGR mouse encoding
+		return ansi.MouseSgr(b, mouse.X, mouse.Y, isRelease)
+	case ansi.UrxvtExtMouseMode.Mode(): // URXVT mouse encoding
+		return fmt.Sprintf("\x1b[%d;%d;%dM", int(b)+32, mouse.X+1, mouse.Y+1)
+	case ansi.Utf8ExtMouseMode.Mode(): // UTF-8 mouse encoding
+		const maxUtf8 = 2014 // the highest coordinate encoded in two bytes
+		if mouse.X < 0 || mouse.Y < 0 || mouse.X > maxUtf8 || mouse.Y > maxUtf8 {
+			return ""
+		}
+		return "\x1b[M" + string(rune(b)+32) + string(rune(mouse.X)+33) + string(rune(mouse.Y)+33)
+	default: // X10 mouse encoding
+		if mouse.X < 0 || mouse.Y < 0 || mouse.X > 222 || mouse.Y > 222 {
+			return ""
+		}
+		return ansi.MouseX10(b, mouse.X, mouse.Y)
 	}
 }
+
+// PixelToCell returns the cell position of the given pixel coordinates, for
+// cells of cellWidth by cellHeight pixels. The position is clamped to the
+// terminal screen. A non-positive cell size is treated as 1 pixel.
+func (t *Terminal) PixelToCell(px, py, cellWidth, cellHeight int) Position {
+	cellWidth, cellHeight = max(cellWidth, 1), max(cellHeight, 1)
+	return cellbuf.Pos(
+		clamp(px/cellWidth, 0, t.Width()-1),
+		clamp(py/cellHeight, 0, t.Height()-1),
+	)
+}
@@ -0,0 +1,112 @@
+package vt
+
+import (
+	"io"
+	"testing"
+
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+func TestMouseMode(t *testing.T) {
+	term := newTestTerminal(t, 10, 4)
+	if tracking, encoding := term.MouseMode(); tracking != 0 || encoding != 0 {
+		t.Errorf("expected mouse tracking to be disabled, got %d and %d", tracking, encoding)
+	}
+
+	term.Write([]byte("\x1b[?1002h\x1b[?1006h"))
+	if tracking, encoding := term.MouseMode(); tracking != 1002 || encoding != 1006 {
+		t.Errorf("expected tracking 1002 and encoding 1006, got %d and %d", tracking, encoding)
+	}
+
+	term.Write([]byte("\x1b[?1016h"))
+	if _, encoding := term.MouseMode(); encoding != 1016 {
+		t.Errorf("expected encoding 1016, got %d", encoding)
+	}
+	term.Write([]byte("\x1b[?1016l"))
+
+	term.Write([]byte("\x1b[?1002l\x1b[?1006l"))
+	if tracking, encoding := term.MouseMode(); tracking != 0 || encoding != 0 {
+		t.Errorf("expected mouse tracking to be disabled, got %d and %d", tracking, encoding)
+	}
+}
+
+func TestEncodeMouse(t *testing.T) {
+	click := MouseClick{X: 1, Y: 2, Button: MouseLeft}
+	release := MouseRelease{X: 1, Y: 2, Button: MouseLeft}
+	drag := MouseMotion{X: 3, Y: 2, Button: MouseLeft}
+	motion := MouseMotion{X: 3, Y: 2}
+	ctrlClick := MouseClick{X: 1, Y: 2, Button: MouseLeft, Mod: ModCtrl}
+
+	cases := []struct {
+		name  string
+		modes string
+		event Mouse
+		want  string
+	}{
+		{"disabled", "", click, ""},
+		{"x10 click", "\x1b[?9h", click, "\x1b[M \"#"},
+		{"x10 no modifiers", "\x1b[?9h", ctrlClick, "\x1b[M \"#"},
+		{"x10 no release", "\x1b[?9h", release, ""},
+		{"normal click", "\x1b[?1000h", click, "\x1b[M \"#"},
+		{"normal ctrl click", "\x1b[?1000h", ctrlClick, "\x1b[M0\"#"},
+		{"normal release", "\x1b[?1000h", release, "\x1b[M#\"#"},
+		{"normal no drag", "\x1b[?1000h", drag, ""},
+		{"button event drag", "\x1b[?1002h", drag, "\x1b[M@$#"},
+		{"button event no motion", "\x1b[?1002h", motion, ""},
+		{"any event motion", "\x1b[?1003h", motion, "\x1b[MC$#"},
+		{"sgr click", "\x1b[?1000h\x1b[?1006h", click, "\x1b[<0;2;3M"},
+		{"sgr release", "\x1b[?1000h\x1b[?1006h", release, "\x1b[<0;2;3m"},
+		{"sgr wheel", "\x1b[?1000h\x1b[?1006h", MouseWheel{X: 1, Y: 2, Button: MouseWheelUp}, "\x1b[<64;2;3M"},
+		{"sgr pixels click", "\x1b[?1000h\x1b[?1016h", MouseClick{X: 95, Y: 130, Button: MouseLeft}, "\x1b[<0;96;131M"},
+		{"sgr pixels release", "\x1b[?1000h\x1b[?1016h", MouseRelease{X: 95, Y: 130, Button: MouseLeft}, "\x1b[<0;96;131m"},
+		{"sgr pixels over sgr", "\x1b[?1000h\x1b[?1006h\x1b[?1016h", MouseClick{X: 300, Y: 500, Button: MouseLeft}, "\x1b[<0;301;501M"},
+		{"urxvt click", "\x1b[?1000h\x1b[?1015h", click, "\x1b[32;2;3M"},
+		{"utf8 click", "\x1b[?1000h\x1b[?1005h", MouseClick{X: 200, Y: 2, Button: MouseLeft}, "\x1b[M é#"},
+		{"x10 out of range", "\x1b[?1000h", MouseClick{X: 223, Y: 2, Button: MouseLeft}, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 10, 4)
+			term.Write([]byte(tc.modes))
+			if got := term.EncodeMouse(tc.event); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+
+			// SendMouse writes the same report.
+			term.SendMouse(tc.event)
+			got, _ := io.ReadAll(&term.buf)
+			if string(got) != tc.want {
+				t.Errorf("expected sent report %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestPixelToCell(t *testing.T) {
+	term := newTestTerminal(t, 10, 4)
+	cases := []struct {
+		name   string
+		px, py int
+		want   Position
+	}{
+		{"origin", 0, 0, cellbuf.Pos(0, 0)},
+		{"inside first cell", 7, 15, cellbuf.Pos(0, 0)},
+		{"cell boundary", 8, 16, cellbuf.Pos(1, 1)},
+		{"before boundary", 23, 47, cellbuf.Pos(2, 2)},
+		{"last cell", 79, 63, cellbuf.Pos(9, 3)},
+		{"past the screen", 80, 64, cellbuf.Pos(9, 3)},
+		{"negative", -5, -20, cellbuf.Pos(0, 0)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := term.PixelToCell(tc.px, tc.py, 8, 16); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	if got := term.PixelToCell(3, 2, 0, 0); got != cellbuf.Pos(3, 2) {
+		t.Errorf("expected a zero cell size to be treated as 1 pixel, got %v", got)
+	}
+}
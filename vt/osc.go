@@ -2,6 +2,7 @@ package vt
 
 import (
 	"bytes"
+	"fmt"
 	"image/color"
 
 	"github.com/charmbracelet/x/ansi"
@@ -10,38 +11,85 @@ import (
 // handleOsc handles an OSC escape sequence.
 func (t *Terminal) handleOsc(cmd int, data []byte) {
 	if !t.handlers.handleOsc(cmd, data) {
-		t.logf("unhandled sequence: OSC %q", data)
+		t.unhandled(fmt.Sprintf("OSC %q", data))
 	}
 }
 
 func (t *Terminal) handleTitle(cmd int, data []byte) {
-	parts := bytes.Split(data, []byte{';'})
+	parts := bytes.SplitN(data, []byte{';'}, 2)
 	if len(parts) != 2 {
 		// Invalid, ignore
 		return
 	}
+	name := string(parts[1])
 	switch cmd {
 	case 0: // Set window title and icon name
-		name := string(parts[1])
-		t.iconName, t.title = name, name
-		if t.Callbacks.Title != nil {
-			t.Callbacks.Title(name)
-		}
-		if t.Callbacks.IconName != nil {
-			t.Callbacks.IconName(name)
-		}
+		t.setIconName(name)
+		t.setTitle(name)
 	case 1: // Set icon name
-		name := string(parts[1])
-		t.iconName = name
-		if t.Callbacks.IconName != nil {
-			t.Callbacks.IconName(name)
-		}
+		t.setIconName(name)
 	case 2: // Set window title
-		name := string(parts[1])
-		t.title = name
-		if t.Callbacks.Title != nil {
-			t.Callbacks.Title(name)
-		}
+		t.setTitle(name)
+	}
+}
+
+// setTitle sets the window title and calls the [Callbacks.Title] callback.
+func (t *Terminal) setTitle(name string) {
+	t.title = name
+	if t.Callbacks.Title != nil {
+		t.Callbacks.Title(name)
+	}
+}
+
+// setIconName sets the icon name and calls the [Callbacks.IconName] callback.
+func (t *Terminal) setIconName(name string) {
+	t.iconName = name
+	if t.Callbacks.IconName != nil {
+		t.Callbacks.IconName(name)
+	}
+}
+
+// maxTitleStack is the maximum number of entries in the title stack.
+const maxTitleStack = 10
+
+// titleStackEntry is an entry of the title stack. Only the pushed names are
+// set.
+type titleStackEntry struct {
+	iconName, title *string
+}
+
+// pushTitle pushes the icon name and/or window title on the title stack
+// depending on which, 0 for both, 1 for the icon name, and 2 for the window
+// title. The oldest entry is dropped when the stack is full.
+func (t *Terminal) pushTitle(which int) {
+	var e titleStackEntry
+	iconName, title := t.iconName, t.title
+	if which == 0 || which == 1 {
+		e.iconName = &iconName
+	}
+	if which == 0 || which == 2 {
+		e.title = &title
+	}
+	if len(t.titles) >= maxTitleStack {
+		t.titles = t.titles[1:]
+	}
+	t.titles = append(t.titles, e)
+}
+
+// popTitle pops an entry from the title stack and restores its icon name
+// and/or window title depending on which, 0 for both, 1 for the icon name,
+// and 2 for the window title.
+func (t *Terminal) popTitle(which int) {
+	if len(t.titles) == 0 {
+		return
+	}
+	e := t.titles[len(t.titles)-1]
+	t.titles = t.titles[:len(t.titles)-1]
+	if e.iconName != nil && (which == 0 || which == 1) {
+		t.setIconName(*e.iconName)
+	}
+	if e.title != nil && (which == 0 || which == 2) {
+		t.setTitle(*e.title)
 	}
 }
 
@@ -77,7 +125,7 @@ func (t *Terminal) handleDefaultColor(cmd int, data []byte) {
 			}
 
 			if enc != nil && col != nil {
-				t.buf.WriteString(enc(ansi.XRGBColorizer{Color: col}))
+				t.respond(enc(ansi.XRGBColorizer{Color: col}))
 			}
 		} else {
 			col := ansi.XParseColor(string(parts[1]))
@@ -106,12 +154,20 @@ func (t *Terminal) handleDefaultColor(cmd int, data []byte) {
 }
 
 func (t *Terminal) handleHyperlink(cmd int, data []byte) {
-	parts := bytes.Split(data, []byte{';'})
+	// OSC 8 ; params ; URI ST
+	// The URI may contain semicolons.
+	parts := bytes.SplitN(data, []byte{';'}, 3)
 	if len(parts) != 3 || cmd != 8 {
 		// Invalid, ignore
 		return
 	}
 
-	t.scr.cur.Link.URL = string(parts[1])
-	t.scr.cur.Link.Params = string(parts[2])
+	if len(parts[2]) == 0 {
+		// An empty URI closes the hyperlink, whatever the params are.
+		t.scr.cur.Link.Reset()
+		return
+	}
+
+	t.scr.cur.Link.Params = string(parts[1])
+	t.scr.cur.Link.URL = string(parts[2])
 }
@@ -0,0 +1,213 @@
+package vt
+
+import "github.com/charmbracelet/x/cellbuf"
+
+// resizeMain resizes the main screen keeping the cursor line on the screen.
+// Lines that no longer fit at the top of the screen are moved to the
+// scrollback, and lines are pulled back from the scrollback when the screen
+// grows taller. When rewrap is true, soft wrapped lines of the screen and the
+// scrollback are re-wrapped to the new width. The phantom argument tells
+// whether the main screen cursor is in the pending wrap state, and it returns
+// the new pending wrap state.
+func (t *Terminal) resizeMain(width, height int, rewrap, phantom bool) bool {
+	n := height - t.scrs[0].Height()
+	if rewrap {
+		// The whole scrollback is re-wrapped, the lines that don't fit on
+		// the screen go back to it.
+		n = len(t.scrollback.lines)
+	}
+	var history []Line
+	var historyWrapped []bool
+	if n > 0 {
+		history, historyWrapped = t.scrollback.pop(n)
+	}
+	scrolled, scrolledWrapped, pos, phantom := t.scrs[0].reflow(width, height, history, historyWrapped, rewrap, phantom)
+	for i, l := range scrolled {
+		t.scrollback.push(l, scrolledWrapped[i])
+	}
+	t.scrs[0].setCursor(pos.X, pos.Y, false)
+	return phantom
+}
+
+// reflowLine is a logical line made of soft wrapped screen lines.
+type reflowLine struct {
+	// cells holds the cells of the line, without wide cell placeholders.
+	cells []*Cell
+	// cursor is the index of the cell under the cursor, or -1 if the cursor
+	// isn't on this line. It can be past the last cell.
+	cursor int
+	// wrapped reports whether the line was soft wrapped into the next one.
+	// It's only set when the lines aren't joined.
+	wrapped bool
+}
+
+// reflow resizes the screen to the given size. The history lines, with their
+// soft wrapped flags, are added above the screen lines. When rewrap is true,
+// soft wrapped lines are joined and re-wrapped to the new width, otherwise
+// lines are truncated. All lines become single-width.
+//
+// Lines above the cursor that no longer fit are scrolled off the top of the
+// screen, after dropping the blank lines below the cursor. It returns the
+// scrolled lines with their soft wrapped flags and the new cursor position. The phantom argument tells
+// whether the cursor is in the pending wrap state, and it returns the new
+// pending wrap state.
+func (s *Screen) reflow(width, height int, history []Line, historyWrapped []bool, rewrap, phantom bool) (scrolled []Line, scrolledWrapped []bool, pos Position, _ bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A history line soft wrapped into the screen is joined with it.
+	src := append(history[:len(history):len(history)], s.buf.Lines...)
+	srcWrapped := append(historyWrapped[:len(historyWrapped):len(historyWrapped)], s.wrapped...)
+	cursor := cellbuf.Pos(s.cur.X, s.cur.Y+len(history))
+	lines := logicalLines(src, srcWrapped, cursor, rewrap)
+
+	// Lay out the logical lines using the new width.
+	var rows []Line
+	var wrapped []bool
+	for _, l := range lines {
+		row := make(Line, width)
+		x := 0
+		found := false
+		for i, c := range l.cells {
+			w := 1
+			if c != nil {
+				w = c.Width
+			}
+			if x+w > width && x > 0 {
+				if !rewrap {
+					break
+				}
+				rows = append(rows, row)
+				wrapped = append(wrapped, true)
+				row = make(Line, width)
+				x = 0
+			}
+			if w > width {
+				// The cell doesn't fit on a line.
+				c = c.Clone().Blank()
+				w = 1
+			}
+			if i == l.cursor {
+				found = true
+				pos = cellbuf.Pos(x, len(rows))
+				if phantom {
+					// The cursor was past the cell, only keep the pending
+					// wrap state if it's still at the end of the line.
+					pos.X += w
+					phantom = pos.X >= width
+					if phantom {
+						pos.X = width - 1
+					}
+				}
+			}
+			row[x] = c
+			for j := 1; j < w; j++ {
+				row[x+j] = &Cell{}
+			}
+			x += w
+		}
+		if l.cursor >= 0 && !found {
+			// The cursor is past the end of the line content.
+			pos = cellbuf.Pos(x+max(l.cursor-len(l.cells), 0), len(rows))
+			if pos.X == width && l.cursor == len(l.cells) {
+				// The cursor is right after the last cell which ends at the
+				// right edge, this is the pending wrap state.
+				pos.X = width - 1
+				phantom = true
+			}
+			for rewrap && pos.X >= width {
+				rows = append(rows, row)
+				wrapped = append(wrapped, false)
+				row = make(Line, width)
+				pos.X -= width
+				pos.Y++
+			}
+			pos.X = min(pos.X, width-1)
+		}
+		rows = append(rows, row)
+		wrapped = append(wrapped, l.wrapped)
+	}
+
+	// Drop the blank lines below the cursor that no longer fit.
+	for len(rows) > height && len(rows)-1 > pos.Y && rows[len(rows)-1].String() == "" {
+		rows = rows[:len(rows)-1]
+		wrapped = wrapped[:len(wrapped)-1]
+	}
+
+	// Scroll the lines above the cursor that no longer fit, and drop the
+	// remaining ones at the bottom.
+	if n := min(len(rows)-height, pos.Y); n > 0 {
+		scrolled, scrolledWrapped = rows[:n], wrapped[:n]
+		rows, wrapped = rows[n:], wrapped[n:]
+		pos.Y -= n
+	}
+	if len(rows) > height {
+		rows, wrapped = rows[:height], wrapped[:height]
+	}
+	for len(rows) < height {
+		rows = append(rows, make(Line, width))
+		wrapped = append(wrapped, false)
+	}
+
+	s.buf.Lines = rows
+	s.wrapped = wrapped
+	s.lineAttrs = make([]LineAttr, height)
+	s.scroll = s.buf.Bounds()
+	s.saved.X = clamp(s.saved.X, 0, width-1)
+	s.saved.Y = clamp(s.saved.Y, 0, height-1)
+	s.damage(ScreenDamage{width, height})
+
+	return scrolled, scrolledWrapped, pos, phantom
+}
+
+// logicalLines returns the rows with their trailing blank cells trimmed,
+// given whether each row is soft wrapped into the next one and the cursor
+// position in the rows. When join is true, soft wrapped rows are joined into
+// a single logical line.
+func logicalLines(rows []Line, rowsWrapped []bool, cursor Position, join bool) []reflowLine {
+	var lines []reflowLine
+	cur := reflowLine{cursor: -1}
+	for y, row := range rows {
+		wrapped := y < len(rowsWrapped) && rowsWrapped[y]
+		start := len(cur.cells)
+		for x, c := range row {
+			placeholder := c != nil && c.Width == 0
+			if x == cursor.X && y == cursor.Y {
+				cur.cursor = len(cur.cells)
+				if placeholder && len(cur.cells) > start {
+					// The cursor is on the wide cell.
+					cur.cursor--
+				}
+			}
+			if placeholder {
+				// Skip wide cell placeholders.
+				continue
+			}
+			cur.cells = append(cur.cells, c)
+		}
+
+		// Soft wrapped lines only have their unwritten cells trimmed.
+		cur.cells = append(cur.cells[:start], trimLine(cur.cells[start:], join && wrapped)...)
+
+		if !join || !wrapped {
+			cur.wrapped = !join && wrapped
+			lines = append(lines, cur)
+			cur = reflowLine{cursor: -1}
+		}
+	}
+	if len(cur.cells) > 0 || cur.cursor >= 0 {
+		lines = append(lines, cur)
+	}
+	return lines
+}
+
+// trimLine returns the cells without the trailing cells that were never
+// written to. Unless wrapped is true, the trailing blank cells are trimmed too.
+func trimLine(cells []*Cell, wrapped bool) []*Cell {
+	end := len(cells)
+	for end > 0 && (cells[end-1] == nil ||
+		!wrapped && cells[end-1].Equal(&cellbuf.BlankCell)) {
+		end--
+	}
+	return cells[:end]
+}
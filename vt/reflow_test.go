@@ -0,0 +1,191 @@
+package vt
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+func TestReflowOnResize(t *testing.T) {
+	cases := []struct {
+		name       string
+		w, h       int
+		input      string
+		resize     Position // new width and height
+		want       string
+		pos        Position
+		scrollback []string
+	}{
+		{
+			name: "narrow wrapped line",
+			w:    10, h: 4,
+			input:  "hello world",
+			resize: cellbuf.Pos(5, 4),
+			want:   "hello\n worl\nd\n",
+			pos:    cellbuf.Pos(1, 2),
+		},
+		{
+			name: "widen wrapped line",
+			w:    5, h: 3,
+			input:  "abcdefgh",
+			resize: cellbuf.Pos(10, 3),
+			want:   "abcdefgh\n\n",
+			pos:    cellbuf.Pos(8, 0),
+		},
+		{
+			name: "keep hard line breaks",
+			w:    4, h: 3,
+			input:  "ab\r\ncd",
+			resize: cellbuf.Pos(8, 3),
+			want:   "ab\ncd\n",
+			pos:    cellbuf.Pos(2, 1),
+		},
+		{
+			name: "cursor in the middle of a line",
+			w:    6, h: 3,
+			input:  "abcdefghij\x1b[1;3H",
+			resize: cellbuf.Pos(4, 3),
+			want:   "abcd\nefgh\nij",
+			pos:    cellbuf.Pos(2, 0),
+		},
+		{
+			name: "scroll lines that no longer fit",
+			w:    6, h: 2,
+			input:      "abcdefghij",
+			resize:     cellbuf.Pos(3, 2),
+			want:       "ghi\nj",
+			pos:        cellbuf.Pos(1, 1),
+			scrollback: []string{"abc", "def"},
+		},
+		{
+			name: "keep the cursor on the screen",
+			w:    6, h: 3,
+			input:  "abcdefghij\x1b[1;1H",
+			resize: cellbuf.Pos(3, 3),
+			want:   "abc\ndef\nghi",
+			pos:    cellbuf.Pos(0, 0),
+		},
+		{
+			name: "wide character",
+			w:    5, h: 2,
+			input:  "abcd你",
+			resize: cellbuf.Pos(6, 2),
+			want:   "abcd你\n",
+			pos:    cellbuf.Pos(5, 0),
+		},
+		{
+			name: "drop blank lines below the cursor",
+			w:    6, h: 3,
+			input:  "abcdefghij",
+			resize: cellbuf.Pos(4, 3),
+			want:   "abcd\nefgh\nij",
+			pos:    cellbuf.Pos(2, 2),
+		},
+		{
+			name: "pending wrap",
+			w:    4, h: 2,
+			input:  "abcd",
+			resize: cellbuf.Pos(6, 2),
+			want:   "abcd\n",
+			pos:    cellbuf.Pos(4, 0),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, tc.w, tc.h)
+			term.Write([]byte(tc.input))
+			term.Resize(tc.resize.X, tc.resize.Y)
+			if got := term.String(); got != tc.want {
+				t.Errorf("expected screen %q, got %q", tc.want, got)
+			}
+			if pos := term.CursorPosition(); pos != tc.pos {
+				t.Errorf("expected cursor position %v, got %v", tc.pos, pos)
+			}
+			var scrollback []string
+			for _, l := range term.Scrollback() {
+				scrollback = append(scrollback, l.String())
+			}
+			if len(scrollback) != len(tc.scrollback) {
+				t.Fatalf("expected scrollback %q, got %q", tc.scrollback, scrollback)
+			}
+			for i := range scrollback {
+				if scrollback[i] != tc.scrollback[i] {
+					t.Errorf("expected scrollback %q, got %q", tc.scrollback, scrollback)
+				}
+			}
+		})
+	}
+}
+
+func TestReflowPendingWrap(t *testing.T) {
+	term := newTestTerminal(t, 4, 2)
+	term.Write([]byte("abcd"))
+	term.Resize(6, 2)
+	term.Write([]byte("X"))
+	if got, want := term.String(), "abcdX\n"; got != want {
+		t.Errorf("expected screen %q, got %q", want, got)
+	}
+}
+
+func TestReflowScrollback(t *testing.T) {
+	term := newTestTerminal(t, 10, 2)
+	term.Write([]byte("first\r\nabcdefghijklm"))
+
+	// Narrowing wraps the line across the scrollback and the screen.
+	term.Resize(5, 2)
+	assertLines(t, scrollbackText(term), []string{"first", "abcde"})
+	assertLines(t, termText(term), []string{"fghij", "klm  "})
+
+	// Widening joins it back, and growing pulls it back whole.
+	term.Resize(10, 2)
+	assertLines(t, scrollbackText(term), []string{"first"})
+	assertLines(t, termText(term), []string{"abcdefghij", "klm       "})
+	term.Resize(10, 4)
+	assertLines(t, scrollbackText(term), nil)
+	assertLines(t, termText(term), []string{"first     ", "abcdefghij", "klm       ", "          "})
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(3, 2) {
+		t.Errorf("expected cursor at (3, 2), got %v", pos)
+	}
+
+	// Growing without widening joins the wrapped line too.
+	term = newTestTerminal(t, 5, 2)
+	term.Write([]byte("abcdefghijklm"))
+	term.Resize(10, 2)
+	term.Resize(5, 2)
+	term.Resize(10, 3)
+	assertLines(t, termText(term), []string{"abcdefghij", "klm       ", "          "})
+}
+
+func TestReflowCursorOnWideCell(t *testing.T) {
+	term := newTestTerminal(t, 6, 2)
+	term.Write([]byte("a世世\x1b[1;3H"))
+	term.Resize(4, 2)
+	assertLines(t, termText(term), []string{"a世 ", "世  "})
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(1, 0) {
+		t.Errorf("expected cursor on the first wide cell at (1, 0), got %v", pos)
+	}
+}
+
+func TestReflowOnResizeDisabled(t *testing.T) {
+	term := newTestTerminal(t, 5, 2)
+	term.ReflowOnResize = false
+	term.Write([]byte("abcdefgh"))
+	term.Resize(10, 2)
+	if got, want := term.String(), "abcde\nfgh"; got != want {
+		t.Errorf("expected screen %q, got %q", want, got)
+	}
+}
+
+func TestReflowAltScreen(t *testing.T) {
+	term := newTestTerminal(t, 5, 2)
+	term.Write([]byte("abcdefgh\x1b[?1049h12345678"))
+	term.Resize(10, 2)
+	if got, want := term.String(), "12345\n678"; got != want {
+		t.Errorf("expected alternate screen %q, got %q", want, got)
+	}
+	term.Write([]byte("\x1b[?1049l"))
+	if got, want := term.String(), "abcdefgh\n"; got != want {
+		t.Errorf("expected main screen %q, got %q", want, got)
+	}
+}
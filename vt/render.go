@@ -0,0 +1,259 @@
+package vt
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+// Render returns the content of the viewport, the current focused screen or
+// the scrollback lines it's scrolled to, with ANSI escape sequences. Each line
+// only emits the style and hyperlink changes between adjacent cells and ends
+// with a reset. Lines are joined by "\r\n". Indexed colors changed in the
+// terminal palette are emitted as RGB colors, and the reverse attribute of
+// the cells is toggled in reverse video mode, see [Terminal.ReverseVideo].
+// Writing the result to a terminal of the same size reproduces the viewport.
+func (t *Terminal) Render() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return cellbuf.Render(displayBuffer{t.viewport(), &t.colors, t.ReverseVideo()})
+}
+
+// ReverseVideo reports whether the screen is displayed in reverse video, with
+// the foreground and background colors swapped, see [ansi.DECSCNM]. The
+// screen cells are left unchanged, the colors are swapped by the renderers.
+func (t *Terminal) ReverseVideo() bool {
+	return t.isModeSet(ansi.ScreenMode)
+}
+
+// displayBuffer is a read-only [cellbuf.CellBuffer] that resolves the indexed
+// colors changed in the palette to their RGB value, and toggles the reverse
+// attribute of the cells in reverse video mode.
+type displayBuffer struct {
+	cellbuf.CellBuffer
+	colors  *[256]color.Color
+	reverse bool
+}
+
+// Cell implements [cellbuf.CellBuffer].
+func (b displayBuffer) Cell(x, y int) *Cell {
+	c := b.CellBuffer.Cell(x, y)
+	if c == nil {
+		return nil
+	}
+	fg, bg, ul := b.color(c.Style.Fg), b.color(c.Style.Bg), b.color(c.Style.Ul)
+	if !b.reverse && fg == c.Style.Fg && bg == c.Style.Bg && ul == c.Style.Ul {
+		return c
+	}
+	c = c.Clone()
+	c.Style.Fg, c.Style.Bg, c.Style.Ul = fg, bg, ul
+	if b.reverse {
+		c.Style.Attrs ^= cellbuf.ReverseAttr
+	}
+	return c
+}
+
+// color returns the palette color of the given indexed color if it was
+// changed, otherwise the color itself.
+func (b displayBuffer) color(c ansi.Color) ansi.Color {
+	var i int
+	switch c := c.(type) {
+	case ansi.BasicColor:
+		i = int(c)
+	case ansi.ExtendedColor:
+		i = int(c)
+	default:
+		return c
+	}
+	if p := b.colors[i]; p != nil {
+		r, g, bl, _ := p.RGBA()
+		return ansi.TrueColor(r>>8<<16 | g>>8<<8 | bl>>8)
+	}
+	return c
+}
+
+// HTML returns the content of the viewport as HTML wrapped in a <pre>
+// element. Cells with the same style are grouped in <span> elements
+// with inline CSS, and hyperlinks are rendered as <a> elements. Only http,
+// https, mailto, ftp and file links are rendered, the text of other links is
+// written as is. Indexed colors are resolved using the terminal palette.
+// Trailing blank cells of each line are trimmed.
+func (t *Terminal) HTML() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var b strings.Builder
+	if t.ReverseVideo() {
+		// The default colors are swapped too.
+		b.WriteString(`<pre style="color:` + cssRGB(t.BackgroundColor()) +
+			`;background-color:` + cssRGB(t.ForegroundColor()) + `">`)
+	} else {
+		b.WriteString("<pre>")
+	}
+	v := t.viewport()
+	for y := 0; y < t.Height(); y++ {
+		if y > 0 {
+			b.WriteByte('\n')
+		}
+		t.writeHTMLLine(&b, v, y)
+	}
+	b.WriteString("</pre>")
+	return b.String()
+}
+
+// writeHTMLLine writes the yth line of the viewport as HTML.
+func (t *Terminal) writeHTMLLine(b *strings.Builder, v *viewport, y int) {
+	var cells []Cell
+	end := 0
+	for x := 0; x < t.Width(); x++ {
+		c := v.Cell(x, y)
+		if c == nil || c.Width == 0 {
+			// Skip wide cell placeholders.
+			continue
+		}
+		cells = append(cells, *c)
+		if !c.Equal(&cellbuf.BlankCell) {
+			end = len(cells)
+		}
+	}
+	cells = cells[:end]
+
+	for i := 0; i < len(cells); {
+		// Group adjacent cells with the same style and link.
+		style, link := cells[i].Style, cells[i].Link
+		j := i + 1
+		for j < len(cells) && cells[j].Style.Equal(&style) && cells[j].Link == link {
+			j++
+		}
+
+		anchor := isSafeLink(link.URL)
+		if anchor {
+			b.WriteString(`<a href="` + html.EscapeString(link.URL) + `">`)
+		}
+		css := t.htmlStyle(style)
+		if css != "" {
+			b.WriteString(`<span style="` + css + `">`)
+		}
+		for _, cell := range cells[i:j] {
+			b.WriteString(html.EscapeString(cell.String()))
+		}
+		if css != "" {
+			b.WriteString("</span>")
+		}
+		if anchor {
+			b.WriteString("</a>")
+		}
+
+		i = j
+	}
+}
+
+// isSafeLink reports whether the given hyperlink URL can be rendered as an
+// HTML anchor. This rejects schemes that run code, like javascript and data.
+func isSafeLink(s string) bool {
+	if s == "" {
+		return false
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto", "ftp", "file":
+		return true
+	}
+	return false
+}
+
+// htmlStyle returns the inline CSS of the given cell style.
+func (t *Terminal) htmlStyle(s Style) string {
+	var props []string
+
+	fg, bg := t.htmlColor(s.Fg), t.htmlColor(s.Bg)
+	if (s.Attrs&cellbuf.ReverseAttr != 0) != t.ReverseVideo() {
+		if fg == "" {
+			fg = cssRGB(t.ForegroundColor())
+		}
+		if bg == "" {
+			bg = cssRGB(t.BackgroundColor())
+		}
+		fg, bg = bg, fg
+	}
+	if fg != "" {
+		props = append(props, "color:"+fg)
+	}
+	if bg != "" {
+		props = append(props, "background-color:"+bg)
+	}
+
+	if s.Attrs&cellbuf.BoldAttr != 0 {
+		props = append(props, "font-weight:bold")
+	}
+	if s.Attrs&cellbuf.FaintAttr != 0 {
+		props = append(props, "opacity:0.5")
+	}
+	if s.Attrs&cellbuf.ItalicAttr != 0 {
+		props = append(props, "font-style:italic")
+	}
+	if s.Attrs&cellbuf.ConcealAttr != 0 {
+		props = append(props, "visibility:hidden")
+	}
+
+	var decorations []string
+	if s.UlStyle != cellbuf.NoUnderline {
+		decorations = append(decorations, "underline")
+	}
+	if s.Attrs&cellbuf.StrikethroughAttr != 0 {
+		decorations = append(decorations, "line-through")
+	}
+	if s.Attrs&cellbuf.OverlineAttr != 0 {
+		decorations = append(decorations, "overline")
+	}
+	if len(decorations) > 0 {
+		props = append(props, "text-decoration:"+strings.Join(decorations, " "))
+	}
+	switch s.UlStyle {
+	case cellbuf.DoubleUnderline:
+		props = append(props, "text-decoration-style:double")
+	case cellbuf.CurlyUnderline:
+		props = append(props, "text-decoration-style:wavy")
+	case cellbuf.DottedUnderline:
+		props = append(props, "text-decoration-style:dotted")
+	case cellbuf.DashedUnderline:
+		props = append(props, "text-decoration-style:dashed")
+	}
+	if ul := t.htmlColor(s.Ul); ul != "" && s.UlStyle != cellbuf.NoUnderline {
+		props = append(props, "text-decoration-color:"+ul)
+	}
+
+	return strings.Join(props, ";")
+}
+
+// htmlColor returns the CSS color of the given cell color. Indexed colors are
+// resolved using the terminal palette. It returns an empty string for the
+// default color.
+func (t *Terminal) htmlColor(c ansi.Color) string {
+	switch c := c.(type) {
+	case nil:
+		return ""
+	case ansi.BasicColor:
+		return cssRGB(t.IndexedColor(int(c)))
+	case ansi.ExtendedColor:
+		return cssRGB(t.IndexedColor(int(c)))
+	default:
+		return cssRGB(c)
+	}
+}
+
+// cssRGB returns the CSS rgb() notation of the given color.
+func cssRGB(c color.Color) string {
+	if c == nil {
+		return ""
+	}
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("rgb(%d,%d,%d)", r>>8, g>>8, b>>8)
+}
@@ -0,0 +1,183 @@
+package vt
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+func TestRenderRoundTrip(t *testing.T) {
+	const w, h = 12, 4
+	term := newTestTerminal(t, w, h)
+	term.Write([]byte("\x1b[1;31mbold red\x1b[m plain\r\n"))
+	term.Write([]byte("\x1b[3;4;38;5;123;48;2;10;20;30m你好\x1b[m \x1b[7mrev\x1b[m\r\n"))
+	term.Write([]byte(ansi.SetHyperlink("https://example.com") + "link" + ansi.ResetHyperlink() + "\x1b[44m  \x1b[m end\r\n"))
+	term.Write([]byte("\x1b[4;12H\x1b[32mX"))
+
+	replay := newTestTerminal(t, w, h)
+	replay.Write([]byte(term.Render()))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			want, _ := term.Cell(x, y)
+			got, _ := replay.Cell(x, y)
+			if !got.Equal(&want) {
+				t.Errorf("cell at %d,%d doesn't match:\nwant: %#v\ngot:  %#v", x, y, want, got)
+			}
+		}
+	}
+}
+
+func TestRenderBlink(t *testing.T) {
+	term := newTestTerminal(t, 4, 3)
+	term.Write([]byte("\x1b[5mabcd\x1b[6mef\x1b[25mg"))
+	// Reflow the wrapped line.
+	term.Resize(8, 3)
+	if cell, _ := term.Cell(5, 0); cell.Style.Attrs != cellbuf.SlowBlinkAttr|cellbuf.RapidBlinkAttr {
+		t.Errorf("expected a blinking cell, got %#v", cell)
+	}
+
+	want := "\x1b[5mabcd\x1b[6mef\x1b[mg\r\n\r\n"
+	if got := term.Render(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRenderLine(t *testing.T) {
+	term := newTestTerminal(t, 10, 2)
+	term.Write([]byte("\x1b[1ma\x1b[31mb\x1b[22mc\x1b[m d"))
+
+	want := "\x1b[1ma\x1b[31mb\x1b[22mc\x1b[m d\r\n"
+	if got := term.Render(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "plain",
+			input: "hello\r\nworld",
+			want:  "<pre>hello\nworld</pre>",
+		},
+		{
+			name:  "escaped",
+			input: "<a&b>",
+			want:  "<pre>&lt;a&amp;b&gt;\n</pre>",
+		},
+		{
+			name:  "styled runs",
+			input: "\x1b[1;31mab\x1b[22;3mc\x1b[m d",
+			want: `<pre><span style="color:rgb(128,0,0);font-weight:bold">ab</span>` +
+				`<span style="color:rgb(128,0,0);font-style:italic">c</span> d` + "\n</pre>",
+		},
+		{
+			name:  "extended and true colors",
+			input: "\x1b[38;5;196;48;2;1;2;3;4mx",
+			want:  `<pre><span style="color:rgb(255,0,0);background-color:rgb(1,2,3);text-decoration:underline">x</span>` + "\n</pre>",
+		},
+		{
+			name:  "reverse default colors",
+			input: "\x1b[7mx",
+			want:  `<pre><span style="color:rgb(0,0,0);background-color:rgb(255,255,255)">x</span>` + "\n</pre>",
+		},
+		{
+			name:  "hyperlink",
+			input: ansi.SetHyperlink("https://example.com/?a=1&b=2") + "go" + ansi.ResetHyperlink() + "!",
+			want:  `<pre><a href="https://example.com/?a=1&amp;b=2">go</a>!` + "\n</pre>",
+		},
+		{
+			name:  "unsafe hyperlink",
+			input: "\x1b]8;;javascript:alert(1)\x1b\\x" + ansi.ResetHyperlink(),
+			want:  "<pre>x\n</pre>",
+		},
+		{
+			name:  "relative hyperlink",
+			input: ansi.SetHyperlink("/etc/passwd") + "x" + ansi.ResetHyperlink(),
+			want:  "<pre>x\n</pre>",
+		},
+		{
+			name:  "mailto hyperlink",
+			input: ansi.SetHyperlink("MAILTO:a@b.c") + "x" + ansi.ResetHyperlink(),
+			want:  `<pre><a href="MAILTO:a@b.c">x</a>` + "\n</pre>",
+		},
+		{
+			name:  "wide characters",
+			input: "\x1b[32m你好\x1b[m x",
+			want:  `<pre><span style="color:rgb(0,128,0)">你好</span> x` + "\n</pre>",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 10, 2)
+			term.Write([]byte(tc.input))
+			if got := term.HTML(); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRenderPalette(t *testing.T) {
+	term := newTestTerminal(t, 4, 1)
+	term.SetIndexedColor(1, color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff})
+	term.Write([]byte("\x1b[31ma\x1b[38;5;1mb\x1b[32mc\x1b[m"))
+
+	// The changed red renders as RGB, the unchanged green stays indexed.
+	want := "\x1b[38;2;18;52;86mab\x1b[32mc\x1b[m"
+	if got := term.Render(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	want = `<pre><span style="color:rgb(18,52,86)">ab</span><span style="color:rgb(0,128,0)">c</span></pre>`
+	if got := term.HTML(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// The stored cells keep their indexed colors.
+	if c, _ := term.Cell(0, 0); c.Style.Fg != ansi.Red {
+		t.Errorf("expected the stored cell to be red, got %v", c.Style.Fg)
+	}
+}
+
+func TestReverseVideo(t *testing.T) {
+	term := newTestTerminal(t, 4, 1)
+	term.Write([]byte("a\x1b[7mb\x1b[m\x1b[?5h"))
+	if !term.ReverseVideo() {
+		t.Fatal("expected reverse video")
+	}
+
+	want := "\x1b[7ma\x1b[mb\x1b[7m  \x1b[m"
+	if got := term.Render(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	want = `<pre style="color:rgb(0,0,0);background-color:rgb(255,255,255)">` +
+		`<span style="color:rgb(0,0,0);background-color:rgb(255,255,255)">a</span>b</pre>`
+	if got := term.HTML(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// The cells are unchanged.
+	if c, _ := term.Cell(0, 0); c.Style.Attrs&cellbuf.ReverseAttr != 0 {
+		t.Errorf("expected the stored cell not to be reversed, got %#v", c.Style)
+	}
+	if c, _ := term.Cell(1, 0); c.Style.Attrs&cellbuf.ReverseAttr == 0 {
+		t.Errorf("expected the stored cell to be reversed, got %#v", c.Style)
+	}
+
+	term.Write([]byte("\x1b[?5l"))
+	if term.ReverseVideo() {
+		t.Fatal("expected no reverse video")
+	}
+	want = "a\x1b[7mb\x1b[m"
+	if got := term.Render(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,133 @@
+package vt
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+// dirtyInput changes the terminal modes, margins, graphic rendition, tab
+// stops, and colors.
+const dirtyInput = "line1\r\nline2\r\nline3\r\nline4" +
+	"\x1b[4h" + // insert mode
+	"\x1b[?6h" + // origin mode
+	"\x1b[?7l" + // no auto wrap
+	"\x1b[?1h" + // cursor keys mode
+	"\x1b[?25l" + // hidden cursor
+	"\x1b[3g" + // clear all tab stops
+	"\x1b(0" + // special drawing G0
+	"\x1b]10;#ff0000\x07" + // foreground color
+	"\x1b[2;3r" + // scroll region
+	"\x1b[2;2H" + // cursor inside the scroll region
+	"\x1b[1;31m" // bold red
+
+func assertModes(t *testing.T, term *Terminal, modes map[ansi.Mode]ansi.ModeSetting) {
+	t.Helper()
+	for mode, want := range modes {
+		if got := term.modes[mode]; got != want {
+			t.Errorf("expected mode %v to be %v, got %v", mode, want, got)
+		}
+	}
+}
+
+func TestFullReset(t *testing.T) {
+	term := newTestTerminal(t, 10, 4)
+	term.Write([]byte(dirtyInput))
+	term.Write([]byte("\r\n\x1b[?1049h"))
+	term.Write([]byte(ansi.RIS))
+
+	if term.scr != &term.scrs[0] {
+		t.Error("expected the main screen to be active")
+	}
+	assertLines(t, termText(term), []string{"          ", "          ", "          ", "          "})
+	assertLines(t, scrollbackText(term), nil)
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(0, 0) {
+		t.Errorf("expected cursor at (0, 0), got %v", pos)
+	}
+	if !term.CursorVisible() {
+		t.Error("expected cursor to be visible")
+	}
+	assertModes(t, term, map[ansi.Mode]ansi.ModeSetting{
+		ansi.InsertReplaceMode:       ansi.ModeReset,
+		ansi.OriginMode:              ansi.ModeReset,
+		ansi.AutoWrapMode:            ansi.ModeSet,
+		ansi.CursorKeysMode:          ansi.ModeReset,
+		ansi.TextCursorEnableMode:    ansi.ModeSet,
+		ansi.AltScreenSaveCursorMode: ansi.ModeReset,
+	})
+	if got, want := term.TabStops(), []int{0, 8}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("expected tab stops %v, got %v", want, got)
+	}
+	if term.ForegroundColor() != color.Color(defaultFg) {
+		t.Errorf("expected default foreground color, got %v", term.ForegroundColor())
+	}
+	if r := term.scr.ScrollRegion(); r != cellbuf.Rect(0, 0, 10, 4) {
+		t.Errorf("expected full screen scroll region, got %v", r)
+	}
+
+	// Default graphic rendition and character set.
+	term.Write([]byte("q"))
+	cell, _ := term.Cell(0, 0)
+	if cell.String() != "q" || !cell.Style.Empty() {
+		t.Errorf("expected plain %q cell, got %q with style %#v", "q", cell.String(), cell.Style)
+	}
+}
+
+func TestSoftReset(t *testing.T) {
+	term := newTestTerminal(t, 10, 4)
+	term.Write([]byte(dirtyInput))
+	term.Write([]byte(ansi.DECSTR))
+
+	// The screen content and the cursor position are kept.
+	assertLines(t, termText(term), []string{"line1     ", "line2     ", "line3     ", "line4     "})
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(1, 2) {
+		t.Errorf("expected cursor at (1, 2), got %v", pos)
+	}
+	if !term.CursorVisible() {
+		t.Error("expected cursor to be visible")
+	}
+	assertModes(t, term, map[ansi.Mode]ansi.ModeSetting{
+		ansi.InsertReplaceMode:    ansi.ModeReset,
+		ansi.OriginMode:           ansi.ModeReset,
+		ansi.AutoWrapMode:         ansi.ModeSet,
+		ansi.CursorKeysMode:       ansi.ModeReset,
+		ansi.TextCursorEnableMode: ansi.ModeSet,
+	})
+	if r := term.scr.ScrollRegion(); r != cellbuf.Rect(0, 0, 10, 4) {
+		t.Errorf("expected full screen scroll region, got %v", r)
+	}
+
+	// Default graphic rendition and character set.
+	term.Write([]byte("q"))
+	cell, _ := term.Cell(1, 2)
+	if cell.String() != "q" || !cell.Style.Empty() {
+		t.Errorf("expected plain %q cell, got %q with style %#v", "q", cell.String(), cell.Style)
+	}
+
+	// The saved cursor is reset to the home position.
+	term.Write([]byte("\x1b8"))
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(0, 0) {
+		t.Errorf("expected restored cursor at (0, 0), got %v", pos)
+	}
+}
+
+func TestReset(t *testing.T) {
+	term := newTestTerminal(t, 10, 2)
+	term.Write([]byte("abc\x1b[31m\x1b[?25l\x1b]"))
+	term.Reset()
+	term.Write([]byte("d"))
+	assertLines(t, termText(term), []string{"d         ", "          "})
+	if !term.CursorVisible() {
+		t.Error("expected cursor to be visible")
+	}
+
+	term.Write([]byte("\x1b[1m\x1b[4h"))
+	term.SoftReset()
+	term.Write([]byte("e"))
+	assertLines(t, termText(term), []string{"de        ", "          "})
+	if cell, _ := term.Cell(1, 0); !cell.Style.Empty() {
+		t.Errorf("expected plain cell, got style %#v", cell.Style)
+	}
+}
@@ -1,6 +1,7 @@
 package vt
 
 import (
+	"strings"
 	"sync"
 
 	"github.com/charmbracelet/x/cellbuf"
@@ -10,10 +11,22 @@ import (
 type Screen struct {
 	// cb is the callbacks struct to use.
 	cb *Callbacks
+	// dmg accumulates the damaged areas of the screen.
+	dmg *damageTracker
+	// bce reports whether blank cells take the pen background color, it's
+	// enabled when nil.
+	bce *bool
 	// The buffer of the screen.
 	buf Buffer
+	// wrapped reports whether each line was soft wrapped into the next one.
+	wrapped []bool
+	// lineAttrs holds the size attribute of each line.
+	lineAttrs []LineAttr
 	// The cur of the screen.
 	cur, saved Cursor
+	// savedState is the terminal state saved along with the cursor, nil when
+	// it wasn't saved.
+	savedState *savedCursor
 	// scroll is the scroll region.
 	scroll Rectangle
 	// mutex for the screen.
@@ -33,9 +46,13 @@ func NewScreen(w, h int) *Screen {
 func (s *Screen) Reset() {
 	s.mu.Lock()
 	s.buf.Clear()
+	s.clearWrapped(0, len(s.wrapped))
+	s.resetLineAttrs(0, len(s.lineAttrs))
 	s.cur = Cursor{}
 	s.saved = Cursor{}
+	s.savedState = nil
 	s.scroll = s.buf.Bounds()
+	s.damage(ScreenDamage{s.buf.Width(), s.buf.Height()})
 	s.mu.Unlock()
 }
 
@@ -59,16 +76,49 @@ func (s *Screen) SetCell(x, y int, c *Cell) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	v := s.buf.SetCell(x, y, c)
-	if v && s.cb.Damage != nil {
+	if v {
 		width := 1
 		if c != nil {
 			width = c.Width
 		}
-		s.cb.Damage(CellDamage{x, y, width})
+		s.damage(CellDamage{x, y, width})
 	}
 	return v
 }
 
+// cloneLine returns a copy of the line at the given y position.
+func (s *Screen) cloneLine(y int) Line {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	line := s.buf.Line(y)
+	if line == nil {
+		return nil
+	}
+	l := make(Line, len(line))
+	for i, c := range line {
+		if c != nil {
+			l[i] = c.Clone()
+		}
+	}
+	return l
+}
+
+// String returns the plain text content of the screen with one line per row
+// and the trailing blanks of each line trimmed. It ignores cell styles, except
+// that concealed cells are replaced with spaces.
+func (s *Screen) String() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var b strings.Builder
+	for y := 0; y < s.buf.Height(); y++ {
+		if y > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(lineText(s.buf.Line(y)))
+	}
+	return b.String()
+}
+
 // Height returns the height of the screen.
 func (s *Screen) Height() int {
 	s.mu.RLock()
@@ -80,10 +130,9 @@ func (s *Screen) Height() int {
 func (s *Screen) Resize(width int, height int) {
 	s.mu.Lock()
 	s.buf.Resize(width, height)
+	s.resizeWrapped(height)
 	s.scroll = s.buf.Bounds()
-	if s.cb != nil && s.cb.Damage != nil {
-		s.cb.Damage(ScreenDamage{width, height})
-	}
+	s.damage(ScreenDamage{width, height})
 	s.mu.Unlock()
 }
 
@@ -99,14 +148,14 @@ func (s *Screen) Clear(rects ...Rectangle) {
 	s.mu.Lock()
 	if len(rects) == 0 {
 		s.buf.Clear()
+		s.clearWrapped(0, len(s.wrapped))
+		s.resetLineAttrs(0, len(s.lineAttrs))
+		s.damage(ScreenDamage{s.buf.Width(), s.buf.Height()})
 	} else {
 		for _, r := range rects {
 			s.buf.ClearRect(r)
-		}
-	}
-	if s.cb.Damage != nil {
-		for _, r := range rects {
-			s.cb.Damage(RectDamage(r))
+			s.clearWrappedRect(r)
+			s.damage(RectDamage(r))
 		}
 	}
 	s.mu.Unlock()
@@ -118,16 +167,57 @@ func (s *Screen) Fill(c *Cell, rects ...Rectangle) {
 	defer s.mu.Unlock()
 	if len(rects) == 0 {
 		s.buf.Fill(c)
+		s.clearWrapped(0, len(s.wrapped))
+		s.resetLineAttrs(0, len(s.lineAttrs))
+		s.damage(ScreenDamage{s.buf.Width(), s.buf.Height()})
 	} else {
 		for _, r := range rects {
 			s.buf.FillRect(c, r)
+			s.clearWrappedRect(r)
+			s.damage(RectDamage(r))
 		}
 	}
-	if s.cb.Damage != nil {
-		for _, r := range rects {
-			s.cb.Damage(RectDamage(r))
+}
+
+// CopyRect copies the cells of the src rectangle to the dst position. The
+// rectangles may overlap. Cells copied past the screen are lost, and wide
+// cells cut by the edges of the rectangles are replaced with blanks.
+func (s *Screen) CopyRect(src Rectangle, dst Position) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	src = src.Intersect(s.buf.Bounds())
+	if src.Empty() {
+		return
+	}
+
+	// Take a copy of the source first in case the rectangles overlap.
+	lines := make([]Line, src.Dy())
+	for i := range lines {
+		lines[i] = make(Line, src.Dx())
+		for j := range lines[i] {
+			if c := s.buf.Cell(src.Min.X+j, src.Min.Y+i); c != nil {
+				lines[i][j] = c.Clone()
+			}
+		}
+	}
+
+	for i, l := range lines {
+		for j, c := range l {
+			if c != nil && c.Width == 0 {
+				if j > 0 {
+					// Wide cell placeholders are set with their wide cell.
+					continue
+				}
+				c = c.Clone().Blank()
+			} else if c != nil && j+c.Width > len(l) {
+				c = c.Clone().Blank()
+			}
+			s.buf.SetCell(dst.X+j, dst.Y+i, c)
 		}
 	}
+	rect := cellbuf.Rect(dst.X, dst.Y, src.Dx(), src.Dy())
+	s.clearWrappedRect(rect)
+	s.damage(RectDamage(rect.Intersect(s.buf.Bounds())))
 }
 
 // setHorizontalMargins sets the horizontal margins.
@@ -170,11 +260,10 @@ func (s *Screen) setCursor(x, y int, margins bool) {
 		y = clamp(s.scroll.Min.Y+y, s.scroll.Min.Y, s.scroll.Max.Y-1)
 		x = clamp(s.scroll.Min.X+x, s.scroll.Min.X, s.scroll.Max.X-1)
 	}
+	x = min(x, s.lineWidth(y)-1)
 	s.cur.X, s.cur.Y = x, y
 	s.mu.Unlock()
-	if s.cb.CursorPosition != nil && (old.X != x || old.Y != y) {
-		s.cb.CursorPosition(old, cellbuf.Pos(x, y))
-	}
+	s.cursorMoved(old, cellbuf.Pos(x, y))
 }
 
 // moveCursor moves the cursor by the given x and y deltas. If the cursor
@@ -203,12 +292,11 @@ func (s *Screen) moveCursor(dx, dy int) {
 		y = clamp(pt.Y, 0, s.buf.Height()-1)
 		x = clamp(pt.X, 0, s.buf.Width()-1)
 	}
+	x = min(x, s.lineWidth(y)-1)
 
 	s.cur.X, s.cur.Y = x, y
 	s.mu.Unlock()
-	if s.cb.CursorPosition != nil && (old.X != x || old.Y != y) {
-		s.cb.CursorPosition(old, cellbuf.Pos(x, y))
-	}
+	s.cursorMoved(old, cellbuf.Pos(x, y))
 }
 
 // Cursor returns the cursor.
@@ -239,15 +327,30 @@ func (s *Screen) SaveCursor() {
 	s.mu.Unlock()
 }
 
-// RestoreCursor restores the cursor.
+// RestoreCursor restores the cursor. The cursor style and visibility are
+// not part of the saved cursor and are left unchanged.
 func (s *Screen) RestoreCursor() {
 	s.mu.Lock()
 	old := s.cur.Position
-	s.cur = s.saved
+	cur := s.saved
+	cur.Style, cur.Steady, cur.Hidden = s.cur.Style, s.cur.Steady, s.cur.Hidden
+	cur.X = min(cur.X, s.lineWidth(cur.Y)-1)
+	s.cur = cur
 	s.mu.Unlock()
-	if s.cb.CursorPosition != nil && (old.X != s.cur.X || old.Y != s.cur.Y) {
-		s.cb.CursorPosition(old, s.cur.Position)
+	s.cursorMoved(old, cur.Position)
+}
+
+// cursorMoved calls the [Callbacks.CursorPosition] callback when the cursor
+// moved. During a synchronized update, the moves are held and reported as a
+// single move when the update ends.
+func (s *Screen) cursorMoved(from, to Position) {
+	if s.cb == nil || s.cb.CursorPosition == nil || from == to {
+		return
 	}
+	if s.dmg != nil && s.dmg.holdCursorMove(from) {
+		return
+	}
+	s.cb.CursorPosition(from, to)
 }
 
 // setCursorHidden sets the cursor hidden.
@@ -308,9 +411,7 @@ func (s *Screen) InsertCell(n int) {
 	x, y := s.cur.X, s.cur.Y
 
 	s.buf.InsertCellRect(x, y, n, s.blankCell(), s.scroll)
-	if s.cb.Damage != nil {
-		s.cb.Damage(RectDamage(cellbuf.Rect(x, y, s.scroll.Dx()-x, 1)))
-	}
+	s.damage(RectDamage(cellbuf.Rect(x, y, s.scroll.Max.X-x, 1)))
 }
 
 // DeleteCell deletes n cells at the cursor position moving cells to the left.
@@ -325,9 +426,7 @@ func (s *Screen) DeleteCell(n int) {
 	x, y := s.cur.X, s.cur.Y
 
 	s.buf.DeleteCellRect(x, y, n, s.blankCell(), s.scroll)
-	if s.cb.Damage != nil {
-		s.cb.Damage(RectDamage(cellbuf.Rect(x, y, s.scroll.Dx()-x, 1)))
-	}
+	s.damage(RectDamage(cellbuf.Rect(x, y, s.scroll.Max.X-x, 1)))
 }
 
 // ScrollUp scrolls the content up n lines within the given region. Lines
@@ -370,12 +469,10 @@ func (s *Screen) InsertLine(n int) bool {
 	}
 
 	s.buf.InsertLineRect(y, n, s.blankCell(), s.scroll)
-	if s.cb.Damage != nil {
-		rect := s.scroll
-		rect.Min.Y = y
-		rect.Max.Y += n
-		s.cb.Damage(RectDamage(rect))
-	}
+	s.moveWrapped(y, n, s.scroll)
+	rect := s.scroll
+	rect.Min.Y = y
+	s.damage(RectDamage(rect))
 
 	return true
 }
@@ -401,21 +498,112 @@ func (s *Screen) DeleteLine(n int) bool {
 	}
 
 	s.buf.DeleteLineRect(y, n, s.blankCell(), scroll)
-	if s.cb.Damage != nil {
-		rect := scroll
-		rect.Min.Y = y
-		rect.Max.Y += n
-		s.cb.Damage(RectDamage(rect))
-	}
+	s.moveWrapped(y, -n, scroll)
+	rect := scroll
+	rect.Min.Y = y
+	s.damage(RectDamage(rect))
 
 	return true
 }
 
+// setWrapped sets whether the line at y was soft wrapped into the next one.
+func (s *Screen) setWrapped(y int, v bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if y >= 0 && y < len(s.wrapped) {
+		s.wrapped[y] = v
+	}
+}
+
+// isWrapped reports whether the line at y was soft wrapped into the next one.
+func (s *Screen) isWrapped(y int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return y >= 0 && y < len(s.wrapped) && s.wrapped[y]
+}
+
+// resizeWrapped resizes the wrapped line flags and the line attributes to the
+// given height. The caller must hold the lock.
+func (s *Screen) resizeWrapped(height int) {
+	if height <= len(s.lineAttrs) {
+		s.lineAttrs = s.lineAttrs[:height]
+	} else {
+		s.lineAttrs = append(s.lineAttrs, make([]LineAttr, height-len(s.lineAttrs))...)
+	}
+	if height <= len(s.wrapped) {
+		s.wrapped = s.wrapped[:height]
+		return
+	}
+	s.wrapped = append(s.wrapped, make([]bool, height-len(s.wrapped))...)
+}
+
+// clearWrapped clears the wrapped flags of the lines from y0 to y1 exclusive.
+// The caller must hold the lock.
+func (s *Screen) clearWrapped(y0, y1 int) {
+	for y := max(y0, 0); y < y1 && y < len(s.wrapped); y++ {
+		s.wrapped[y] = false
+	}
+}
+
+// clearWrappedRect clears the wrapped flags of the lines erased up to their
+// last column by the given rectangle. The caller must hold the lock.
+func (s *Screen) clearWrappedRect(r Rectangle) {
+	if r.Max.X >= s.buf.Width() {
+		s.clearWrapped(r.Min.Y, r.Max.Y)
+	}
+}
+
+// moveWrapped moves the wrapped flags and the line attributes of the lines
+// within the given scroll region starting at y by n lines, down when n is
+// positive and up when n is negative, following [Buffer.InsertLineRect] and
+// [Buffer.DeleteLineRect]. Lines in a scroll region narrower than the screen
+// lose their flags and keep their attributes. The caller must hold the lock.
+func (s *Screen) moveWrapped(y, n int, scroll Rectangle) {
+	top, bottom := y, min(scroll.Max.Y, len(s.wrapped))
+	if scroll.Min.X > 0 || scroll.Max.X < s.buf.Width() {
+		s.clearWrapped(top, bottom)
+		return
+	}
+
+	lines := s.wrapped[top:bottom]
+	attrs := s.lineAttrs[top:bottom]
+	if n > 0 {
+		n = min(n, len(lines))
+		copy(lines[n:], lines)
+		copy(attrs[n:], attrs)
+		for i := 0; i < n; i++ {
+			lines[i] = false
+			attrs[i] = LineSingleWidth
+		}
+	} else {
+		n = min(-n, len(lines))
+		copy(lines, lines[n:])
+		copy(attrs, attrs[n:])
+		for i := len(lines) - n; i < len(lines); i++ {
+			lines[i] = false
+			attrs[i] = LineSingleWidth
+		}
+	}
+}
+
+// damage records the given damaged area and reports it to the damage
+// callback. During a synchronized update, the callback is deferred until the
+// update ends.
+func (s *Screen) damage(d Damage) {
+	if s.dmg != nil && !s.dmg.add(d) {
+		// The damage is reported once the synchronized update ends.
+		return
+	}
+	if s.cb != nil && s.cb.Damage != nil {
+		s.cb.Damage(d)
+	}
+}
+
 // blankCell returns the cursor blank cell with the background color set to the
-// current pen background color. If the pen background color is nil, the return
-// value is nil.
+// current pen background color. If the pen background color is nil, or
+// background color erase is disabled, the return value is nil.
 func (s *Screen) blankCell() (c *Cell) {
-	if s.cur.Pen.Bg == nil {
+	if s.cur.Pen.Bg == nil || (s.bce != nil && !*s.bce) {
 		return
 	}
 
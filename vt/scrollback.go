@@ -0,0 +1,112 @@
+package vt
+
+// DefaultScrollbackLimit is the default number of lines kept in the terminal
+// scrollback.
+const DefaultScrollbackLimit = 1000
+
+// scrollback is a ring buffer of lines scrolled off the top of the screen.
+type scrollback struct {
+	// lines holds the lines, lines[start] is the oldest one once the buffer
+	// is full.
+	lines []Line
+	start int
+	limit int
+	// wrapped reports whether each line of lines was soft wrapped into the
+	// next one.
+	wrapped []bool
+	// offset is the number of scrollback lines the viewport is scrolled up
+	// by, zero when the viewport shows the screen.
+	offset int
+}
+
+// push adds a line to the scrollback, evicting the oldest line when the
+// scrollback is full. The wrapped argument tells whether the line was soft
+// wrapped into the next one.
+func (s *scrollback) push(l Line, wrapped bool) {
+	if s.limit <= 0 {
+		return
+	}
+	if s.offset > 0 {
+		// Keep the viewport on the same lines.
+		s.offset = min(s.offset+1, s.limit)
+	}
+	if len(s.lines) < s.limit {
+		s.lines = append(s.lines, l)
+		s.wrapped = append(s.wrapped, wrapped)
+		return
+	}
+	s.lines[s.start] = l
+	s.wrapped[s.start] = wrapped
+	s.start = (s.start + 1) % len(s.lines)
+}
+
+// all returns the scrollback lines from the oldest to the newest.
+func (s *scrollback) all() []Line {
+	lines := make([]Line, 0, len(s.lines))
+	lines = append(lines, s.lines[s.start:]...)
+	return append(lines, s.lines[:s.start]...)
+}
+
+// allWrapped returns the soft wrapped flags of the lines returned by
+// [scrollback.all].
+func (s *scrollback) allWrapped() []bool {
+	wrapped := make([]bool, 0, len(s.wrapped))
+	wrapped = append(wrapped, s.wrapped[s.start:]...)
+	return append(wrapped, s.wrapped[:s.start]...)
+}
+
+// pop removes the n newest lines from the scrollback and returns them from
+// the oldest to the newest, with their soft wrapped flags.
+func (s *scrollback) pop(n int) ([]Line, []bool) {
+	lines, wrapped := s.all(), s.allWrapped()
+	n = min(n, len(lines))
+	popped, poppedWrapped := lines[len(lines)-n:], wrapped[len(wrapped)-n:]
+	s.lines = lines[: len(lines)-n : len(lines)-n]
+	s.wrapped = wrapped[: len(wrapped)-n : len(wrapped)-n]
+	s.start = 0
+	s.offset = min(s.offset, len(s.lines))
+	return popped, poppedWrapped
+}
+
+// setLimit sets the maximum number of lines, evicting the oldest lines that
+// no longer fit.
+func (s *scrollback) setLimit(n int) {
+	if n < 0 {
+		n = 0
+	}
+	lines, wrapped := s.all(), s.allWrapped()
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+		wrapped = wrapped[len(wrapped)-n:]
+	}
+	s.lines = lines
+	s.wrapped = wrapped
+	s.start = 0
+	s.limit = n
+	s.offset = min(s.offset, len(s.lines))
+}
+
+// clear removes all the lines from the scrollback.
+func (s *scrollback) clear() {
+	s.lines = nil
+	s.wrapped = nil
+	s.start = 0
+	s.offset = 0
+}
+
+// Scrollback returns the lines scrolled off the top of the main screen, from
+// the oldest to the newest.
+func (t *Terminal) Scrollback() []Line {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.scrollback.all()
+}
+
+// SetScrollbackLimit sets the maximum number of lines kept in the scrollback.
+// The oldest lines are evicted once the limit is reached. A limit of zero
+// disables the scrollback. The default is [DefaultScrollbackLimit].
+func (t *Terminal) SetScrollbackLimit(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.scrollback.setLimit(n)
+}
@@ -0,0 +1,212 @@
+package vt
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+func scrollbackText(term *Terminal) []string {
+	var lines []string
+	for _, l := range term.Scrollback() {
+		lines = append(lines, l.String())
+	}
+	return lines
+}
+
+func assertLines(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("want %d lines, got %d: %q", len(want), len(got), got)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("line %d doesn't match:\nwant: %q\ngot:  %q", i+1, want[i], got[i])
+		}
+	}
+}
+
+func TestScrollback(t *testing.T) {
+	term := newTestTerminal(t, 5, 3)
+	for i := 1; i <= 6; i++ {
+		if i > 1 {
+			term.Write([]byte("\r\n"))
+		}
+		term.Write([]byte("line" + strconv.Itoa(i)))
+	}
+
+	assertLines(t, scrollbackText(term), []string{"line1", "line2", "line3"})
+	assertLines(t, termText(term), []string{"line4", "line5", "line6"})
+}
+
+func TestScrollbackLimit(t *testing.T) {
+	term := newTestTerminal(t, 3, 2)
+	term.SetScrollbackLimit(2)
+	for i := 0; i < 6; i++ {
+		term.Write([]byte(strconv.Itoa(i) + "\r\n"))
+	}
+
+	// Six lines were written and the cursor is on a blank last line, so
+	// lines 0 to 4 scrolled off and only the newest two are kept.
+	assertLines(t, scrollbackText(term), []string{"3", "4"})
+
+	term.SetScrollbackLimit(1)
+	assertLines(t, scrollbackText(term), []string{"4"})
+
+	term.SetScrollbackLimit(0)
+	term.Write([]byte("x\r\n"))
+	assertLines(t, scrollbackText(term), nil)
+}
+
+func TestScrollbackScrollRegion(t *testing.T) {
+	term := newTestTerminal(t, 3, 4)
+	// Scroll region on lines 2-3, the lines scrolling out of it are lost.
+	term.Write([]byte("\x1b[2;3r\x1b[3;1Ha\r\nb\r\nc"))
+	assertLines(t, scrollbackText(term), nil)
+
+	// Scroll region starting at the top, the lines go to the scrollback.
+	term.Write([]byte("\x1b[1;2r\x1b[1;1Hd\r\ne\r\nf"))
+	assertLines(t, scrollbackText(term), []string{"d"})
+}
+
+func TestScrollbackAltScreen(t *testing.T) {
+	term := newTestTerminal(t, 3, 2)
+	term.Write([]byte("\x1b[?1049h"))
+	for i := 0; i < 5; i++ {
+		term.Write([]byte(strconv.Itoa(i) + "\r\n"))
+	}
+	term.Write([]byte("\x1b[?1049l"))
+	assertLines(t, scrollbackText(term), nil)
+}
+
+func TestScrollbackErase(t *testing.T) {
+	term := newTestTerminal(t, 3, 1)
+	term.Write([]byte("a\r\nb\r\nc"))
+	assertLines(t, scrollbackText(term), []string{"a", "b"})
+
+	term.Write([]byte("\x1b[2J"))
+	assertLines(t, scrollbackText(term), []string{"a", "b"})
+
+	term.Write([]byte("c\x1b[3J"))
+	assertLines(t, scrollbackText(term), nil)
+	assertLines(t, termText(term), []string{" c "})
+
+	// Erasing the scrollback moves the viewport back to the screen.
+	term.Write([]byte("\r\nd"))
+	term.ScrollUp(1)
+	term.Write([]byte("\x1b[3J"))
+	if got := term.ScrollOffset(); got != 0 {
+		t.Errorf("expected scroll offset 0, got %d", got)
+	}
+	assertLines(t, termText(term), []string{"d  "})
+}
+
+func TestScrollbackResizeHeight(t *testing.T) {
+	for _, reflow := range []bool{true, false} {
+		term := newTestTerminal(t, 5, 5)
+		term.ReflowOnResize = reflow
+		term.Write([]byte("line1\r\nline2\r\nline3\r\nline4"))
+
+		// Shrinking below the cursor row scrolls the top lines into the
+		// scrollback and keeps the cursor on its line.
+		term.Resize(5, 2)
+		assertLines(t, scrollbackText(term), []string{"line1", "line2"})
+		assertLines(t, termText(term), []string{"line3", "line4"})
+		if pos := term.CursorPosition(); pos != cellbuf.Pos(4, 1) {
+			t.Errorf("expected cursor at (4, 1), got %v", pos)
+		}
+
+		// Growing pulls the lines back from the scrollback.
+		term.Resize(5, 5)
+		assertLines(t, scrollbackText(term), nil)
+		assertLines(t, termText(term), []string{"line1", "line2", "line3", "line4", "     "})
+		if pos := term.CursorPosition(); pos != cellbuf.Pos(4, 3) {
+			t.Errorf("expected cursor at (4, 3), got %v", pos)
+		}
+	}
+}
+
+func TestScrollbackResizeHeightWide(t *testing.T) {
+	for _, width := range []int{1, 2, 4, 6} {
+		for _, reflow := range []bool{true, false} {
+			term := newTestTerminal(t, 4, 2)
+			term.ReflowOnResize = reflow
+			term.Write([]byte("ab字\r\n\n\n"))
+			assertLines(t, scrollbackText(term), []string{"ab字", ""})
+
+			// Growing pulls the line with the wide character at the right
+			// edge back from the scrollback.
+			term.Resize(width, 4)
+			if width >= 4 {
+				assertLines(t, scrollbackText(term), nil)
+				assertLines(t, termText(term)[:1], []string{"ab字" + strings.Repeat(" ", width-4)})
+			}
+		}
+	}
+}
+
+func TestScrollbackResizeHeightBlankLines(t *testing.T) {
+	term := newTestTerminal(t, 5, 5)
+	term.Write([]byte("line1\r\nline2"))
+
+	// The blank lines below the cursor are dropped first.
+	term.Resize(5, 2)
+	assertLines(t, scrollbackText(term), nil)
+	assertLines(t, termText(term), []string{"line1", "line2"})
+	if pos := term.CursorPosition(); pos != cellbuf.Pos(4, 1) {
+		t.Errorf("expected cursor at (4, 1), got %v", pos)
+	}
+}
+
+func TestScrollbackScrollUp(t *testing.T) {
+	term := newTestTerminal(t, 3, 3)
+	term.Write([]byte("a\r\nb\r\nc"))
+
+	// Scrolling the whole screen up pushes the lines to the scrollback.
+	term.Write([]byte("\x1b[2S"))
+	assertLines(t, scrollbackText(term), []string{"a", "b"})
+	assertLines(t, termText(term), []string{"c  ", "   ", "   "})
+
+	// Lines scrolled out of a bounded region are lost.
+	term.Write([]byte("\x1b[1;1Hd\r\ne\r\nf\x1b[2;3r\x1b[S"))
+	assertLines(t, scrollbackText(term), []string{"a", "b"})
+	assertLines(t, termText(term), []string{"d  ", "f  ", "   "})
+
+	// Scrolling down doesn't touch the scrollback.
+	term.Write([]byte("\x1b[r\x1b[T"))
+	assertLines(t, scrollbackText(term), []string{"a", "b"})
+	assertLines(t, termText(term), []string{"   ", "d  ", "f  "})
+
+	// The alternate screen has no scrollback.
+	term.Write([]byte("\x1b[?1049hx\x1b[S\x1b[?1049l"))
+	assertLines(t, scrollbackText(term), []string{"a", "b"})
+}
+
+func TestScrollZeroCount(t *testing.T) {
+	// A zero count scrolls by one line.
+	term := newTestTerminal(t, 3, 3)
+	term.Write([]byte("a\r\nb\r\nc\x1b[0S"))
+	assertLines(t, scrollbackText(term), []string{"a"})
+	assertLines(t, termText(term), []string{"b  ", "c  ", "   "})
+
+	term.Write([]byte("\x1b[0T"))
+	assertLines(t, termText(term), []string{"   ", "b  ", "c  "})
+}
+
+func TestScrollUpBackground(t *testing.T) {
+	term := newTestTerminal(t, 3, 3)
+	term.Write([]byte("a\r\nb\r\nc\x1b[2;3r\x1b[44m\x1b[S\x1b[m"))
+	assertLines(t, termText(term), []string{"a  ", "c  ", "   "})
+	for x := 0; x < 3; x++ {
+		c, _ := term.Cell(x, 2)
+		if c.Style.Bg != ansi.Blue {
+			t.Errorf("cell %d: expected a blue background, got %v", x, c.Style.Bg)
+		}
+	}
+	if c, _ := term.Cell(0, 0); c.Style.Bg != nil {
+		t.Errorf("expected no background outside the region, got %v", c.Style.Bg)
+	}
+}
@@ -0,0 +1,88 @@
+package vt
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+// Search returns the positions of the matches of pattern in the scrollback
+// and the screen, from the oldest to the newest. Soft wrapped lines are
+// searched as a single logical line, so a match can span a line wrap. It does
+// a plain substring match, ignoring case unless caseSensitive is true, and
+// matches don't overlap.
+//
+// The positions are in the history coordinates, where the scrollback lines
+// come first, the oldest one at y 0, followed by the screen lines. Use
+// [Terminal.ScrollTo] to show a match. On the alternate screen, which has no
+// scrollback, only the screen is searched.
+func (t *Terminal) Search(pattern string, caseSensitive bool) []Position {
+	if pattern == "" {
+		return nil
+	}
+	if !caseSensitive {
+		pattern = strings.ToLower(pattern)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var lines []Line
+	var wrapped []bool
+	if t.scr == &t.scrs[0] {
+		lines, wrapped = t.scrollback.all(), t.scrollback.allWrapped()
+	}
+	for y := 0; y < t.scr.Height(); y++ {
+		lines = append(lines, t.scr.cloneLine(y))
+		wrapped = append(wrapped, t.scr.isWrapped(y))
+	}
+
+	var matches []Position
+	var text strings.Builder
+	var starts []int     // the offset of each cell in text
+	var cells []Position // the position of each cell
+	for y, l := range lines {
+		for x, c := range trimLine(l, wrapped[y]) {
+			s := " "
+			if c != nil {
+				if c.Width == 0 {
+					// Skip wide cell placeholders.
+					continue
+				}
+				if !c.Empty() {
+					s = c.String()
+				}
+			}
+			if !caseSensitive {
+				s = strings.ToLower(s)
+			}
+			starts = append(starts, text.Len())
+			cells = append(cells, cellbuf.Pos(x, y))
+			text.WriteString(s)
+		}
+		if wrapped[y] && y < len(lines)-1 {
+			continue
+		}
+
+		// Search the logical line.
+		s := text.String()
+		for i := 0; i <= len(s)-len(pattern); {
+			n := strings.Index(s[i:], pattern)
+			if n < 0 {
+				break
+			}
+			i += n
+			// Only report matches starting at a cell.
+			if j := sort.SearchInts(starts, i); j < len(starts) && starts[j] == i {
+				matches = append(matches, cells[j])
+				i += len(pattern)
+			} else {
+				i++
+			}
+		}
+		text.Reset()
+		starts, cells = starts[:0], cells[:0]
+	}
+	return matches
+}
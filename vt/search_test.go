@@ -0,0 +1,114 @@
+package vt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+// newSearchTerminal returns a terminal with the history lines:
+//
+//	0 hello
+//	1 0123456789 (soft wrapped)
+//	2 abcdhello
+//	3 HeLLo 你好
+//	4 end
+//
+// The first two lines are in the scrollback.
+func newSearchTerminal(t *testing.T) *Terminal {
+	term := newTestTerminal(t, 10, 3)
+	term.Write([]byte("hello\r\n0123456789abcdhello\r\nHeLLo 你好\r\nend")) //nolint:errcheck
+	if n := len(term.Scrollback()); n != 2 {
+		t.Fatalf("expected 2 scrollback lines, got %d", n)
+	}
+	return term
+}
+
+func TestSearch(t *testing.T) {
+	cases := []struct {
+		name          string
+		pattern       string
+		caseSensitive bool
+		want          []Position
+	}{
+		{"case sensitive", "hello", true, []Position{cellbuf.Pos(0, 0), cellbuf.Pos(4, 2)}},
+		{"case insensitive", "hello", false, []Position{cellbuf.Pos(0, 0), cellbuf.Pos(4, 2), cellbuf.Pos(0, 3)}},
+		{"across wrap", "9a", true, []Position{cellbuf.Pos(9, 1)}},
+		{"across wrap into the screen", "89abcd", true, []Position{cellbuf.Pos(8, 1)}},
+		{"wide characters", "好", true, []Position{cellbuf.Pos(8, 3)}},
+		{"space before wide characters", "o 你", false, []Position{cellbuf.Pos(4, 3)}},
+		{"across hard line break", "lo0", true, nil},
+		{"no overlap", "ll", false, []Position{cellbuf.Pos(2, 0), cellbuf.Pos(6, 2), cellbuf.Pos(2, 3)}},
+		{"no match", "world", false, nil},
+		{"empty pattern", "", false, nil},
+	}
+
+	term := newSearchTerminal(t)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := term.Search(tc.pattern, tc.caseSensitive); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected matches %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSearchScrollTo(t *testing.T) {
+	term := newSearchTerminal(t)
+	matches := term.Search("89abcd", true)
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, got %v", matches)
+	}
+
+	term.ScrollTo(matches[0].Y)
+	if got := term.ScrollOffset(); got != 1 {
+		t.Errorf("expected scroll offset 1, got %d", got)
+	}
+	if c, _ := term.ViewportCell(matches[0].X, 0); c.Rune != '8' {
+		t.Errorf("expected the match at the top of the viewport, got %q", c.Rune)
+	}
+
+	// Screen lines are shown with the screen.
+	term.ScrollTo(3)
+	if got := term.ScrollOffset(); got != 0 {
+		t.Errorf("expected scroll offset 0, got %d", got)
+	}
+}
+
+func TestSearchKeepsWraps(t *testing.T) {
+	want := []Position{cellbuf.Pos(8, 1)}
+
+	// The soft wrapped flags are saved with the scrollback.
+	term := newSearchTerminal(t)
+	var buf bytes.Buffer
+	if err := term.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := newTestTerminal(t, 10, 3)
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Search("89abcd", true); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected matches %v after loading, got %v", want, got)
+	}
+
+	// Lines scrolled by a resize keep their soft wrapped flags.
+	term.Resize(5, 3)
+	if got := term.Search("89abcd", true); len(got) != 1 {
+		t.Errorf("expected 1 match after resizing, got %v", got)
+	}
+	if got := term.Search("abcdhello", true); len(got) != 1 {
+		t.Errorf("expected 1 match after resizing, got %v", got)
+	}
+}
+
+func TestSearchAltScreen(t *testing.T) {
+	term := newSearchTerminal(t)
+	term.Write([]byte("\x1b[?1049h\x1b[2;3Hhello")) //nolint:errcheck
+	want := []Position{cellbuf.Pos(2, 1)}
+	if got := term.Search("hello", true); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected matches %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,95 @@
+package vt
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+// selection represents a text selection on the screen.
+type selection struct {
+	start, end Position
+	active     bool
+}
+
+// SetSelection selects the text between the start and end positions,
+// inclusive, in reading order. This is a stream selection, like the one made
+// with a mouse, where every line between the first and the last one is
+// selected entirely. The positions are clamped to the screen bounds and can
+// be given in any order.
+func (t *Terminal) SetSelection(start, end Position) {
+	if end.Y < start.Y || (end.Y == start.Y && end.X < start.X) {
+		start, end = end, start
+	}
+	t.selection = selection{start: start, end: end, active: true}
+}
+
+// ClearSelection clears the current selection.
+func (t *Terminal) ClearSelection() {
+	t.selection = selection{}
+}
+
+// Selection returns the current selection start and end positions clamped to
+// the screen bounds. It returns false if nothing is selected.
+func (t *Terminal) Selection() (start, end Position, ok bool) {
+	if !t.selection.active {
+		return start, end, false
+	}
+	w, h := t.Width(), t.Height()
+	if w <= 0 || h <= 0 {
+		return start, end, false
+	}
+	start = cellbuf.Pos(clamp(t.selection.start.X, 0, w-1), clamp(t.selection.start.Y, 0, h-1))
+	end = cellbuf.Pos(clamp(t.selection.end.X, 0, w-1), clamp(t.selection.end.Y, 0, h-1))
+	return start, end, true
+}
+
+// SelectedText returns the text of the current selection. Trailing spaces are
+// removed from each line and lines are separated by a newline, except for
+// lines that were soft wrapped into the next one, which are joined. A
+// selection starting in the middle of a wide character includes the whole
+// character. Concealed cells are replaced with spaces.
+func (t *Terminal) SelectedText() string {
+	start, end, ok := t.Selection()
+	if !ok {
+		return ""
+	}
+
+	w := t.Width()
+	var sb strings.Builder
+	for y := start.Y; y <= end.Y; y++ {
+		x0, x1 := 0, w-1
+		if y == start.Y {
+			x0 = start.X
+			// Move to the first cell of a wide character.
+			for x0 > 0 {
+				if c := t.scr.Cell(x0, y); c == nil || !c.Empty() {
+					break
+				}
+				x0--
+			}
+		}
+		if y == end.Y {
+			x1 = end.X
+		}
+
+		var line strings.Builder
+		for x := x0; x <= x1; x++ {
+			if c := t.scr.Cell(x, y); c != nil {
+				line.WriteString(cellText(c))
+			}
+		}
+
+		if y < end.Y && t.scr.isWrapped(y) {
+			sb.WriteString(line.String())
+			continue
+		}
+
+		sb.WriteString(strings.TrimRight(line.String(), " "))
+		if y < end.Y {
+			sb.WriteByte('\n')
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,116 @@
+package vt
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+func TestSelectedText(t *testing.T) {
+	cases := []struct {
+		name       string
+		w, h       int
+		input      string
+		start, end Position
+		want       string
+	}{
+		{
+			name: "single line",
+			w:    10, h: 2,
+			input: "hello world",
+			start: cellbuf.Pos(1, 0), end: cellbuf.Pos(3, 0),
+			want: "ell",
+		},
+		{
+			name: "trailing spaces",
+			w:    10, h: 3,
+			input: "ab\r\ncd\r\nef",
+			start: cellbuf.Pos(0, 0), end: cellbuf.Pos(9, 1),
+			want: "ab\ncd",
+		},
+		{
+			name: "concealed",
+			w:    10, h: 2,
+			input: "pw: \x1b[8mse\u4f60\x1b[28m!",
+			start: cellbuf.Pos(0, 0), end: cellbuf.Pos(9, 0),
+			want: "pw:     !",
+		},
+		{
+			name: "reversed positions",
+			w:    10, h: 3,
+			input: "ab\r\ncd",
+			start: cellbuf.Pos(1, 1), end: cellbuf.Pos(1, 0),
+			want: "b\ncd",
+		},
+		{
+			name: "wrapped line",
+			w:    5, h: 3,
+			input: "hello world",
+			start: cellbuf.Pos(0, 0), end: cellbuf.Pos(4, 2),
+			want: "hello world",
+		},
+		{
+			name: "wrapped line then newline",
+			w:    5, h: 3,
+			input: "abcdefg\r\nxy",
+			start: cellbuf.Pos(2, 0), end: cellbuf.Pos(4, 2),
+			want: "cdefg\nxy",
+		},
+		{
+			name: "start inside wide char",
+			w:    10, h: 1,
+			input: "a你好b",
+			start: cellbuf.Pos(2, 0), end: cellbuf.Pos(3, 0),
+			want: "你好",
+		},
+		{
+			name: "end inside wide char",
+			w:    10, h: 1,
+			input: "a你好b",
+			start: cellbuf.Pos(0, 0), end: cellbuf.Pos(2, 0),
+			want: "a你",
+		},
+		{
+			name: "wrapped wide chars",
+			w:    4, h: 2,
+			input: "你好世界",
+			start: cellbuf.Pos(1, 0), end: cellbuf.Pos(3, 1),
+			want: "你好世界",
+		},
+		{
+			name: "clamped to bounds",
+			w:    5, h: 2,
+			input: "abc\r\ndef",
+			start: cellbuf.Pos(-3, -1), end: cellbuf.Pos(20, 20),
+			want: "abc\ndef",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			term := newTestTerminal(t, tt.w, tt.h)
+			term.Write([]byte(tt.input))
+			term.SetSelection(tt.start, tt.end)
+			if got := term.SelectedText(); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClearSelection(t *testing.T) {
+	term := newTestTerminal(t, 5, 1)
+	term.Write([]byte("abc"))
+	term.SetSelection(cellbuf.Pos(0, 0), cellbuf.Pos(2, 0))
+	if _, _, ok := term.Selection(); !ok {
+		t.Fatal("expected an active selection")
+	}
+
+	term.ClearSelection()
+	if _, _, ok := term.Selection(); ok {
+		t.Error("expected no selection")
+	}
+	if got := term.SelectedText(); got != "" {
+		t.Errorf("want empty text, got %q", got)
+	}
+}
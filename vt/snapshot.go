@@ -0,0 +1,111 @@
+package vt
+
+import "github.com/charmbracelet/x/cellbuf"
+
+// Snapshot is a copy of the cells of a terminal screen at a point in time.
+// It isn't affected by later changes to the terminal. Use [Diff] to find the
+// areas that changed between two snapshots.
+type Snapshot struct {
+	width, height int
+	cells         []Cell // row major
+}
+
+// Snapshot returns a snapshot of the current focused screen cells.
+func (t *Terminal) Snapshot() *Snapshot {
+	return t.scr.snapshot()
+}
+
+// snapshot returns a snapshot of the screen cells.
+func (s *Screen) snapshot() *Snapshot {
+	snap := new(Snapshot)
+	s.snapshotInto(snap)
+	return snap
+}
+
+// snapshotInto copies the screen cells into snap, reusing its memory.
+func (s *Screen) snapshotInto(snap *Snapshot) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	width, height := s.buf.Width(), s.buf.Height()
+	snap.width, snap.height = width, height
+	if cap(snap.cells) < width*height {
+		snap.cells = make([]Cell, 0, width*height)
+	}
+	snap.cells = snap.cells[:0]
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := s.buf.Cell(x, y)
+			if c == nil {
+				c = &cellbuf.BlankCell
+			}
+			cell := *c
+			if cell.Comb != nil {
+				cell.Comb = append([]rune(nil), cell.Comb...)
+			}
+			snap.cells = append(snap.cells, cell)
+		}
+	}
+}
+
+// Width returns the width of the snapshot.
+func (s *Snapshot) Width() int {
+	return s.width
+}
+
+// Height returns the height of the snapshot.
+func (s *Snapshot) Height() int {
+	return s.height
+}
+
+// Cell returns the cell at the given x, y position. It reports false if the
+// position is out of bounds.
+func (s *Snapshot) Cell(x, y int) (Cell, bool) {
+	if x < 0 || y < 0 || x >= s.width || y >= s.height {
+		return Cell{}, false
+	}
+	return s.cells[y*s.width+x], true
+}
+
+// Diff returns the areas that differ between the snapshots a and b. Like
+// [Terminal.FlushDamage], the changed cells are merged into a set of
+// non-overlapping [RectDamage] sorted by their top-left corner, or a single
+// [ScreenDamage] when most of the screen changed. When the snapshots have
+// different sizes, or either of them is nil, the whole screen of the other
+// one is reported as damaged. It returns nil if the snapshots are identical.
+func Diff(a, b *Snapshot) []Damage {
+	switch {
+	case a == nil && b == nil:
+		return nil
+	case a == nil:
+		return []Damage{ScreenDamage{b.width, b.height}}
+	case b == nil || a.width != b.width || a.height != b.height:
+		if b != nil {
+			a = b
+		}
+		return []Damage{ScreenDamage{a.width, a.height}}
+	}
+
+	changed := func(x, y int) bool {
+		i := y*b.width + x
+		return !a.cells[i].Equal(&b.cells[i])
+	}
+
+	var damages []Damage
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			if !changed(x, y) {
+				continue
+			}
+			// Report the run of changed cells as a single area.
+			start := x
+			for x < b.width && changed(x, y) {
+				x++
+			}
+			damages = append(damages, CellDamage{X: start, Y: y, Width: x - start})
+		}
+	}
+
+	rects, full := coalesceDamage(damages, b.width, b.height)
+	return rectsDamage(rects, full, b.width, b.height)
+}
@@ -0,0 +1,94 @@
+package vt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		name   string
+		before string
+		after  string
+		want   []Damage
+	}{
+		{
+			name:   "identical",
+			before: "hello\r\nworld",
+			after:  "hello\r\nworld",
+		},
+		{
+			name:   "few cells",
+			before: "hello\r\nworld",
+			after:  "hallo\r\nworlD",
+			want: []Damage{
+				RectDamage(cellbuf.Rect(1, 0, 1, 1)),
+				RectDamage(cellbuf.Rect(4, 1, 1, 1)),
+			},
+		},
+		{
+			name:   "style only",
+			before: "hello",
+			after:  "h\x1b[1me\x1b[mllo",
+			want:   []Damage{RectDamage(cellbuf.Rect(1, 0, 1, 1))},
+		},
+		{
+			name:   "full row",
+			before: "hello\r\n0123456789",
+			after:  "hello\r\nabcdefghij",
+			want:   []Damage{RectDamage(cellbuf.Rect(0, 1, 10, 1))},
+		},
+		{
+			name:   "most of the screen",
+			before: "hello\r\nworld",
+			after:  "\x1b#8",
+			want:   []Damage{ScreenDamage{10, 4}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 10, 4)
+			term.Write([]byte(tc.before))
+			a := term.Snapshot()
+
+			term = newTestTerminal(t, 10, 4)
+			term.Write([]byte(tc.after))
+			b := term.Snapshot()
+
+			if got := Diff(a, b); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDiffSize(t *testing.T) {
+	a := newTestTerminal(t, 10, 4).Snapshot()
+	b := newTestTerminal(t, 8, 2).Snapshot()
+	if got, want := Diff(a, b), []Damage{ScreenDamage{8, 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if got, want := Diff(nil, a), []Damage{ScreenDamage{10, 4}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+	term := newTestTerminal(t, 4, 1)
+	term.Write([]byte("ab"))
+	snap := term.Snapshot()
+	term.Write([]byte("\rcd"))
+
+	if cell, ok := snap.Cell(0, 0); !ok || cell.String() != "a" {
+		t.Errorf("expected snapshot cell %q, got %q", "a", cell.String())
+	}
+	if got := Diff(snap, term.Snapshot()); len(got) != 1 {
+		t.Errorf("expected one damaged area, got %v", got)
+	}
+	if _, ok := snap.Cell(4, 0); ok {
+		t.Error("expected cell at 4,0 to be out of bounds")
+	}
+}
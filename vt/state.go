@@ -0,0 +1,593 @@
+package vt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/cellbuf"
+)
+
+// stateVersion is the version of the saved terminal state format.
+const stateVersion = 2
+
+// ErrInvalidState is returned by [Terminal.Load] when the saved terminal
+// state is invalid.
+var ErrInvalidState = errors.New("vt: invalid terminal state")
+
+// terminalState is the saved state of a terminal.
+type terminalState struct {
+	Version           int            `json:"version"`
+	Width             int            `json:"width"`
+	Height            int            `json:"height"`
+	Screens           [2]screenState `json:"screens"`
+	AltScreen         bool           `json:"alt_screen,omitempty"`
+	Scrollback        [][]*cellState `json:"scrollback,omitempty"`
+	ScrollbackWrapped []bool         `json:"scrollback_wrapped,omitempty"`
+	ScrollbackLimit   int            `json:"scrollback_limit"`
+	Modes             []modeState    `json:"modes"`
+	TabStops          []int          `json:"tab_stops"`
+	TabWidth          int            `json:"tab_width"`
+	HTSStops          []int          `json:"hts_stops,omitempty"`
+	Charsets          [4]string      `json:"charsets"`
+	GL                int            `json:"gl"`
+	GR                int            `json:"gr"`
+	Foreground        string         `json:"foreground,omitempty"`
+	Background        string         `json:"background,omitempty"`
+	CursorColor       string         `json:"cursor_color,omitempty"`
+	Palette           map[int]string `json:"palette,omitempty"`
+	Title             string         `json:"title,omitempty"`
+	IconName          string         `json:"icon_name,omitempty"`
+	Titles            []titleState   `json:"titles,omitempty"`
+	LastChar          rune           `json:"last_char,omitempty"`
+	Phantom           bool           `json:"phantom,omitempty"`
+}
+
+// screenState is the saved state of a screen.
+type screenState struct {
+	Lines     [][]*cellState `json:"lines"`
+	Wrapped   []bool         `json:"wrapped,omitempty"`
+	LineAttrs []LineAttr     `json:"line_attrs,omitempty"`
+	Cursor    cursorState    `json:"cursor"`
+	Saved     cursorState    `json:"saved"`
+	Scroll    [4]int         `json:"scroll"` // min x, min y, max x, max y
+}
+
+// cursorState is the saved state of a cursor.
+type cursorState struct {
+	X      int         `json:"x"`
+	Y      int         `json:"y"`
+	Pen    *styleState `json:"pen,omitempty"`
+	Link   *Link       `json:"link,omitempty"`
+	Style  CursorStyle `json:"style,omitempty"`
+	Steady bool        `json:"steady,omitempty"`
+	Hidden bool        `json:"hidden,omitempty"`
+}
+
+// cellState is the saved state of a cell. A nil cell is a cell that was never
+// written to.
+type cellState struct {
+	Content string      `json:"c,omitempty"`
+	Width   int         `json:"w"`
+	Style   *styleState `json:"s,omitempty"`
+	Link    *Link       `json:"l,omitempty"`
+}
+
+// styleState is the saved state of a cell style.
+type styleState struct {
+	Fg      string                 `json:"fg,omitempty"`
+	Bg      string                 `json:"bg,omitempty"`
+	Ul      string                 `json:"ul,omitempty"`
+	Attrs   cellbuf.AttrMask       `json:"attrs,omitempty"`
+	UlStyle cellbuf.UnderlineStyle `json:"ul_style,omitempty"`
+}
+
+// modeState is the saved setting of a mode. ANSI modes are saved as their
+// number and DEC modes are prefixed with a question mark.
+type modeState struct {
+	Mode    string           `json:"mode"`
+	Setting ansi.ModeSetting `json:"setting"`
+}
+
+// titleState is a saved entry of the title stack.
+type titleState struct {
+	IconName *string `json:"icon_name,omitempty"`
+	Title    *string `json:"title,omitempty"`
+}
+
+// charsets are the named character sets that can be saved.
+var charsets = map[string]CharSet{
+	"uk":              UK,
+	"special_drawing": SpecialDrawing,
+}
+
+// Save writes the terminal state as JSON to w. The state includes both
+// screens with their cells, cursors and scroll regions, the scrollback, the
+// modes, the tab stops, the character sets, the colors, and the titles. Use
+// [Terminal.Load] to restore it.
+func (t *Terminal) Save(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := terminalState{
+		Version:         stateVersion,
+		Width:           t.Width(),
+		Height:          t.Height(),
+		AltScreen:       t.scr == &t.scrs[1],
+		ScrollbackLimit: t.scrollback.limit,
+		GL:              t.gl,
+		GR:              t.gr,
+		Foreground:      encodeColor(t.fg),
+		Background:      encodeColor(t.bg),
+		CursorColor:     encodeColor(t.cur),
+		Title:           t.title,
+		IconName:        t.iconName,
+		LastChar:        t.lastChar,
+		Phantom:         t.atPhantom,
+	}
+	for i := range t.scrs {
+		st.Screens[i] = saveScreen(&t.scrs[i])
+	}
+	for _, l := range t.scrollback.all() {
+		st.Scrollback = append(st.Scrollback, saveLine(l))
+	}
+	for _, w := range t.scrollback.wrapped {
+		if w {
+			st.ScrollbackWrapped = t.scrollback.allWrapped()
+			break
+		}
+	}
+	for mode, setting := range t.modes {
+		st.Modes = append(st.Modes, modeState{encodeMode(mode), setting})
+	}
+	sort.Slice(st.Modes, func(i, j int) bool {
+		return st.Modes[i].Mode < st.Modes[j].Mode
+	})
+	st.TabStops = t.TabStops()
+	st.TabWidth = t.tabWidth
+	for x := range t.htsStops {
+		if x < st.Width {
+			st.HTSStops = append(st.HTSStops, x)
+		}
+	}
+	sort.Ints(st.HTSStops)
+	for i, cs := range t.charsets {
+		st.Charsets[i] = charsetName(cs)
+	}
+	for i, c := range t.colors {
+		if c != nil {
+			if st.Palette == nil {
+				st.Palette = map[int]string{}
+			}
+			st.Palette[i] = encodeColor(c)
+		}
+	}
+	for _, e := range t.titles {
+		st.Titles = append(st.Titles, titleState{IconName: e.iconName, Title: e.title})
+	}
+
+	return json.NewEncoder(w).Encode(st) //nolint:wrapcheck
+}
+
+// Load restores the terminal state saved by [Terminal.Save] from r. The
+// terminal is resized to the saved size. The callbacks, the logger, and the
+// registered handlers are kept. It returns [ErrInvalidState] if the state
+// doesn't describe a valid terminal.
+func (t *Terminal) Load(r io.Reader) error {
+	var st terminalState
+	if err := json.NewDecoder(r).Decode(&st); err != nil {
+		return fmt.Errorf("vt: decoding terminal state: %w", err)
+	}
+	if st.Version != stateVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidState, st.Version)
+	}
+	if st.Width <= 0 || st.Height <= 0 {
+		return fmt.Errorf("%w: invalid size %dx%d", ErrInvalidState, st.Width, st.Height)
+	}
+
+	// Decode everything before touching the terminal so that an invalid
+	// state leaves it unchanged.
+	var scrs [2]Screen
+	for i := range scrs {
+		if err := loadScreen(&scrs[i], st.Screens[i], st.Width, st.Height); err != nil {
+			return err
+		}
+	}
+	scrollback := make([]Line, len(st.Scrollback))
+	for i, l := range st.Scrollback {
+		// Lines scrolled off before the terminal got narrower keep their
+		// width.
+		line, err := loadLine(l, max(len(l), st.Width))
+		if err != nil {
+			return err
+		}
+		scrollback[i] = line
+	}
+	for _, x := range append(st.TabStops[:len(st.TabStops):len(st.TabStops)], st.HTSStops...) {
+		if x < 0 || x >= st.Width {
+			return fmt.Errorf("%w: invalid tab stop %d", ErrInvalidState, x)
+		}
+	}
+	if st.TabWidth < 1 {
+		return fmt.Errorf("%w: invalid tab width %d", ErrInvalidState, st.TabWidth)
+	}
+	modes := make(map[ansi.Mode]ansi.ModeSetting, len(st.Modes))
+	for _, m := range st.Modes {
+		mode, err := decodeMode(m.Mode)
+		if err != nil {
+			return err
+		}
+		modes[mode] = m.Setting
+	}
+	var cs [4]CharSet
+	for i, name := range st.Charsets {
+		if name == "" {
+			continue
+		}
+		set, ok := charsets[name]
+		if !ok {
+			return fmt.Errorf("%w: unknown character set %q", ErrInvalidState, name)
+		}
+		cs[i] = set
+	}
+	if st.GL < 0 || st.GL > 3 || st.GR < 0 || st.GR > 3 {
+		return fmt.Errorf("%w: invalid character set selection", ErrInvalidState)
+	}
+	var palette [256]color.Color
+	for i, s := range st.Palette {
+		if i < 0 || i > 255 {
+			return fmt.Errorf("%w: invalid palette index %d", ErrInvalidState, i)
+		}
+		c, err := decodeColor(s)
+		if err != nil {
+			return err
+		}
+		palette[i] = c
+	}
+	var fg, bg, cur color.Color
+	for _, c := range []struct {
+		s   string
+		dst *color.Color
+	}{{st.Foreground, &fg}, {st.Background, &bg}, {st.CursorColor, &cur}} {
+		col, err := decodeColor(c.s)
+		if err != nil {
+			return err
+		}
+		*c.dst = col
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.parser.Reset()
+	for i := range scrs {
+		t.scrs[i].mu.Lock()
+		t.scrs[i].buf = scrs[i].buf
+		t.scrs[i].wrapped = scrs[i].wrapped
+		t.scrs[i].lineAttrs = scrs[i].lineAttrs
+		t.scrs[i].cur = scrs[i].cur
+		t.scrs[i].saved = scrs[i].saved
+		// The origin mode and character sets saved with the cursor aren't
+		// part of the state and restore to their defaults.
+		t.scrs[i].savedState = nil
+		t.scrs[i].scroll = scrs[i].scroll
+		t.scrs[i].mu.Unlock()
+	}
+	t.scr = &t.scrs[0]
+	if st.AltScreen {
+		t.scr = &t.scrs[1]
+	}
+	t.scrollback.setLimit(st.ScrollbackLimit)
+	t.scrollback.clear()
+	for i, l := range scrollback {
+		t.scrollback.push(l, i < len(st.ScrollbackWrapped) && st.ScrollbackWrapped[i])
+	}
+	t.modes = modes
+	t.tabWidth = st.TabWidth
+	t.tabstops = cellbuf.NewTabStops(st.Width, t.tabWidth)
+	t.tabstops.Clear()
+	for _, x := range st.TabStops {
+		t.tabstops.Set(x)
+	}
+	t.htsStops = nil
+	for _, x := range st.HTSStops {
+		if t.htsStops == nil {
+			t.htsStops = map[int]struct{}{}
+		}
+		t.htsStops[x] = struct{}{}
+	}
+	t.charsets = cs
+	t.gl, t.gr, t.gsingle = st.GL, st.GR, 0
+	t.fg, t.bg, t.cur = fg, bg, cur
+	t.colors = palette
+	t.title, t.iconName = st.Title, st.IconName
+	t.titles = t.titles[:0]
+	for _, e := range st.Titles {
+		t.titles = append(t.titles, titleStackEntry{iconName: e.IconName, title: e.Title})
+	}
+	t.lastChar = st.LastChar
+	t.atPhantom = st.Phantom
+	t.selection = selection{}
+	t.scr.damage(ScreenDamage{st.Width, st.Height})
+
+	return nil
+}
+
+// saveScreen returns the saved state of the screen.
+func saveScreen(s *Screen) screenState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := screenState{
+		Cursor: saveCursor(s.cur),
+		Saved:  saveCursor(s.saved),
+		Scroll: [4]int{s.scroll.Min.X, s.scroll.Min.Y, s.scroll.Max.X, s.scroll.Max.Y},
+	}
+	for _, l := range s.buf.Lines {
+		st.Lines = append(st.Lines, saveLine(l))
+	}
+	for _, w := range s.wrapped {
+		if w {
+			st.Wrapped = s.wrapped
+			break
+		}
+	}
+	for _, a := range s.lineAttrs {
+		if a != LineSingleWidth {
+			st.LineAttrs = s.lineAttrs
+			break
+		}
+	}
+	return st
+}
+
+// loadScreen restores the saved state of a screen of the given size.
+func loadScreen(s *Screen, st screenState, width, height int) error {
+	if len(st.Lines) != height {
+		return fmt.Errorf("%w: got %d screen lines, want %d", ErrInvalidState, len(st.Lines), height)
+	}
+	s.buf.Lines = make([]Line, height)
+	for y, l := range st.Lines {
+		line, err := loadLine(l, width)
+		if err != nil {
+			return err
+		}
+		s.buf.Lines[y] = line
+	}
+	s.wrapped = make([]bool, height)
+	copy(s.wrapped, st.Wrapped)
+	s.lineAttrs = make([]LineAttr, height)
+	for y, a := range st.LineAttrs {
+		if y >= height || a < LineSingleWidth || a > LineDoubleHeightBottom {
+			return fmt.Errorf("%w: invalid line attribute %d on line %d", ErrInvalidState, a, y)
+		}
+		s.lineAttrs[y] = a
+	}
+
+	var err error
+	if s.cur, err = loadCursor(st.Cursor, width, height); err != nil {
+		return err
+	}
+	if s.saved, err = loadCursor(st.Saved, width, height); err != nil {
+		return err
+	}
+	s.scroll = cellbuf.Rect(st.Scroll[0], st.Scroll[1], st.Scroll[2]-st.Scroll[0], st.Scroll[3]-st.Scroll[1])
+	if s.scroll.Empty() || !s.scroll.In(cellbuf.Rect(0, 0, width, height)) {
+		return fmt.Errorf("%w: invalid scroll region %v", ErrInvalidState, st.Scroll)
+	}
+	return nil
+}
+
+// saveCursor returns the saved state of the cursor.
+func saveCursor(c Cursor) cursorState {
+	st := cursorState{
+		X:      c.X,
+		Y:      c.Y,
+		Pen:    saveStyle(c.Pen),
+		Style:  c.Style,
+		Steady: c.Steady,
+		Hidden: c.Hidden,
+	}
+	if !c.Link.Empty() {
+		link := c.Link
+		st.Link = &link
+	}
+	return st
+}
+
+// loadCursor restores the saved state of a cursor within a screen of the
+// given size.
+func loadCursor(st cursorState, width, height int) (Cursor, error) {
+	if st.X < 0 || st.X >= width || st.Y < 0 || st.Y >= height {
+		return Cursor{}, fmt.Errorf("%w: cursor at (%d, %d) is out of bounds", ErrInvalidState, st.X, st.Y)
+	}
+	c := Cursor{
+		Position: cellbuf.Pos(st.X, st.Y),
+		Style:    st.Style,
+		Steady:   st.Steady,
+		Hidden:   st.Hidden,
+	}
+	if st.Link != nil {
+		c.Link = *st.Link
+	}
+	pen, err := loadStyle(st.Pen)
+	if err != nil {
+		return Cursor{}, err
+	}
+	c.Pen = pen
+	return c, nil
+}
+
+// saveLine returns the saved state of the line cells.
+func saveLine(l Line) []*cellState {
+	cells := make([]*cellState, len(l))
+	for x, c := range l {
+		if c == nil {
+			continue
+		}
+		st := &cellState{
+			Content: c.String(),
+			Width:   c.Width,
+			Style:   saveStyle(c.Style),
+		}
+		if !c.Link.Empty() {
+			link := c.Link
+			st.Link = &link
+		}
+		cells[x] = st
+	}
+	return cells
+}
+
+// loadLine restores the saved cells of a line of the given width.
+func loadLine(cells []*cellState, width int) (Line, error) {
+	if len(cells) > width {
+		return nil, fmt.Errorf("%w: line is wider than %d cells", ErrInvalidState, width)
+	}
+	l := make(Line, width)
+	for x, st := range cells {
+		if st == nil {
+			continue
+		}
+		if st.Width < 0 || x+st.Width > width {
+			return nil, fmt.Errorf("%w: invalid cell width %d at column %d", ErrInvalidState, st.Width, x)
+		}
+		c := &Cell{Width: st.Width}
+		for i, r := range st.Content {
+			if i == 0 {
+				c.Rune = r
+			} else {
+				c.Comb = append(c.Comb, r)
+			}
+		}
+		style, err := loadStyle(st.Style)
+		if err != nil {
+			return nil, err
+		}
+		c.Style = style
+		if st.Link != nil {
+			c.Link = *st.Link
+		}
+		l[x] = c
+	}
+	return l, nil
+}
+
+// saveStyle returns the saved state of the style, or nil if the style is
+// empty.
+func saveStyle(s Style) *styleState {
+	if s.Empty() {
+		return nil
+	}
+	return &styleState{
+		Fg:      encodeColor(s.Fg),
+		Bg:      encodeColor(s.Bg),
+		Ul:      encodeColor(s.Ul),
+		Attrs:   s.Attrs,
+		UlStyle: s.UlStyle,
+	}
+}
+
+// loadStyle restores a saved style.
+func loadStyle(st *styleState) (s Style, err error) {
+	if st == nil {
+		return s, nil
+	}
+	s.Attrs, s.UlStyle = st.Attrs, st.UlStyle
+	if s.Fg, err = decodeColor(st.Fg); err != nil {
+		return s, err
+	}
+	if s.Bg, err = decodeColor(st.Bg); err != nil {
+		return s, err
+	}
+	if s.Ul, err = decodeColor(st.Ul); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// encodeColor encodes a color as a string. Basic ANSI colors are encoded as
+// "ansi:N", 256 colors as "ansi256:N", and other colors as "#rrggbbaa" hex
+// values. A nil color is encoded as an empty string.
+func encodeColor(c color.Color) string {
+	switch c := c.(type) {
+	case nil:
+		return ""
+	case ansi.BasicColor:
+		return "ansi:" + strconv.Itoa(int(c))
+	case ansi.ExtendedColor:
+		return "ansi256:" + strconv.Itoa(int(c))
+	default:
+		r, g, b, a := c.RGBA()
+		return fmt.Sprintf("#%02x%02x%02x%02x", r>>8, g>>8, b>>8, a>>8)
+	}
+}
+
+// decodeColor decodes a color encoded with [encodeColor].
+func decodeColor(s string) (color.Color, error) {
+	if s == "" {
+		return nil, nil //nolint:nilnil
+	}
+	if strings.HasPrefix(s, "ansi:") {
+		i, err := strconv.ParseUint(strings.TrimPrefix(s, "ansi:"), 10, 8)
+		if err != nil || i > 15 {
+			return nil, fmt.Errorf("%w: invalid color %q", ErrInvalidState, s)
+		}
+		return ansi.BasicColor(i), nil
+	}
+	if strings.HasPrefix(s, "ansi256:") {
+		i, err := strconv.ParseUint(strings.TrimPrefix(s, "ansi256:"), 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid color %q", ErrInvalidState, s)
+		}
+		return ansi.ExtendedColor(i), nil
+	}
+	var c color.RGBA
+	if n, err := fmt.Sscanf(s, "#%02x%02x%02x%02x", &c.R, &c.G, &c.B, &c.A); err != nil || n != 4 || len(s) != 9 {
+		return nil, fmt.Errorf("%w: invalid color %q", ErrInvalidState, s)
+	}
+	return c, nil
+}
+
+// encodeMode encodes a mode as its number. DEC modes are prefixed with a
+// question mark.
+func encodeMode(m ansi.Mode) string {
+	if _, ok := m.(ansi.DECMode); ok {
+		return "?" + strconv.Itoa(m.Mode())
+	}
+	return strconv.Itoa(m.Mode())
+}
+
+// decodeMode decodes a mode encoded with [encodeMode].
+func decodeMode(s string) (ansi.Mode, error) {
+	dec := strings.HasPrefix(s, "?")
+	i, err := strconv.Atoi(strings.TrimPrefix(s, "?"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid mode %q", ErrInvalidState, s)
+	}
+	if dec {
+		return ansi.DECMode(i), nil
+	}
+	return ansi.ANSIMode(i), nil
+}
+
+// charsetName returns the name of a character set in [charsets], or an empty
+// string for the default character set.
+func charsetName(cs CharSet) string {
+	if cs == nil {
+		return ""
+	}
+	for name, set := range charsets {
+		if reflect.ValueOf(set).Pointer() == reflect.ValueOf(cs).Pointer() {
+			return name
+		}
+	}
+	return ""
+}
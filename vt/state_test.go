@@ -0,0 +1,190 @@
+package vt
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestSaveLoad(t *testing.T) {
+	term := newTestTerminal(t, 10, 4)
+	term.SetScrollbackLimit(5)
+	term.Write([]byte("line1\r\nline2\r\nline3\r\nline4\r\n" +
+		"\x1b[1;31;48;5;200mred" + ansi.SetHyperlink("https://example.com", "id=1") + "你" + ansi.ResetHyperlink() +
+		"\x1b[38;2;1;2;3mé́\x1b[m" +
+		"abcd" + // soft wrapped
+		"\x1b[?1h\x1b[4h\x1b[?25l" + // modes
+		"\x1b[3g\x1b[1;3H\x1bH" + // tab stops
+		"\x1b)0" + // charset
+		"\x1b]4;1;#ff0000\x07\x1b]2;title\x07\x1b[22t" +
+		"\x1b[2;3r\x1b[5 q\x1b7\x1b[3;2H\x1b[3m"))
+
+	var buf bytes.Buffer
+	if err := term.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newTestTerminal(t, 3, 3)
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Width() != 10 || loaded.Height() != 4 {
+		t.Fatalf("expected a 10x4 terminal, got %dx%d", loaded.Width(), loaded.Height())
+	}
+	for y := 0; y < term.Height(); y++ {
+		for x := 0; x < term.Width(); x++ {
+			want, _ := term.Cell(x, y)
+			got, _ := loaded.Cell(x, y)
+			if !got.Equal(&want) {
+				t.Errorf("cell at %d,%d doesn't match:\nwant: %#v\ngot:  %#v", x, y, want, got)
+			}
+		}
+	}
+	if got := Diff(term.Snapshot(), loaded.Snapshot()); got != nil {
+		t.Errorf("expected no difference, got %v", got)
+	}
+	assertLines(t, scrollbackText(loaded), scrollbackText(term))
+	if !reflect.DeepEqual(loaded.modes, term.modes) {
+		t.Errorf("modes don't match:\nwant: %v\ngot:  %v", term.modes, loaded.modes)
+	}
+	if !reflect.DeepEqual(loaded.TabStops(), term.TabStops()) {
+		t.Errorf("expected tab stops %v, got %v", term.TabStops(), loaded.TabStops())
+	}
+	if loaded.scr.cur != term.scr.cur || loaded.scr.saved != term.scr.saved {
+		t.Errorf("cursors don't match:\nwant: %#v %#v\ngot:  %#v %#v", term.scr.cur, term.scr.saved, loaded.scr.cur, loaded.scr.saved)
+	}
+	if loaded.scr.ScrollRegion() != term.scr.ScrollRegion() {
+		t.Errorf("expected scroll region %v, got %v", term.scr.ScrollRegion(), loaded.scr.ScrollRegion())
+	}
+	if !reflect.DeepEqual(loaded.scr.wrapped, term.scr.wrapped) {
+		t.Errorf("expected wrapped lines %v, got %v", term.scr.wrapped, loaded.scr.wrapped)
+	}
+	if loaded.Title() != "title" || len(loaded.titles) != 1 {
+		t.Errorf("expected title %q with one stacked title, got %q and %d", "title", loaded.Title(), len(loaded.titles))
+	}
+
+	// Saving the loaded terminal gives the same state.
+	var want, got bytes.Buffer
+	if err := term.Save(&want); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Save(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("saved states don't match:\nwant: %s\ngot:  %s", want.String(), got.String())
+	}
+
+	// Both terminals behave the same.
+	for _, tt := range []*Terminal{term, loaded} {
+		tt.Write([]byte("\x1b[?1049h\x0eq\x1b[?1049lx"))
+	}
+	assertLines(t, termText(loaded), termText(term))
+}
+
+func TestSaveLoadNarrowed(t *testing.T) {
+	// The scrollback lines are wider than the terminal.
+	term := newTestTerminal(t, 20, 3)
+	for i := 0; i < 6; i++ {
+		term.Write([]byte("\r\n" + strings.Repeat(strconv.Itoa(i), 15)))
+	}
+	term.ReflowOnResize = false
+	term.Resize(10, 3)
+	if n := len(term.Scrollback()[0]); n != 20 {
+		t.Fatalf("expected a 20 cells scrollback line, got %d", n)
+	}
+
+	var want bytes.Buffer
+	if err := term.Save(&want); err != nil {
+		t.Fatal(err)
+	}
+	loaded := newTestTerminal(t, 10, 3)
+	if err := loaded.Load(bytes.NewReader(want.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	assertLines(t, scrollbackText(loaded), scrollbackText(term))
+	var got bytes.Buffer
+	if err := loaded.Save(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("saved states don't match:\nwant: %s\ngot:  %s", want.String(), got.String())
+	}
+}
+
+func TestSaveLoadTabStops(t *testing.T) {
+	term := newTestTerminal(t, 20, 1)
+	term.SetTabWidth(4)
+	term.Write([]byte("\x1b[7G\x1bH\x1b[9G\x1bH"))
+
+	var buf bytes.Buffer
+	if err := term.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := newTestTerminal(t, 20, 1)
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tab width and the tab stops set with HTS are restored.
+	for _, tt := range []*Terminal{term, loaded} {
+		tt.SetTabWidth(8)
+	}
+	if got, want := loaded.TabStops(), []int{0, 6, 8, 16}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tab stops %v, got %v", want, got)
+	}
+	if got, want := loaded.TabStops(), term.TabStops(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the tab stops of the saved terminal %v, got %v", want, got)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	term := newTestTerminal(t, 4, 2)
+	term.Write([]byte("ab"))
+	for _, input := range []string{
+		`{"version":1,"width":4,"height":2}`,
+		`{"version":3,"width":4,"height":2}`,
+		`{"version":2,"width":0,"height":2}`,
+		`{"version":2,"width":4,"height":2}`,
+	} {
+		err := term.Load(strings.NewReader(input))
+		if !errors.Is(err, ErrInvalidState) {
+			t.Errorf("expected invalid state error for %s, got %v", input, err)
+		}
+	}
+	if err := term.Load(strings.NewReader("{")); err == nil {
+		t.Error("expected a decoding error")
+	}
+	assertLines(t, termText(term), []string{"ab  ", "    "})
+
+	// Break a valid state.
+	var buf bytes.Buffer
+	if err := term.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.String()
+	for _, r := range []*strings.Replacer{
+		strings.NewReplacer(`"tab_stops":[0]`, `"tab_stops":[500]`),
+		strings.NewReplacer(`"tab_stops":[0]`, `"tab_stops":[-1]`),
+		strings.NewReplacer(`"tab_width":8`, `"tab_width":0`),
+		strings.NewReplacer(`"tab_width":8`, `"tab_width":8,"hts_stops":[4]`),
+		strings.NewReplacer(`{"c":"a","w":1}`, `{"c":"a","w":-1}`),
+		strings.NewReplacer(`{"c":"b","w":1}`, `{"c":"b","w":4}`),
+	} {
+		input := r.Replace(valid)
+		if input == valid {
+			t.Fatalf("expected the state to change, got %s", input)
+		}
+		err := term.Load(strings.NewReader(input))
+		if !errors.Is(err, ErrInvalidState) {
+			t.Errorf("expected invalid state error for %s, got %v", input, err)
+		}
+	}
+	assertLines(t, termText(term), []string{"ab  ", "    "})
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/x/ansi"
 	"github.com/charmbracelet/x/ansi/parser"
@@ -23,6 +24,12 @@ type Terminal struct {
 	// Both main and alt screens.
 	scrs [2]Screen
 
+	// The lines scrolled off the top of the main screen.
+	scrollback scrollback
+
+	// The current text selection.
+	selection selection
+
 	// Character sets
 	charsets [4]CharSet
 
@@ -35,6 +42,11 @@ type Terminal struct {
 	// Terminal modes.
 	modes map[ansi.Mode]ansi.ModeSetting
 
+	// unknown holds the latest sequences the terminal doesn't handle, and
+	// unknownErr the first one of the current write in strict mode.
+	unknown    []string
+	unknownErr error
+
 	// The current focused screen.
 	scr *Screen
 
@@ -46,11 +58,43 @@ type Terminal struct {
 
 	Callbacks Callbacks
 
+	// ReflowOnResize re-wraps the soft wrapped lines of the main screen when
+	// the terminal width changes instead of truncating them. It's enabled by
+	// default.
+	ReflowOnResize bool
+
+	// StrictMode makes [Terminal.Write] return an [UnknownSequenceError] for
+	// the first sequence the terminal doesn't handle. The rest of the data is
+	// still processed. Unknown sequences are recorded either way, see
+	// [Terminal.UnknownSequences].
+	StrictMode bool
+
+	// BackgroundColorErase makes erasing, scrolling, and inserting or deleting
+	// characters and lines fill the vacated cells with the current background
+	// color (bce). Other attributes are never carried over. When disabled, the
+	// cells are filled with the default background. It's enabled by default.
+	BackgroundColorErase bool
+
+	// damage accumulates the damaged areas of the screens.
+	damage damageTracker
+
 	// The terminal's icon name and title.
 	iconName, title string
 
+	// titles is the title stack, see [Terminal.pushTitle].
+	titles []titleStackEntry
+
 	// tabstop is the list of tab stops.
 	tabstops *cellbuf.TabStops
+	// tabWidth is the interval of the default tab stops.
+	tabWidth int
+	// htsStops holds the columns of the tab stops set with [ansi.HTS], which
+	// are kept when the default tab stops change.
+	htsStops map[int]struct{}
+
+	// frame is the frame returned by [Terminal.BeginFrame], and spareFrame
+	// the last released one, reused for the next frame.
+	frame, spareFrame *Frame
 
 	// The input buffer of the terminal.
 	buf bytes.Buffer
@@ -82,7 +126,12 @@ func NewTerminal(w, h int, opts ...Option) *Terminal {
 	t.scrs[1] = *NewScreen(w, h)
 	t.scrs[0].cb = &t.Callbacks
 	t.scrs[1].cb = &t.Callbacks
+	t.scrs[0].dmg = &t.damage
+	t.scrs[1].dmg = &t.damage
+	t.scrs[0].bce = &t.BackgroundColorErase
+	t.scrs[1].bce = &t.BackgroundColorErase
 	t.scr = &t.scrs[0]
+	t.scrollback.setLimit(DefaultScrollbackLimit)
 	t.parser = ansi.NewParser() // 4MB data buffer
 	t.parser.SetHandler(ansi.Handler{
 		Print:     t.handlePrint,
@@ -98,10 +147,13 @@ func NewTerminal(w, h int, opts ...Option) *Terminal {
 	t.parser.SetParamsSize(parser.MaxParamsSize)
 	t.parser.SetDataSize(1024 * 1024 * 4) // 4MB data buffer
 	t.resetModes()
-	t.tabstops = cellbuf.DefaultTabStops(w)
+	t.tabWidth = cellbuf.DefaultTabInterval
+	t.resetTabStops()
 	t.fg = defaultFg
 	t.bg = defaultBg
 	t.cur = defaultCur
+	t.ReflowOnResize = true
+	t.BackgroundColorErase = true
 	t.registerDefaultHandlers()
 
 	for _, opt := range opts {
@@ -116,10 +168,70 @@ func (t *Terminal) Screen() *Screen {
 	return t.scr
 }
 
-// Cell returns the current focused screen cell at the given x, y position. It returns nil if the cell
-// is out of bounds.
-func (t *Terminal) Cell(x, y int) *Cell {
-	return t.scr.Cell(x, y)
+// Cell returns a copy of the current focused screen cell at the given x, y
+// position, including its content, style, and hyperlink. It reports false if
+// the position is out of bounds. The cells following a wide character are
+// reported as empty cells with a zero width.
+func (t *Terminal) Cell(x, y int) (Cell, bool) {
+	c := t.scr.Cell(x, y)
+	if c == nil {
+		return Cell{}, false
+	}
+	return *c, true
+}
+
+// FlushDamage returns the areas of the terminal screen that were damaged since
+// the last flush and resets them. Overlapping and adjacent areas are merged
+// into a set of non-overlapping [RectDamage] sorted by their top-left corner.
+// When most of the screen is damaged, it returns a single [ScreenDamage]
+// instead. It returns nil if nothing was damaged.
+func (t *Terminal) FlushDamage() []Damage {
+	return t.damage.flush(t.Width(), t.Height())
+}
+
+// DamagedCells calls f for each cell of the terminal screen that was damaged
+// since the last flush. Each cell is visited once, even when it's covered by
+// multiple damaged areas. It doesn't reset the damaged areas, use
+// [Terminal.ClearDamage] or [Terminal.FlushDamage] once they're consumed.
+func (t *Terminal) DamagedCells(f func(pos Position, cell Cell)) {
+	for _, r := range t.damage.rects(t.Width(), t.Height()) {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				if cell, ok := t.Cell(x, y); ok {
+					f(cellbuf.Pos(x, y), cell)
+				}
+			}
+		}
+	}
+}
+
+// SyncActive reports whether a synchronized update is in progress, see
+// [ansi.SynchronizedOutputMode]. During the update, the damaged areas are held
+// and [Terminal.FlushDamage] returns nil until it ends.
+func (t *Terminal) SyncActive() bool {
+	return t.isModeSet(ansi.SynchronizedOutputMode)
+}
+
+// ClearDamage discards the damaged areas of the terminal screen.
+func (t *Terminal) ClearDamage() {
+	t.damage.reset()
+}
+
+// String returns the plain text content of the current focused screen with
+// one line per row and the trailing blanks of each line trimmed. Rows are
+// joined by "\n". It ignores cell styles.
+func (t *Terminal) String() string {
+	return t.scr.String()
+}
+
+// Title returns the window title set by the application.
+func (t *Terminal) Title() string {
+	return t.title
+}
+
+// IconName returns the icon name set by the application.
+func (t *Terminal) IconName() string {
+	return t.iconName
 }
 
 // Height returns the height of the terminal.
@@ -132,14 +244,57 @@ func (t *Terminal) Width() int {
 	return t.scr.Width()
 }
 
+// Size returns the width and height of the terminal.
+func (t *Terminal) Size() (width, height int) {
+	return t.Width(), t.Height()
+}
+
 // CursorPosition returns the terminal's cursor position.
 func (t *Terminal) CursorPosition() Position {
 	x, y := t.scr.CursorPosition()
 	return cellbuf.Pos(x, y)
 }
 
-// Resize resizes the terminal.
-func (t *Terminal) Resize(width int, height int) {
+// CursorVisible returns whether the cursor is visible. This is controlled by
+// [ansi.TextCursorEnableMode] (DECTCEM).
+func (t *Terminal) CursorVisible() bool {
+	return t.isModeSet(ansi.TextCursorEnableMode)
+}
+
+// CursorStyle returns the cursor style set using [ansi.DECSCUSR].
+func (t *Terminal) CursorStyle() CursorStyle {
+	return t.scr.Cursor().Style
+}
+
+// CursorBlink returns whether the cursor is blinking. This is set using
+// [ansi.DECSCUSR].
+func (t *Terminal) CursorBlink() bool {
+	return !t.scr.Cursor().Steady
+}
+
+// Resize resizes the terminal. The main screen keeps the cursor line on the
+// screen, lines scrolled off the top go to the scrollback, and lines are
+// pulled back from the scrollback when the height grows. When
+// [Terminal.ReflowOnResize] is set and the width changes, the soft wrapped
+// lines of the main screen and the scrollback are re-wrapped to the new width
+// keeping the cursor on the same character.
+//
+// It reports whether the size changed, the whole screen is then damaged with
+// a [ScreenDamage]. Resizing to the current size does nothing.
+func (t *Terminal) Resize(width int, height int) bool {
+	if width == t.scrs[0].Width() && height == t.scrs[0].Height() {
+		return false
+	}
+
+	main := width > 0 && height > 0
+	rewrap := t.ReflowOnResize && width != t.scrs[0].Width()
+	if main && t.scr == &t.scrs[0] {
+		t.atPhantom = t.resizeMain(width, height, rewrap, t.atPhantom)
+		t.scrs[1].Resize(width, height)
+		t.tabstops.Resize(width)
+		return true
+	}
+
 	x, y := t.scr.CursorPosition()
 	if t.atPhantom {
 		if x < width-1 {
@@ -161,11 +316,35 @@ func (t *Terminal) Resize(width int, height int) {
 		x = width - 1
 	}
 
-	t.scrs[0].Resize(width, height)
+	if main {
+		t.resizeMain(width, height, rewrap, false)
+	} else {
+		t.scrs[0].Resize(width, height)
+	}
 	t.scrs[1].Resize(width, height)
-	t.tabstops = cellbuf.DefaultTabStops(width)
+	t.tabstops.Resize(width)
 
 	t.setCursor(x, y)
+	return true
+}
+
+// Reset performs a full terminal reset as if [ansi.RIS] was received. It
+// clears the screens and the scrollback and restores the default modes, tab
+// stops, and colors.
+func (t *Terminal) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.parser.Reset()
+	t.fullReset()
+}
+
+// SoftReset performs a soft terminal reset as if [ansi.DECSTR] was received.
+// It restores the default modes, margins, and graphic rendition keeping the
+// screen content.
+func (t *Terminal) SoftReset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.softReset()
 }
 
 // Read reads data from the terminal input buffer.
@@ -185,6 +364,15 @@ func (t *Terminal) Read(p []byte) (n int, err error) {
 	return t.buf.Read(p)
 }
 
+// respond sends the reply to a host query, see [Callbacks.Response].
+func (t *Terminal) respond(s string) {
+	if t.Callbacks.Response != nil {
+		t.Callbacks.Response([]byte(s))
+		return
+	}
+	t.buf.WriteString(s)
+}
+
 // Close closes the terminal.
 func (t *Terminal) Close() error {
 	t.mu.Lock()
@@ -203,6 +391,11 @@ func (t *Terminal) Write(p []byte) (n int, err error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	defer func() {
+		err = t.unknownErr
+		t.unknownErr = nil
+	}()
+
 	for len(p) > 0 {
 		action := t.parser.Advance(p[0])
 		if action == parser.CollectAction && t.parser.State() == parser.Utf8State {
@@ -210,9 +403,15 @@ func (t *Terminal) Write(p []byte) (n int, err error) {
 			var gr []byte
 			var width int
 			gr, p, width, _ = uniseg.FirstGraphemeCluster(p, -1)
-			t.handleGrapheme(string(gr), width)
 			// Reset the parser back to ground state.
 			t.parser.Reset()
+			if r, size := utf8.DecodeRune(gr); size == len(gr) && r >= ansi.PAD && r <= ansi.APC {
+				// A UTF-8 encoded C1 control code is handled like its
+				// 8-bit form.
+				t.parser.Advance(byte(r))
+			} else {
+				t.handleGrapheme(string(gr), width)
+			}
 			n += len(gr)
 		} else {
 			p = p[1:]
@@ -309,7 +508,37 @@ func (t *Terminal) SetIndexedColor(i int, c color.Color) {
 	t.colors[i] = c
 }
 
-// resetTabStops resets the terminal tab stops to the default set.
+// TabStops returns the columns of the terminal tab stops in ascending order.
+func (t *Terminal) TabStops() []int {
+	var stops []int
+	for x := 0; x < t.Width(); x++ {
+		if t.tabstops.IsStop(x) {
+			stops = append(stops, x)
+		}
+	}
+	return stops
+}
+
+// SetTabWidth sets the interval of the default tab stops, 8 columns by
+// default. The default tab stops are reset to every n columns, while the tab
+// stops set with [ansi.HTS] are kept until [ansi.TBC] clears them. A width
+// less than 1 resets the default interval.
+func (t *Terminal) SetTabWidth(n int) {
+	if n < 1 {
+		n = cellbuf.DefaultTabInterval
+	}
+	t.tabWidth = n
+	t.tabstops = cellbuf.NewTabStops(t.Width(), t.tabWidth)
+	for x := range t.htsStops {
+		if x < t.Width() {
+			t.tabstops.Set(x)
+		}
+	}
+}
+
+// resetTabStops resets the terminal tab stops to the default set, clearing
+// the ones set with [ansi.HTS].
 func (t *Terminal) resetTabStops() {
-	t.tabstops = cellbuf.DefaultTabStops(t.Width())
+	t.tabstops = cellbuf.NewTabStops(t.Width(), t.tabWidth)
+	t.htsStops = nil
 }
@@ -1,8 +1,12 @@
 package vt
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/charmbracelet/x/cellbuf"
 )
 
@@ -187,13 +191,13 @@ var cases = []struct {
 			"\x1b[?6h",  // enable origin mode
 			"\x1b[?69h", // enable left/right margin mode
 			"\x1b[2;5s", // set left/right margin
-			"\x1b[4G",   // move to column 4
+			"\x1b[4G",   // move to column 4 of the margins
 			"A",
 			"\x1b[1G",
 			"\r",
 			"X",
 		},
-		want: []string{" X A      "},
+		want: []string{" X  A     "},
 		pos:  cellbuf.Pos(2, 0),
 	},
 
@@ -470,6 +474,116 @@ var cases = []struct {
 		},
 		pos: cellbuf.Pos(5, 2),
 	},
+	{
+		name: "DECOM Set Moves Cursor to Top Margin",
+		w:    5, h: 5,
+		input: []string{
+			"\x1b[2;4r",
+			"\x1b[3;3H",
+			"\x1b[?6h",
+			"X",
+		},
+		want: []string{
+			"     ",
+			"X    ",
+			"     ",
+			"     ",
+			"     ",
+		},
+		pos: cellbuf.Pos(1, 1),
+	},
+	{
+		name: "DECOM CUP Relative to Top Margin",
+		w:    5, h: 5,
+		input: []string{
+			"\x1b[2;4r",
+			"\x1b[?6h",
+			"\x1b[2;3H",
+			"X",
+		},
+		want: []string{
+			"     ",
+			"     ",
+			"  X  ",
+			"     ",
+			"     ",
+		},
+		pos: cellbuf.Pos(3, 2),
+	},
+	{
+		name: "DECOM CUP Clamped to Bottom Margin",
+		w:    5, h: 5,
+		input: []string{
+			"\x1b[2;4r",
+			"\x1b[?6h",
+			"\x1b[5;1H",
+			"X",
+		},
+		want: []string{
+			"     ",
+			"     ",
+			"     ",
+			"X    ",
+			"     ",
+		},
+		pos: cellbuf.Pos(1, 3),
+	},
+	{
+		name: "DECOM Reset Restores Absolute Addressing",
+		w:    5, h: 5,
+		input: []string{
+			"\x1b[2;4r",
+			"\x1b[?6h",
+			"\x1b[?6l",
+			"\x1b[5;1H",
+			"X",
+		},
+		want: []string{
+			"     ",
+			"     ",
+			"     ",
+			"     ",
+			"X    ",
+		},
+		pos: cellbuf.Pos(1, 4),
+	},
+	{
+		name: "DECOM VPA Relative to Top Margin",
+		w:    5, h: 5,
+		input: []string{
+			"\x1b[2;4r",
+			"\x1b[?6h",
+			"\x1b[2d",
+			"X",
+		},
+		want: []string{
+			"     ",
+			"     ",
+			"X    ",
+			"     ",
+			"     ",
+		},
+		pos: cellbuf.Pos(1, 2),
+	},
+	{
+		name: "DECOM HPR Relative to Margins",
+		w:    5, h: 5,
+		input: []string{
+			"\x1b[2;4r",
+			"\x1b[?6h",
+			"\x1b[2;1H",
+			"\x1b[2a",
+			"X",
+		},
+		want: []string{
+			"     ",
+			"     ",
+			"  X  ",
+			"     ",
+			"     ",
+		},
+		pos: cellbuf.Pos(3, 2),
+	},
 	{
 		name: "CUP Pending Wrap is Unset",
 		w:    10, h: 1,
@@ -695,6 +809,24 @@ var cases = []struct {
 		pos: cellbuf.Pos(1, 1),
 	},
 
+	{
+		name: "DL Moves Wide Characters",
+		w:    6, h: 3,
+		input: []string{
+			"ABCDEF\r\n",
+			"你好X\r\n",
+			"GH",
+			"\x1b[1;1H",
+			"\x1b[M",
+		},
+		want: []string{
+			"你好X ",
+			"GH    ",
+			"      ",
+		},
+		pos: cellbuf.Pos(0, 0),
+	},
+
 	// Insert Line [ansi.IL]
 	{
 		name: "IL Simple Insert Line",
@@ -781,6 +913,100 @@ var cases = []struct {
 		pos: cellbuf.Pos(1, 1),
 	},
 
+	// Insert Character [ansi.ICH]
+	{
+		name: "ICH Simple Insert Character",
+		w:    8, h: 1,
+		input: []string{
+			"ABC123",
+			"\x1b[3G",
+			"\x1b[2@",
+		},
+		want: []string{"AB  C123"},
+		pos:  cellbuf.Pos(2, 0),
+	},
+	{
+		name: "ICH Pushes Characters Off Line",
+		w:    6, h: 1,
+		input: []string{
+			"ABC123",
+			"\x1b[2G",
+			"\x1b[3@",
+		},
+		want: []string{"A   BC"},
+		pos:  cellbuf.Pos(1, 0),
+	},
+	{
+		name: "ICH At Last Column",
+		w:    6, h: 1,
+		input: []string{
+			"ABC123",
+			"\x1b[6G",
+			"\x1b[10@",
+		},
+		want: []string{"ABC12 "},
+		pos:  cellbuf.Pos(5, 0),
+	},
+	{
+		name: "ICH Inside Left/Right Scroll Region",
+		w:    8, h: 1,
+		input: []string{
+			"ABC123",
+			"\x1b[?69h", // enable left/right margins
+			"\x1b[2;4s", // scroll region left/right
+			"\x1b[2G",
+			"\x1b[@",
+		},
+		want: []string{"A BC23  "},
+		pos:  cellbuf.Pos(1, 0),
+	},
+	{
+		name: "ICH Outside Left/Right Scroll Region",
+		w:    8, h: 1,
+		input: []string{
+			"ABC123",
+			"\x1b[?69h", // enable left/right margins
+			"\x1b[2;4s", // scroll region left/right
+			"\x1b[5G",
+			"\x1b[@",
+		},
+		want: []string{"ABC123  "},
+		pos:  cellbuf.Pos(4, 0),
+	},
+	{
+		name: "ICH Split Wide Character",
+		w:    8, h: 1,
+		input: []string{
+			"A橋123",
+			"\x1b[3G",
+			"\x1b[@",
+		},
+		want: []string{"A   123 "},
+		pos:  cellbuf.Pos(2, 0),
+	},
+	{
+		name: "ICH Shifts Wide Character",
+		w:    8, h: 1,
+		input: []string{
+			"A橋123",
+			"\x1b[2G",
+			"\x1b[@",
+		},
+		want: []string{"A 橋123 "},
+		pos:  cellbuf.Pos(1, 0),
+	},
+	{
+		name: "ICH Pushes Wide Character Off Line",
+		w:    6, h: 1,
+		input: []string{
+			"ABCD橋",
+			"\x1b[1G",
+			"\x1b[@",
+		},
+		want: []string{" ABCD "},
+		pos:  cellbuf.Pos(0, 0),
+	},
+
 	// Delete Character [ansi.DCH]
 	{
 		name: "DCH Simple Delete Character",
@@ -848,6 +1074,39 @@ var cases = []struct {
 		want: []string{"A 123     "},
 		pos:  cellbuf.Pos(2, 0),
 	},
+	{
+		name: "DCH Wide Character Head",
+		w:    10, h: 1,
+		input: []string{
+			"A橋123",
+			"\x1b[2G",
+			"\x1b[P",
+		},
+		want: []string{"A 123     "},
+		pos:  cellbuf.Pos(1, 0),
+	},
+	{
+		name: "DCH Range Ends Inside Wide Character",
+		w:    10, h: 1,
+		input: []string{
+			"AB橋123",
+			"\x1b[2G",
+			"\x1b[2P",
+		},
+		want: []string{"A 123     "},
+		pos:  cellbuf.Pos(1, 0),
+	},
+	{
+		name: "DCH Shifts Wide Character",
+		w:    10, h: 1,
+		input: []string{
+			"AB橋123",
+			"\x1b[2G",
+			"\x1b[P",
+		},
+		want: []string{"A橋123    "},
+		pos:  cellbuf.Pos(1, 0),
+	},
 
 	// Set Top and Bottom Margins [ansi.DECSTBM]
 	{
@@ -930,6 +1189,80 @@ var cases = []struct {
 		},
 		pos: cellbuf.Pos(3, 2),
 	},
+	{
+		name: "DECSTBM Newline Scrolls Only Inside Margins",
+		w:    3, h: 5,
+		input: []string{
+			"A\r\nB\r\nC\r\nD\r\nE",
+			"\x1b[2;4r", // set scroll region at lines 2-4
+			"\x1b[4;1H", // move to the bottom margin
+			"\n\n",      // scroll the region up twice
+			"X",
+		},
+		want: []string{
+			"A  ",
+			"D  ",
+			"   ",
+			"X  ",
+			"E  ",
+		},
+		pos: cellbuf.Pos(1, 3),
+	},
+	{
+		name: "DECSTBM Reverse Index Scrolls Only Inside Margins",
+		w:    3, h: 5,
+		input: []string{
+			"A\r\nB\r\nC\r\nD\r\nE",
+			"\x1b[2;4r", // set scroll region at lines 2-4
+			"\x1b[2;1H", // move to the top margin
+			"\x1bM",     // reverse index
+		},
+		want: []string{
+			"A  ",
+			"   ",
+			"B  ",
+			"C  ",
+			"E  ",
+		},
+		pos: cellbuf.Pos(0, 1),
+	},
+	{
+		name: "DECSTBM Reset Restores Full Screen Scrolling",
+		w:    3, h: 5,
+		input: []string{
+			"A\r\nB\r\nC\r\nD\r\nE",
+			"\x1b[2;3r", // set scroll region at lines 2-3
+			"\x1b[r",    // reset scroll region
+			"\x1b[5;1H", // move to the bottom of the screen
+			"\nX",
+		},
+		want: []string{
+			"B  ",
+			"C  ",
+			"D  ",
+			"E  ",
+			"X  ",
+		},
+		pos: cellbuf.Pos(1, 4),
+	},
+	{
+		name: "DECSTBM Bottom Beyond Screen",
+		w:    3, h: 5,
+		input: []string{
+			"A\r\nB\r\nC\r\nD\r\nE",
+			"\x1b[2;99r", // bottom margin is clamped to the screen
+			"\x1b[5;1H",  // move to the bottom of the screen
+			"\nX",
+		},
+		want: []string{
+			"A  ",
+			"C  ",
+			"D  ",
+			"E  ",
+			"X  ",
+		},
+		pos: cellbuf.Pos(1, 4),
+	},
 
 	// Set Left/Right Margins [ansi.DECSLRM]
 	{
@@ -1471,7 +1804,7 @@ var cases = []struct {
 		},
 		want: []string{
 			"        ",
-			"        ",
+			"  F     ",
 			"GHI     ",
 		},
 		pos: cellbuf.Pos(1, 1),
@@ -1496,57 +1829,215 @@ var cases = []struct {
 		pos: cellbuf.Pos(1, 1),
 	},
 
-	// Reverse Index [ansi.RI]
 	{
-		name: "RI No Scroll Region Top of Screen",
-		w:    10, h: 4,
+		name: "ED Erase Below Default Parameter",
+		w:    8, h: 3,
 		input: []string{
-			"\x1b[1;1H", // move to top-left
-			"\x1b[2J",   // clear screen
-			"A\r\n",
-			"B\r\n",
-			"C\r\n",
-			"\x1b[1;1H", // move to top-left
-			"\x1bM",     // reverse index
+			"ABC\r\n",
+			"DEF\r\n",
+			"GHI",
+			"\x1b[2;2H",
+			"\x1b[J",
+		},
+		want: []string{
+			"ABC     ",
+			"D       ",
+			"        ",
+		},
+		pos: cellbuf.Pos(1, 1),
+	},
+	{
+		name: "ED Erase Above with Multi-Cell Character",
+		w:    8, h: 2,
+		input: []string{
+			"AB橋C\r\n",
+			"DEF",
+			"\x1b[1;3H",
+			"\x1b[1J",
+		},
+		want: []string{
+			"    C   ",
+			"DEF     ",
+		},
+		pos: cellbuf.Pos(2, 0),
+	},
+	{
+		name: "ED Erase Complete Resets Pending Wrap",
+		w:    4, h: 2,
+		input: []string{
+			"ABCD",
+			"\x1b[2J",
 			"X",
 		},
 		want: []string{
-			"X         ",
-			"A         ",
-			"B         ",
-			"C         ",
+			"   X",
+			"    ",
 		},
-		pos: cellbuf.Pos(1, 0),
+		pos: cellbuf.Pos(3, 0),
 	},
 	{
-		name: "RI No Scroll Region Not Top of Screen",
+		name: "ED Erase Scrollback",
+		w:    8, h: 3,
+		input: []string{
+			"ABC\r\n",
+			"DEF\r\n",
+			"GHI",
+			"\x1b[2;2H",
+			"\x1b[3J",
+		},
+		want: []string{
+			"ABC     ",
+			"DEF     ",
+			"GHI     ",
+		},
+		pos: cellbuf.Pos(1, 1),
+	},
+
+	// Next Line [ansi.NEL]
+	{
+		name: "NEL Moves to Start of Next Line",
 		w:    10, h: 3,
 		input: []string{
 			"\x1b[1;1H", // move to top-left
 			"\x1b[2J",   // clear screen
-			"A\r\n",
-			"B\r\n",
-			"C",
-			"\x1b[2;1H",
-			"\x1bM", // reverse index
+			"AB",
+			"\x1bE", // next line
 			"X",
 		},
 		want: []string{
+			"AB        ",
 			"X         ",
-			"B         ",
-			"C         ",
+			"          ",
 		},
-		pos: cellbuf.Pos(1, 0),
+		pos: cellbuf.Pos(1, 1),
 	},
 	{
-		name: "RI Top/Bottom Scroll Region",
+		name: "NEL C1 Control",
 		w:    10, h: 3,
 		input: []string{
 			"\x1b[1;1H", // move to top-left
 			"\x1b[2J",   // clear screen
-			"A\r\n",
-			"B\r\n",
-			"C",
+			"AB",
+			"\x85", // next line
+			"X",
+		},
+		want: []string{
+			"AB        ",
+			"X         ",
+			"          ",
+		},
+		pos: cellbuf.Pos(1, 1),
+	},
+	{
+		name: "NEL Bottom of Scroll Region",
+		w:    10, h: 4,
+		input: []string{
+			"\x1b[1;1H", // move to top-left
+			"\x1b[2J",   // clear screen
+			"\x1b[1;3r", // scroll region
+			"\x1b[4;1H", // below scroll region
+			"B",
+			"\x1b[3;1H", // move to last row of region
+			"AB",
+			"\x1bE", // next line
+			"X",
+		},
+		want: []string{
+			"          ",
+			"AB        ",
+			"X         ",
+			"B         ",
+		},
+		pos: cellbuf.Pos(1, 2),
+	},
+	{
+		name: "NEL Inside of Left/Right Scroll Region",
+		w:    10, h: 3,
+		input: []string{
+			"\x1b[1;1H", // move to top-left
+			"\x1b[2J",   // clear screen
+			"\x1b[?69h", // enable left/right margins
+			"\x1b[3;6s", // scroll region left/right
+			"\x1b[1;4H",
+			"\x1bE", // next line
+			"X",
+		},
+		want: []string{
+			"          ",
+			"  X       ",
+			"          ",
+		},
+		pos: cellbuf.Pos(3, 1),
+	},
+
+	// Reverse Index [ansi.RI]
+	{
+		name: "RI Cancels Pending Wrap",
+		w:    3, h: 2,
+		input: []string{
+			"\x1b[1;1H", // move to top-left
+			"\x1b[2J",   // clear screen
+			"\x1b[2;1H", // move to bottom-left
+			"ABC",
+			"\x1bM", // reverse index
+			"X",
+		},
+		want: []string{
+			"  X",
+			"ABC",
+		},
+		pos: cellbuf.Pos(2, 0),
+	},
+	{
+		name: "RI No Scroll Region Top of Screen",
+		w:    10, h: 4,
+		input: []string{
+			"\x1b[1;1H", // move to top-left
+			"\x1b[2J",   // clear screen
+			"A\r\n",
+			"B\r\n",
+			"C\r\n",
+			"\x1b[1;1H", // move to top-left
+			"\x1bM",     // reverse index
+			"X",
+		},
+		want: []string{
+			"X         ",
+			"A         ",
+			"B         ",
+			"C         ",
+		},
+		pos: cellbuf.Pos(1, 0),
+	},
+	{
+		name: "RI No Scroll Region Not Top of Screen",
+		w:    10, h: 3,
+		input: []string{
+			"\x1b[1;1H", // move to top-left
+			"\x1b[2J",   // clear screen
+			"A\r\n",
+			"B\r\n",
+			"C",
+			"\x1b[2;1H",
+			"\x1bM", // reverse index
+			"X",
+		},
+		want: []string{
+			"X         ",
+			"B         ",
+			"C         ",
+		},
+		pos: cellbuf.Pos(1, 0),
+	},
+	{
+		name: "RI Top/Bottom Scroll Region",
+		w:    10, h: 3,
+		input: []string{
+			"\x1b[1;1H", // move to top-left
+			"\x1b[2J",   // clear screen
+			"A\r\n",
+			"B\r\n",
+			"C",
 			"\x1b[2;3r", // scroll region
 			"\x1b[2;1H",
 			"\x1bM", // reverse index
@@ -1781,6 +2272,311 @@ var cases = []struct {
 		want: []string{"                       "},
 		pos:  cellbuf.Pos(22, 0),
 	},
+
+	// Horizontal Tab [ansi.HT]
+	{
+		name: "HT Default Tab Stops",
+		w:    20, h: 1,
+		input: []string{
+			"A\tB\tC",
+		},
+		want: []string{"A       B       C   "},
+		pos:  cellbuf.Pos(17, 0),
+	},
+	{
+		name: "HT Stops At Last Column",
+		w:    12, h: 1,
+		input: []string{
+			"\t\t\tA",
+		},
+		want: []string{"           A"},
+		pos:  cellbuf.Pos(11, 0),
+	},
+	{
+		name: "HT Stops At Right Margin",
+		w:    20, h: 1,
+		input: []string{
+			"\x1b[?69h", // enable left/right margins
+			"\x1b[1;6s", // scroll region left/right
+			"\tA",
+		},
+		want: []string{"     A              "},
+		pos:  cellbuf.Pos(6, 0),
+	},
+	{
+		name: "HT Right Of Right Margin",
+		w:    20, h: 1,
+		input: []string{
+			"\x1b[?69h", // enable left/right margins
+			"\x1b[1;6s", // scroll region left/right
+			"\x1b[10G",
+			"\tA",
+		},
+		want: []string{"                A   "},
+		pos:  cellbuf.Pos(17, 0),
+	},
+
+	// Horizontal Tab Set [ansi.HTS]
+	{
+		name: "HTS Custom Tab Stops",
+		w:    20, h: 1,
+		input: []string{
+			"\x1b[3g",  // clear all tab stops
+			"\x1b[4G",  // move to column 4
+			"\x1bH",    // set tab stop
+			"\x1b[11G", // move to column 11
+			"\x1bH",    // set tab stop
+			"\r\tA\tB",
+		},
+		want: []string{"   A      B         "},
+		pos:  cellbuf.Pos(11, 0),
+	},
+
+	// Screen Alignment Pattern [ansi.DECALN]
+	{
+		name: "DECALN Fills Screen",
+		w:    4, h: 3,
+		input: []string{
+			"\x1b[31mAB\r\n",
+			"\x1b#8",
+		},
+		want: []string{"EEEE", "EEEE", "EEEE"},
+		pos:  cellbuf.Pos(0, 0),
+	},
+	{
+		name: "DECALN Resets Margins",
+		w:    4, h: 3,
+		input: []string{
+			"\x1b[?69h", // enable left/right margins
+			"\x1b[2;3s", // scroll region left/right
+			"\x1b[2;3r", // scroll region top/bottom
+			"\x1b#8",
+			"\x1b[3;1H",
+			"\n",      // scroll the whole screen
+			"\x1b[4C", // move right past the former margin
+			"X",
+		},
+		want: []string{"EEEE", "EEEE", "   X"},
+		pos:  cellbuf.Pos(3, 2),
+	},
+
+	// Select Character Set [ansi.SCS]
+	{
+		name: "SCS Special Drawing G0",
+		w:    4, h: 1,
+		input: []string{
+			"\x1b(0qx",
+			"\x1b(Bqx",
+		},
+		want: []string{"─│qx"},
+		pos:  cellbuf.Pos(3, 0),
+	},
+	{
+		name: "SCS Special Drawing Box",
+		w:    4, h: 3,
+		input: []string{
+			"\x1b(0",
+			"lqqk\r\n",
+			"x  x\r\n",
+			"mqqj",
+			"\x1b(B",
+		},
+		want: []string{"┌──┐", "│  │", "└──┘"},
+		pos:  cellbuf.Pos(3, 2),
+	},
+	{
+		name: "SCS Special Drawing G1 Shift Out",
+		w:    6, h: 1,
+		input: []string{
+			"\x1b)0",
+			"q",
+			"\x0eqn", // shift out, G1 into GL
+			"\x0fq",  // shift in, G0 into GL
+		},
+		want: []string{"q─┼q  "},
+		pos:  cellbuf.Pos(4, 0),
+	},
+	{
+		name: "SCS UK G0",
+		w:    4, h: 1,
+		input: []string{
+			"\x1b(A$a",
+		},
+		want: []string{"£a  "},
+		pos:  cellbuf.Pos(2, 0),
+	},
+
+	// Insert/Replace Mode [ansi.IRM]
+	{
+		name: "IRM Insert Into Filled Line",
+		w:    5, h: 1,
+		input: []string{
+			"abcde",
+			"\x1b[4h", // enable insert mode
+			"\x1b[1;2H",
+			"X",
+		},
+		want: []string{"aXbcd"},
+		pos:  cellbuf.Pos(2, 0),
+	},
+	{
+		name: "IRM Insert Wide Character",
+		w:    6, h: 1,
+		input: []string{
+			"abcdef",
+			"\x1b[4h", // enable insert mode
+			"\x1b[1;2H",
+			"你",
+		},
+		want: []string{"a你bcd"},
+		pos:  cellbuf.Pos(3, 0),
+	},
+	{
+		name: "IRM Insert Pushes Out Wide Character",
+		w:    5, h: 1,
+		input: []string{
+			"abc你",
+			"\x1b[4h", // enable insert mode
+			"\x1b[1;1H",
+			"X",
+		},
+		want: []string{"Xabc "},
+		pos:  cellbuf.Pos(1, 0),
+	},
+	{
+		name: "IRM Disabled Replaces",
+		w:    5, h: 1,
+		input: []string{
+			"abcde",
+			"\x1b[4h\x1b[4l", // enable then disable insert mode
+			"\x1b[1;2H",
+			"X",
+		},
+		want: []string{"aXcde"},
+		pos:  cellbuf.Pos(2, 0),
+	},
+	// Auto Wrap Mode [ansi.DECAWM]
+	{
+		name: "DECAWM Pending Wrap at Last Column",
+		w:    5, h: 2,
+		input: []string{
+			"abcde",
+		},
+		want: []string{"abcde", "     "},
+		pos:  cellbuf.Pos(4, 0),
+	},
+	{
+		name: "DECAWM Next Character Wraps",
+		w:    5, h: 2,
+		input: []string{
+			"abcde",
+			"f",
+		},
+		want: []string{"abcde", "f    "},
+		pos:  cellbuf.Pos(1, 1),
+	},
+	{
+		name: "DECAWM Pending Wrap Unset by CR",
+		w:    5, h: 2,
+		input: []string{
+			"abcde",
+			"\rX",
+		},
+		want: []string{"Xbcde", "     "},
+		pos:  cellbuf.Pos(1, 0),
+	},
+	{
+		name: "DECAWM Wide Character Wraps",
+		w:    5, h: 2,
+		input: []string{
+			"abcd",
+			"你",
+		},
+		want: []string{"abcd ", "你   "},
+		pos:  cellbuf.Pos(2, 1),
+	},
+	{
+		name: "DECAWM Disabled Overwrites Last Column",
+		w:    5, h: 2,
+		input: []string{
+			"\x1b[?7l", // disable auto wrap
+			"abcde",
+			"f",
+		},
+		want: []string{"abcdf", "     "},
+		pos:  cellbuf.Pos(4, 0),
+	},
+	{
+		name: "DECAWM Disabled Wide Character at Last Column",
+		w:    5, h: 2,
+		input: []string{
+			"\x1b[?7l", // disable auto wrap
+			"abcd",
+			"你",
+		},
+		want: []string{"abc你", "     "},
+		pos:  cellbuf.Pos(3, 0),
+	},
+	{
+		name: "DECAWM Disabled Cancels Pending Wrap",
+		w:    5, h: 2,
+		input: []string{
+			"abcde",
+			"\x1b[?7l", // disable auto wrap
+			"f",
+		},
+		want: []string{"abcdf", "     "},
+		pos:  cellbuf.Pos(4, 0),
+	},
+	// Alternate Screen Buffer [ansi.AltScreenSaveCursorMode]
+	{
+		name: "AltScreenSaveCursor Restores Primary Screen And Cursor",
+		w:    8, h: 3,
+		input: []string{
+			"abc\r\ndef",
+			"\x1b[?1049h",
+			"\x1b[2;2HXYZ",
+			"\x1b[?1049l",
+		},
+		want: []string{"abc     ", "def     ", "        "},
+		pos:  cellbuf.Pos(3, 1),
+	},
+	{
+		name: "AltScreenSaveCursor Clears Alternate Screen",
+		w:    8, h: 2,
+		input: []string{
+			"\x1b[?1049h",
+			"alt",
+			"\x1b[?1049l",
+			"\x1b[?1049h",
+		},
+		want: []string{"        ", "        "},
+		pos:  cellbuf.Pos(0, 0),
+	},
+	{
+		name: "AltScreenSaveCursor Set Twice",
+		w:    8, h: 2,
+		input: []string{
+			"ab",
+			"\x1b[?1049h",
+			"alt",
+			"\x1b[?1049h",
+		},
+		want: []string{"alt     ", "        "},
+		pos:  cellbuf.Pos(3, 0),
+	},
+	{
+		name: "AltScreen Keeps Primary Screen",
+		w:    8, h: 2,
+		input: []string{
+			"main",
+			"\x1b[?1047h",
+			"alt",
+			"\x1b[?1047l",
+		},
+		want: []string{"main    ", "        "},
+		pos:  cellbuf.Pos(4, 0),
+	},
 }
 
 // TestTerminal tests the terminal.
@@ -1808,13 +2604,278 @@ func TestTerminal(t *testing.T) {
 	}
 }
 
+// TestTerminalString tests the plain text content of the terminal.
+func TestTerminalString(t *testing.T) {
+	term := newTestTerminal(t, 8, 4)
+	term.Write([]byte("\x1b[1;31mhello\x1b[m  \r\n"))
+	term.Write([]byte("你好 world"))
+	term.Write([]byte("\x1b[4;3H\x1b[44m  x"))
+
+	want := "hello\n你好 wor\nld\n    x"
+	if got := term.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	term.Write([]byte("\x1b[2J"))
+	if got, want := term.String(), "\n\n\n"; got != want {
+		t.Errorf("expected %q after clearing the screen, got %q", want, got)
+	}
+}
+
+// TestTabStops tests setting and clearing tab stops.
+func TestTabStops(t *testing.T) {
+	term := newTestTerminal(t, 20, 1)
+	if got, want := term.TabStops(), []int{0, 8, 16}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected default tab stops %v, got %v", want, got)
+	}
+
+	term.Write([]byte("\x1b[5G\x1bH\x1b[9G\x1b[g"))
+	if got, want := term.TabStops(), []int{0, 4, 16}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tab stops %v, got %v", want, got)
+	}
+
+	// Resizing keeps the custom tab stops.
+	term.Resize(30, 1)
+	if got, want := term.TabStops(), []int{0, 4, 16, 24}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tab stops %v after resize, got %v", want, got)
+	}
+
+	term.Write([]byte("\x1b[3g"))
+	if got := term.TabStops(); len(got) != 0 {
+		t.Errorf("expected no tab stops, got %v", got)
+	}
+
+	term.Write([]byte("\x1b[?5W"))
+	if got, want := term.TabStops(), []int{0, 8, 16, 24}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected default tab stops %v after reset, got %v", want, got)
+	}
+}
+
+func TestSetTabWidth(t *testing.T) {
+	term := newTestTerminal(t, 20, 1)
+	// Set a custom tab stop at column 3.
+	term.Write([]byte("\x1b[4G\x1bH\r"))
+
+	term.SetTabWidth(4)
+	if got, want := term.TabStops(), []int{0, 3, 4, 8, 12, 16}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tab stops %v, got %v", want, got)
+	}
+	var cols []int
+	for i := 0; i < 6; i++ {
+		term.Write([]byte("\t"))
+		cols = append(cols, term.CursorPosition().X)
+	}
+	if want := []int{3, 4, 8, 12, 16, 19}; !reflect.DeepEqual(cols, want) {
+		t.Errorf("expected HT to move to columns %v, got %v", want, cols)
+	}
+
+	// The tab width applies to the resized columns and resets.
+	term.Resize(30, 1)
+	if got, want := term.TabStops(), []int{0, 3, 4, 8, 12, 16, 20, 24, 28}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tab stops %v after resize, got %v", want, got)
+	}
+	term.Write([]byte("\x1b[3g\x1b[?5W"))
+	if got, want := term.TabStops(), []int{0, 4, 8, 12, 16, 20, 24, 28}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tab stops %v after reset, got %v", want, got)
+	}
+
+	term.SetTabWidth(0)
+	if got, want := term.TabStops(), []int{0, 8, 16, 24}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected default tab stops %v, got %v", want, got)
+	}
+
+	// Tab stops set on a default column are kept too, until cleared.
+	term.Write([]byte("\x1b[17G\x1bH\x1b[6G\x1bH"))
+	term.SetTabWidth(5)
+	if got, want := term.TabStops(), []int{0, 5, 10, 15, 16, 20, 25}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tab stops %v, got %v", want, got)
+	}
+	term.Write([]byte("\x1b[17G\x1b[g"))
+	term.SetTabWidth(8)
+	if got, want := term.TabStops(), []int{0, 5, 8, 16, 24}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tab stops %v after clearing one, got %v", want, got)
+	}
+	term.Write([]byte("\x1b[3g"))
+	term.SetTabWidth(8)
+	if got, want := term.TabStops(), []int{0, 8, 16, 24}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tab stops %v after clearing all, got %v", want, got)
+	}
+}
+
+// TestEditingBackground tests that editing and erasing sequences fill the
+// vacated cells with the current background color and no other attributes.
+func TestEditingBackground(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		cells []Position
+	}{
+		{"ICH", "ABCDEF\x1b[41m\x1b[1;2H\x1b[2@", []Position{{X: 1}, {X: 2}}},
+		{"DCH", "ABCDEF\x1b[41m\x1b[1;2H\x1b[2P", []Position{{X: 4}, {X: 5}}},
+		{"IL", "ABC\r\nDEF\x1b[41m\x1b[1;2H\x1b[L", []Position{{X: 0}, {X: 5}}},
+		{"DL", "ABC\r\nDEF\x1b[41m\x1b[1;2H\x1b[M", []Position{{X: 0, Y: 1}, {X: 5, Y: 1}}},
+		{"ED Below", "ABC\r\nDEF\x1b[1;4;41m\x1b[1;2H\x1b[J", []Position{{X: 1}, {X: 5}, {X: 0, Y: 1}}},
+		{"ED Above", "ABC\r\nDEF\x1b[1;4;41m\x1b[2;2H\x1b[1J", []Position{{X: 0}, {X: 5}, {X: 1, Y: 1}}},
+		{"ED Complete", "ABC\r\nDEF\x1b[1;4;41m\x1b[2J", []Position{{X: 0}, {X: 5, Y: 1}}},
+		{"EL Right", "ABCDEF\x1b[1;4;41m\x1b[1;3H\x1b[K", []Position{{X: 2}, {X: 5}}},
+		{"EL Left", "ABCDEF\x1b[1;4;41m\x1b[1;3H\x1b[1K", []Position{{X: 0}, {X: 2}}},
+		{"EL Complete", "ABCDEF\x1b[1;4;41m\x1b[1;3H\x1b[2K", []Position{{X: 0}, {X: 5}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 6, 2)
+			term.Write([]byte(tc.input))
+			for _, pos := range tc.cells {
+				cell, ok := term.Cell(pos.X, pos.Y)
+				if !ok || cell.Style.Bg != ansi.Red {
+					t.Errorf("expected cell at %v to have a red background, got %#v", pos, cell)
+				} else if cell.Style.Attrs != 0 || cell.Style.UlStyle != 0 {
+					t.Errorf("expected cell at %v to have no attributes, got %#v", pos, cell.Style)
+				}
+			}
+		})
+	}
+}
+
+func TestBackgroundColorErase(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		cells []Position
+	}{
+		{"ED", "ABC\r\nDEF\x1b[4;41m\x1b[2J", []Position{{X: 0}, {X: 5}, {X: 2, Y: 1}}},
+		{"EL", "ABCDEF\x1b[4;41m\x1b[1;3H\x1b[K", []Position{{X: 2}, {X: 5}}},
+		{"ECH", "ABCDEF\x1b[4;41m\x1b[1;2H\x1b[3X", []Position{{X: 1}, {X: 3}}},
+		{"IL", "ABC\r\nDEF\x1b[4;41m\x1b[1;2H\x1b[L", []Position{{X: 0}, {X: 5}}},
+		{"DL", "ABC\r\nDEF\x1b[4;41m\x1b[1;2H\x1b[M", []Position{{X: 0, Y: 1}, {X: 5, Y: 1}}},
+		{"SU", "ABC\r\nDEF\x1b[4;41m\x1b[S", []Position{{X: 0, Y: 1}, {X: 5, Y: 1}}},
+		{"SD", "ABC\r\nDEF\x1b[4;41m\x1b[T", []Position{{X: 0}, {X: 5}}},
+		{"LF", "ABC\r\nDEF\x1b[4;41m\n", []Position{{X: 0, Y: 1}, {X: 5, Y: 1}}},
+	}
+
+	for _, tc := range cases {
+		for _, bce := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s bce=%t", tc.name, bce), func(t *testing.T) {
+				term := newTestTerminal(t, 6, 2)
+				term.BackgroundColorErase = bce
+				term.Write([]byte(tc.input))
+				for _, pos := range tc.cells {
+					cell, ok := term.Cell(pos.X, pos.Y)
+					if !ok || cell.Rune != ' ' {
+						t.Errorf("expected a blank cell at %v, got %#v", pos, cell)
+						continue
+					}
+					want := cellbuf.Style{}
+					if bce {
+						want.Bg = ansi.Red
+					}
+					if !cell.Style.Equal(&want) {
+						t.Errorf("expected cell at %v to have style %#v, got %#v", pos, want, cell.Style)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestDeviceAttributes(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"DA1", "\x1b[c", "\x1b[?62;22;28c"},
+		{"DA1 zero", "\x1b[0c", "\x1b[?62;22;28c"},
+		{"DA1 invalid", "\x1b[1c", ""},
+		{"DA2", "\x1b[>c", "\x1b[>1;10;0c"},
+		{"DA2 zero", "\x1b[>0c", "\x1b[>1;10;0c"},
+		{"DA2 invalid", "\x1b[>1c", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 10, 5)
+			term.Write([]byte(tc.input))
+			if got := term.buf.String(); got != tc.want {
+				t.Errorf("want response %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDeviceStatusReport(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"DSR operating status", "\x1b[5n", "\x1b[0n"},
+		{"CPR", "\x1b[3;5H\x1b[6n", "\x1b[3;5R"},
+		{"CPR default", "\x1b[6n", "\x1b[1;1R"},
+		{"DECXCPR", "\x1b[3;5H\x1b[?6n", "\x1b[?3;5R"},
+		{"DECXCPR origin mode", "\x1b[2;4r\x1b[?6h\x1b[2;5H\x1b[?6n", "\x1b[?2;5R"},
+		{"DSR invalid", "\x1b[0n", ""},
+		{"DSR unsupported", "\x1b[15n", ""},
+		{"DEC DSR unsupported", "\x1b[?5n", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 10, 5)
+			var got string
+			term.Callbacks.Response = func(b []byte) { got += string(b) }
+			term.Write([]byte(tc.input))
+			if got != tc.want {
+				t.Errorf("want response %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestResponseCallback(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"CPR", "\x1b[3;5H\x1b[6n", "\x1b[3;5R"},
+		{"CPR origin mode", "\x1b[2;4r\x1b[?6h\x1b[2;5H\x1b[6n", "\x1b[2;5R"},
+		{"DECXCPR", "\x1b[3;5H\x1b[?6n", "\x1b[?3;5R"},
+		{"DSR", "\x1b[5n", "\x1b[0n"},
+		{"DA1", "\x1b[c", "\x1b[?62;22;28c"},
+		{"DECRQM", "\x1b[?7$p", "\x1b[?7;1$y"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			term := newTestTerminal(t, 10, 5)
+			var got []string
+			term.Callbacks.Response = func(b []byte) { got = append(got, string(b)) }
+			term.Write([]byte(tc.input))
+			assertLines(t, got, []string{tc.want})
+			if term.buf.Len() != 0 {
+				t.Errorf("want no buffered input, got %q", term.buf.String())
+			}
+		})
+	}
+
+	// Input that isn't a reply is still buffered.
+	term := newTestTerminal(t, 10, 5)
+	term.Callbacks.Response = func([]byte) { t.Error("unexpected response") }
+	term.SendKey(Key{Code: KeyEnter})
+	if got := term.buf.String(); got != "\r" {
+		t.Errorf("want buffered input %q, got %q", "\r", got)
+	}
+}
+
 func termText(term *Terminal) []string {
 	var lines []string
 	for y := 0; y < term.Height(); y++ {
 		var line string
 		for x := 0; x < term.Width(); x++ {
-			cell := term.Cell(x, y)
-			if cell == nil {
+			cell, ok := term.Cell(x, y)
+			if !ok {
 				continue
 			}
 			line += cell.String()
@@ -1824,3 +2885,47 @@ func termText(term *Terminal) []string {
 	}
 	return lines
 }
+
+func TestUnknownSequences(t *testing.T) {
+	term := newTestTerminal(t, 10, 2)
+	input := "a\x1b[?9999zb\x1b[1;2~"
+	n, err := term.Write([]byte(input))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n != len(input) {
+		t.Errorf("expected %d bytes written, got %d", len(input), n)
+	}
+	want := []string{`CSI "?9999z"`, `CSI "1;2~"`}
+	if got := term.UnknownSequences(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := term.String(); got != "ab\n" {
+		t.Errorf("expected %q, got %q", "ab\n", got)
+	}
+}
+
+func TestUnknownSequencesStrictMode(t *testing.T) {
+	term := newTestTerminal(t, 10, 2)
+	term.StrictMode = true
+	input := "a\x1b[?9999zb\x1b[1;2~c"
+	n, err := term.Write([]byte(input))
+	var unknown *UnknownSequenceError
+	if !errors.As(err, &unknown) || unknown.Sequence != `CSI "?9999z"` {
+		t.Fatalf("expected an error for the first unknown sequence, got %v", err)
+	}
+	// The rest of the data is still processed.
+	if n != len(input) {
+		t.Errorf("expected %d bytes written, got %d", len(input), n)
+	}
+	if got := term.String(); got != "abc\n" {
+		t.Errorf("expected %q, got %q", "abc\n", got)
+	}
+	if got := term.UnknownSequences(); len(got) != 2 {
+		t.Errorf("expected 2 unknown sequences, got %q", got)
+	}
+
+	if _, err := term.Write([]byte("\x1b[1m")); err != nil {
+		t.Errorf("expected no error for a known sequence, got %v", err)
+	}
+}
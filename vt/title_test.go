@@ -0,0 +1,75 @@
+package vt
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestTitle(t *testing.T) {
+	term := newTestTerminal(t, 10, 2)
+	var titles, iconNames []string
+	term.Callbacks.Title = func(s string) { titles = append(titles, s) }
+	term.Callbacks.IconName = func(s string) { iconNames = append(iconNames, s) }
+
+	// OSC terminated by BEL.
+	term.Write([]byte(ansi.SetIconNameWindowTitle("both")))
+	if term.Title() != "both" || term.IconName() != "both" {
+		t.Errorf("expected title and icon name %q, got %q and %q", "both", term.Title(), term.IconName())
+	}
+
+	// OSC terminated by ST.
+	term.Write([]byte("\x1b]2;a;b\x1b\\"))
+	if term.Title() != "a;b" {
+		t.Errorf("expected title %q, got %q", "a;b", term.Title())
+	}
+	term.Write([]byte("\x1b]1;icon\x1b\\"))
+	if term.IconName() != "icon" || term.Title() != "a;b" {
+		t.Errorf("expected icon name %q and title %q, got %q and %q", "icon", "a;b", term.IconName(), term.Title())
+	}
+
+	assertLines(t, titles, []string{"both", "a;b"})
+	assertLines(t, iconNames, []string{"both", "icon"})
+}
+
+func TestTitleStack(t *testing.T) {
+	term := newTestTerminal(t, 10, 2)
+	term.Write([]byte(ansi.SetIconName("icon1") + ansi.SetWindowTitle("title1")))
+
+	// Push both, then only the title.
+	term.Write([]byte(ansi.WindowOp(ansi.PushTitleWinOp, 0)))
+	term.Write([]byte(ansi.SetIconName("icon2") + ansi.SetWindowTitle("title2")))
+	term.Write([]byte(ansi.WindowOp(ansi.PushTitleWinOp, 2)))
+	term.Write([]byte(ansi.SetIconName("icon3") + ansi.SetWindowTitle("title3")))
+
+	term.Write([]byte(ansi.WindowOp(ansi.PopTitleWinOp, 0)))
+	if term.Title() != "title2" || term.IconName() != "icon3" {
+		t.Errorf("expected title %q and icon name %q, got %q and %q", "title2", "icon3", term.Title(), term.IconName())
+	}
+
+	// Only restore the icon name.
+	term.Write([]byte(ansi.WindowOp(ansi.PopTitleWinOp, 1)))
+	if term.Title() != "title2" || term.IconName() != "icon1" {
+		t.Errorf("expected title %q and icon name %q, got %q and %q", "title2", "icon1", term.Title(), term.IconName())
+	}
+
+	// Popping an empty stack does nothing.
+	term.Write([]byte(ansi.WindowOp(ansi.PopTitleWinOp)))
+	if term.Title() != "title2" || term.IconName() != "icon1" {
+		t.Errorf("expected title %q and icon name %q, got %q and %q", "title2", "icon1", term.Title(), term.IconName())
+	}
+}
+
+func TestTitleStackLimit(t *testing.T) {
+	term := newTestTerminal(t, 10, 2)
+	for i := 0; i <= maxTitleStack; i++ {
+		term.Write([]byte(ansi.SetWindowTitle(string(rune('a'+i))) + ansi.WindowOp(ansi.PushTitleWinOp)))
+	}
+	for i := 0; i <= maxTitleStack; i++ {
+		term.Write([]byte(ansi.WindowOp(ansi.PopTitleWinOp)))
+	}
+	// The oldest entry was dropped.
+	if term.Title() != "b" {
+		t.Errorf("expected title %q, got %q", "b", term.Title())
+	}
+}
@@ -0,0 +1,37 @@
+package vt
+
+// maxUnknownSequences is the maximum number of unknown sequences recorded.
+const maxUnknownSequences = 100
+
+// UnknownSequenceError is returned by [Terminal.Write] in strict mode when
+// the data has a sequence the terminal doesn't handle.
+type UnknownSequenceError struct {
+	// Sequence describes the sequence, such as `CSI "?1234h"`.
+	Sequence string
+}
+
+// Error implements error.
+func (e *UnknownSequenceError) Error() string {
+	return "vt: unhandled sequence: " + e.Sequence
+}
+
+// UnknownSequences returns the latest sequences written to the terminal that
+// it doesn't handle, oldest first, in the same form as
+// [UnknownSequenceError.Sequence]. Up to 100 sequences are kept.
+func (t *Terminal) UnknownSequences() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.unknown...)
+}
+
+// unhandled logs and records a sequence the terminal doesn't handle.
+func (t *Terminal) unhandled(seq string) {
+	t.logf("unhandled sequence: %s", seq)
+	if len(t.unknown) == maxUnknownSequences {
+		t.unknown = append(t.unknown[:0], t.unknown[1:]...)
+	}
+	t.unknown = append(t.unknown, seq)
+	if t.StrictMode && t.unknownErr == nil {
+		t.unknownErr = &UnknownSequenceError{Sequence: seq}
+	}
+}
@@ -30,14 +30,31 @@ func (t *Terminal) handleGrapheme(content string, width int) {
 		cell = cellbuf.NewCellString(content)
 	}
 
+	if width == 0 {
+		// Combining marks and other zero-width graphemes belong to the
+		// preceding cell.
+		if t.appendCombining(content) {
+			return
+		}
+		// Without a preceding cell, the grapheme is stored on its own.
+		width, cell.Width = 1, 1
+	}
+
 	x, y := t.scr.CursorPosition()
-	if t.atPhantom || x+width > t.scr.Width() {
-		// moves cursor down similar to [Terminal.linefeed] except it doesn't
-		// respects [ansi.LNM] mode.
-		// This will rest the phantom state i.e. pending wrap state.
-		t.index()
-		_, y = t.scr.CursorPosition()
-		x = 0
+	if t.atPhantom || x+width > t.scr.LineWidth(y) {
+		if t.isModeSet(ansi.AutoWrapMode) {
+			// moves cursor down similar to [Terminal.linefeed] except it
+			// doesn't respects [ansi.LNM] mode.
+			// This will rest the phantom state i.e. pending wrap state.
+			t.scr.setWrapped(y, true)
+			t.index()
+			_, y = t.scr.CursorPosition()
+			x = 0
+		} else {
+			// Without auto wrap, the character overwrites the last column(s)
+			// of the line.
+			x = max(0, t.scr.LineWidth(y)-width)
+		}
 	}
 
 	// Handle character set mappings
@@ -66,6 +83,13 @@ func (t *Terminal) handleGrapheme(content string, width int) {
 	cell.Style = t.scr.cursorPen()
 	cell.Link = t.scr.cursorLink()
 
+	if t.isModeSet(ansi.InsertReplaceMode) {
+		// Shift the rest of the line to the right to make room for the
+		// character, dropping the cells that go past the right margin.
+		t.scr.setCursor(x, y, false)
+		t.scr.InsertCell(width)
+	}
+
 	if t.scr.SetCell(x, y, cell) {
 		if width == 1 && len(content) == 1 {
 			t.lastChar, _ = utf8.DecodeRuneInString(content)
@@ -73,7 +97,7 @@ func (t *Terminal) handleGrapheme(content string, width int) {
 	}
 
 	// Handle phantom state at the end of the line
-	if x+width >= t.scr.Width() {
+	if x+width >= t.scr.LineWidth(y) {
 		if t.isModeSet(ansi.AutoWrapMode) {
 			t.atPhantom = true
 		}
@@ -85,6 +109,38 @@ func (t *Terminal) handleGrapheme(content string, width int) {
 	t.scr.setCursor(x, y, false)
 }
 
+// appendCombining appends the runes of a zero-width grapheme, such as a
+// combining mark, to the cell preceding the cursor. It returns false if
+// there's no preceding cell on the line.
+func (t *Terminal) appendCombining(content string) bool {
+	x, y := t.scr.CursorPosition()
+	if !t.atPhantom {
+		// The cursor is past the last written cell unless it's pending a
+		// wrap.
+		x--
+	}
+	// Skip the placeholders of a preceding wide cell.
+	for x >= 0 {
+		c := t.scr.Cell(x, y)
+		if c == nil {
+			return false
+		}
+		if c.Width > 0 {
+			break
+		}
+		x--
+	}
+	if x < 0 {
+		return false
+	}
+
+	cell := t.scr.Cell(x, y).Clone()
+	for _, r := range content {
+		cell.Comb = append(cell.Comb, r)
+	}
+	return t.scr.SetCell(x, y, cell)
+}
+
 func firstRune(s string) rune {
 	for _, r := range s {
 		return r
@@ -0,0 +1,125 @@
+package vt
+
+import "github.com/charmbracelet/x/cellbuf"
+
+// ScrollUp scrolls the viewport up n lines into the scrollback, stopping at
+// the oldest line. It doesn't change the screen content or the cursor. The
+// viewport stays on the same lines when new lines are added to the
+// scrollback. It has no effect on the alternate screen.
+func (t *Terminal) ScrollUp(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.scrollViewport(t.scrollback.offset + n)
+}
+
+// ScrollDown scrolls the viewport down n lines towards the screen, stopping
+// at the bottom of the scrollback.
+func (t *Terminal) ScrollDown(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.scrollViewport(t.scrollback.offset - n)
+}
+
+// ScrollToBottom scrolls the viewport back to the screen.
+func (t *Terminal) ScrollToBottom() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.scrollViewport(0)
+}
+
+// ScrollTo scrolls the viewport to show the line y of the history, where the
+// scrollback lines come first, the oldest one at y 0, followed by the screen
+// lines, see [Terminal.Search]. A scrollback line is shown at the top of the
+// viewport, and the viewport shows the screen for a screen line.
+func (t *Terminal) ScrollTo(y int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.scrollViewport(len(t.scrollback.lines) - y)
+}
+
+// ScrollOffset returns the number of lines the viewport is scrolled up into
+// the scrollback, zero when it shows the screen.
+func (t *Terminal) ScrollOffset() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.scrollOffset()
+}
+
+// scrollOffset returns the viewport scroll offset. The caller must hold the
+// lock.
+func (t *Terminal) scrollOffset() int {
+	if t.scr != &t.scrs[0] {
+		return 0
+	}
+	return t.scrollback.offset
+}
+
+// scrollViewport sets the viewport scroll offset, clamped to the scrollback.
+// The caller must hold the lock.
+func (t *Terminal) scrollViewport(offset int) {
+	if t.scr != &t.scrs[0] {
+		return
+	}
+	offset = clamp(offset, 0, len(t.scrollback.lines))
+	if offset == t.scrollback.offset {
+		return
+	}
+	t.scrollback.offset = offset
+	t.scr.damage(ScreenDamage{t.Width(), t.Height()})
+}
+
+// ViewportCell returns a copy of the cell at the given x, y position of the
+// viewport, which shows the scrollback lines when it's scrolled up, see
+// [Terminal.ScrollUp]. Otherwise, it's the same as [Terminal.Cell].
+func (t *Terminal) ViewportCell(x, y int) (Cell, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := t.viewport().Cell(x, y)
+	if c == nil {
+		return Cell{}, false
+	}
+	return *c, true
+}
+
+// viewport returns the terminal viewport. The caller must hold the lock.
+func (t *Terminal) viewport() *viewport {
+	v := &viewport{scr: t.scr}
+	if n := t.scrollOffset(); n > 0 {
+		lines := t.scrollback.all()
+		v.lines = lines[len(lines)-n:]
+	}
+	return v
+}
+
+// viewport is a read-only [cellbuf.CellBuffer] of the screen scrolled down by
+// the given scrollback lines.
+type viewport struct {
+	scr   *Screen
+	lines []Line
+}
+
+// Cell implements [cellbuf.CellBuffer].
+func (v *viewport) Cell(x, y int) *Cell {
+	if y >= 0 && y < len(v.lines) {
+		if x < 0 || x >= v.scr.Width() {
+			return nil
+		}
+		if c := v.lines[y].At(x); c != nil {
+			return c
+		}
+		// The line is narrower than the screen.
+		c := cellbuf.BlankCell
+		return &c
+	}
+	return v.scr.Cell(x, y-len(v.lines))
+}
+
+// SetCell implements [cellbuf.CellBuffer]. The viewport is read-only.
+func (v *viewport) SetCell(int, int, *Cell) bool {
+	return false
+}
+
+// Bounds implements [cellbuf.CellBuffer].
+func (v *viewport) Bounds() Rectangle {
+	return v.scr.Bounds()
+}
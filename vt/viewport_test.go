@@ -0,0 +1,119 @@
+package vt
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func viewportText(term *Terminal) []string {
+	var lines []string
+	for _, l := range strings.Split(term.Render(), "\r\n") {
+		lines = append(lines, ansi.Strip(l))
+	}
+	return lines
+}
+
+func TestViewportScroll(t *testing.T) {
+	term := newTestTerminal(t, 5, 3)
+	for i := 1; i <= 6; i++ {
+		if i > 1 {
+			term.Write([]byte("\r\n"))
+		}
+		term.Write([]byte("line" + strconv.Itoa(i)))
+	}
+	pos := term.CursorPosition()
+
+	term.ScrollUp(2)
+	if got := term.ScrollOffset(); got != 2 {
+		t.Errorf("expected offset 2, got %d", got)
+	}
+	assertLines(t, viewportText(term), []string{"line2", "line3", "line4"})
+	if c, _ := term.ViewportCell(4, 0); c.Rune != '2' {
+		t.Errorf("expected viewport cell '2', got %q", c.Rune)
+	}
+
+	// The screen and the cursor are unchanged.
+	assertLines(t, termText(term), []string{"line4", "line5", "line6"})
+	if got := term.CursorPosition(); got != pos {
+		t.Errorf("expected cursor at %v, got %v", pos, got)
+	}
+
+	// Scrolling stops at the oldest line.
+	term.ScrollUp(10)
+	if got := term.ScrollOffset(); got != 3 {
+		t.Errorf("expected offset 3, got %d", got)
+	}
+	assertLines(t, viewportText(term), []string{"line1", "line2", "line3"})
+
+	// And at the bottom.
+	term.ScrollDown(1)
+	assertLines(t, viewportText(term), []string{"line2", "line3", "line4"})
+	term.ScrollDown(10)
+	if got := term.ScrollOffset(); got != 0 {
+		t.Errorf("expected offset 0, got %d", got)
+	}
+	assertLines(t, viewportText(term), []string{"line4", "line5", "line6"})
+}
+
+func TestViewportScrollOutput(t *testing.T) {
+	term := newTestTerminal(t, 5, 2)
+	term.Write([]byte("a\r\nb\r\nc\r\nd"))
+	term.ScrollUp(1)
+	assertLines(t, viewportText(term), []string{"b", "c"})
+
+	// The viewport stays on the same lines as the output scrolls.
+	term.Write([]byte("\r\ne"))
+	if got := term.ScrollOffset(); got != 2 {
+		t.Errorf("expected offset 2, got %d", got)
+	}
+	assertLines(t, viewportText(term), []string{"b", "c"})
+
+	term.ScrollToBottom()
+	assertLines(t, viewportText(term), []string{"d", "e"})
+
+	// The alternate screen has no scrollback.
+	term.ScrollUp(1)
+	term.Write([]byte("\x1b[?1049h"))
+	if got := term.ScrollOffset(); got != 0 {
+		t.Errorf("expected offset 0 on the alternate screen, got %d", got)
+	}
+	term.ScrollUp(1)
+	assertLines(t, viewportText(term), []string{"", ""})
+}
+
+// TestViewportConcurrentWrite tests that the viewport and the scrollback can
+// be used while the terminal is written to. Run it with the race detector.
+func TestViewportConcurrentWrite(t *testing.T) {
+	term := newTestTerminal(t, 10, 3)
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			term.Write([]byte("line" + strconv.Itoa(i) + "\r\n")) //nolint:errcheck
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		term.ScrollUp(2)
+		term.Render()
+		term.HTML()
+		term.ViewportCell(0, 0)
+		term.ScrollOffset()
+		term.Scrollback()
+		term.Search("line", true)
+		term.ScrollTo(0)
+		term.ScrollDown(1)
+		term.ScrollToBottom()
+		term.SetScrollbackLimit(50 + i%10)
+	}
+	close(stop)
+	<-done
+}